@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"taxi-service/models"
 	"taxi-service/services"
 
@@ -31,6 +32,12 @@ func (cc *CorridaController) CriarCorrida(c *fiber.Ctx) error {
 
 	corrida, err := cc.service.CriarNovaCorrida(corridaInput)
 	if err != nil {
+		if errors.Is(err, services.ErrCategoriaInvalida) || errors.Is(err, services.ErrEmbarqueSemCoordenadas) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrEmbarqueForaDaArea) || errors.Is(err, services.ErrEmbarqueAreaRestrita) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -39,6 +46,18 @@ func (cc *CorridaController) CriarCorrida(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(corrida)
 }
 
+// ListarCategorias (GET /corridas/categorias) lista as categorias de veículo e suas tarifas.
+func (cc *CorridaController) ListarCategorias(c *fiber.Ctx) error {
+	categorias := make([]fiber.Map, 0, len(models.CategoriasVeiculo))
+	for _, categoria := range models.CategoriasVeiculo {
+		categorias = append(categorias, fiber.Map{
+			"categoria": categoria,
+			"tarifa":    services.TarifaDa(categoria),
+		})
+	}
+	return c.JSON(fiber.Map{"categorias": categorias})
+}
+
 // GetCorrida (GET /corrida/:id) busca o status de uma corrida.
 func (cc *CorridaController) GetCorrida(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -51,6 +70,7 @@ func (cc *CorridaController) GetCorrida(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	definirETag(c, corrida.Versao)
 	return c.JSON(corrida)
 }
 
@@ -69,20 +89,51 @@ func (cc *CorridaController) AceitarCorrida(c *fiber.Ctx) error {
 	}
 
 	var body struct {
-		MotoristaID int `json:"motoristaId"`
+		MotoristaID models.MotoristaID `json:"motoristaId"`
 	}
 
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Corpo da requisição inválido"})
 	}
 
-	if err := cc.service.AceitarCorrida(id, body.MotoristaID); err != nil {
+	if body.MotoristaID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "motoristaId é obrigatório"})
+	}
+
+	versao, err := versaoIfMatch(c)
+	if err != nil {
+		return respostaPreCondicaoFalhou(c)
+	}
+
+	if err := cc.service.AceitarCorrida(id, body.MotoristaID, versao); err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(c)
+		}
+		var erroElegibilidade *services.ErroElegibilidade
+		if errors.As(err, &erroElegibilidade) {
+			return c.Status(statusElegibilidade(erroElegibilidade.Codigo)).JSON(fiber.Map{
+				"error": erroElegibilidade.Mensagem,
+				"code":  erroElegibilidade.Codigo,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// statusElegibilidade converte o código de inelegibilidade no status HTTP da resposta.
+func statusElegibilidade(codigo string) int {
+	switch codigo {
+	case services.CodigoMotoristaNaoEncontrado:
+		return fiber.StatusNotFound
+	case services.CodigoMotoristaOcupado:
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusUnprocessableEntity
+	}
+}
+
 // AtualizarPosicao (PUT /corrida/:id/posicao) atualiza a posição do motorista.
 func (cc *CorridaController) AtualizarPosicao(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -99,7 +150,15 @@ func (cc *CorridaController) AtualizarPosicao(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Corpo da requisição inválido"})
 	}
 
-	if err := cc.service.AtualizarPosicao(id, body.Lat, body.Lng); err != nil {
+	versao, err := versaoIfMatch(c)
+	if err != nil {
+		return respostaPreCondicaoFalhou(c)
+	}
+
+	if err := cc.service.AtualizarPosicao(id, body.Lat, body.Lng, versao); err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(c)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -113,7 +172,18 @@ func (cc *CorridaController) CancelarCorrida(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID da corrida inválido"})
 	}
 
-	if err := cc.service.CancelarCorrida(id); err != nil {
+	versao, err := versaoIfMatch(c)
+	if err != nil {
+		return respostaPreCondicaoFalhou(c)
+	}
+
+	if err := cc.service.CancelarCorrida(id, versao); err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(c)
+		}
+		if errors.Is(err, services.ErrCorridaIndisponivel) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -127,13 +197,116 @@ func (cc *CorridaController) FinalizarCorrida(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID da corrida inválido"})
 	}
 
-	if err := cc.service.FinalizarCorrida(id); err != nil {
+	versao, err := versaoIfMatch(c)
+	if err != nil {
+		return respostaPreCondicaoFalhou(c)
+	}
+
+	if err := cc.service.FinalizarCorrida(id, versao); err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(c)
+		}
+		if errors.Is(err, services.ErrCorridaIndisponivel) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Corridas de rua terminam com o recibo do taxímetro
+	if recibo, err := cc.service.ReciboCorrida(id); err == nil {
+		return c.Status(fiber.StatusOK).JSON(recibo)
+	}
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// IniciarCorridaDeRua (POST /corrida/rua) inicia uma corrida pedida na rua, com passageiro
+// anônimo, na posição atual do motorista. O valor é calculado pelo taxímetro a partir das
+// posições enviadas em PUT /corrida/:id/posicao.
+func (cc *CorridaController) IniciarCorridaDeRua(c *fiber.Ctx) error {
+	var body struct {
+		MotoristaID models.MotoristaID `json:"motoristaId"`
+		Lat         float64            `json:"lat"`
+		Lng         float64            `json:"lng"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Corpo da requisição inválido"})
+	}
+	if body.MotoristaID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "motoristaId é obrigatório"})
+	}
+	if !services.CoordenadasValidas(body.Lat, body.Lng) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "lat e lng são obrigatórios e devem ser coordenadas válidas"})
+	}
+
+	corrida, err := cc.service.IniciarCorridaDeRua(body.MotoristaID, body.Lat, body.Lng)
+	if err != nil {
+		var erroElegibilidade *services.ErroElegibilidade
+		if errors.As(err, &erroElegibilidade) {
+			return c.Status(statusElegibilidade(erroElegibilidade.Codigo)).JSON(fiber.Map{
+				"error": erroElegibilidade.Mensagem,
+				"code":  erroElegibilidade.Codigo,
+			})
+		}
+		if errors.Is(err, services.ErrEmbarqueForaDaArea) || errors.Is(err, services.ErrEmbarqueAreaRestrita) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(corrida)
+}
+
+// ReciboCorrida (GET /corrida/:id/recibo) retorna o recibo de uma corrida de rua finalizada.
+func (cc *CorridaController) ReciboCorrida(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID da corrida inválido"})
+	}
+
+	recibo, err := cc.service.ReciboCorrida(id)
+	if err != nil {
+		return respostaErroRecibo(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(recibo)
+}
+
+// EnviarRecibo (POST /corrida/:id/recibo) envia o recibo para o email informado pelo passageiro.
+func (cc *CorridaController) EnviarRecibo(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID da corrida inválido"})
+	}
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Corpo da requisição inválido"})
+	}
+
+	recibo, err := cc.service.EnviarRecibo(id, body.Email)
+	if err != nil {
+		return respostaErroRecibo(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"enviado_para": body.Email, "recibo": recibo})
+}
+
+// respostaErroRecibo converte os erros do recibo no status HTTP da resposta.
+func respostaErroRecibo(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrCorridaNaoEncontrada):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrEmailReciboInvalido):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrCorridaNaoEDeRua), errors.Is(err, services.ErrReciboIndisponivel):
+		status = fiber.StatusConflict
+	case errors.Is(err, services.ErrEnvioReciboIndefinido):
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+}
+
 func (cc *CorridaController) AvaliarCorrida(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := strconv.Atoi(idStr)
@@ -164,7 +337,7 @@ func (cc *CorridaController) Service() *services.CorridaService {
 }
 
 type cancelamentoMotoristaRequest struct {
-	MotoristaID string `json:"motorista_id"`
+	MotoristaID models.MotoristaID `json:"motorista_id"`
 }
 
 // CancelarCorridaPeloMotorista lida com a requisição de cancelamento de uma corrida por um motorista.
@@ -189,9 +362,17 @@ func (cc *CorridaController) CancelarCorridaPeloMotorista(c *fiber.Ctx) error {
 		})
 	}
 
+	versao, err := versaoIfMatch(c)
+	if err != nil {
+		return respostaPreCondicaoFalhou(c)
+	}
+
 	// REATIVADO: A chamada ao serviço agora funcionará corretamente.
-	err = cc.service.CancelarCorridaPeloMotorista(corridaID, req.MotoristaID)
+	err = cc.service.CancelarCorridaPeloMotorista(corridaID, req.MotoristaID, versao)
 	if err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(c)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// DisponibilidadeController gerencia o status online/offline e a posição dos motoristas
+type DisponibilidadeController struct {
+	registro *services.RegistroDisponibilidade
+}
+
+// NewDisponibilidadeController cria uma nova instância do controller
+func NewDisponibilidadeController(registro *services.RegistroDisponibilidade) *DisponibilidadeController {
+	return &DisponibilidadeController{
+		registro: registro,
+	}
+}
+
+// posicaoRequest é o corpo aceito por online e heartbeat
+type posicaoRequest struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// lerPosicao valida o corpo com a posição atual do motorista
+func lerPosicao(ctx *fiber.Ctx) (posicaoRequest, error) {
+	var body posicaoRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		return body, errors.New("Corpo da requisição inválido")
+	}
+	if !services.CoordenadasValidas(body.Lat, body.Lng) {
+		return body, errors.New("lat e lng são obrigatórios e devem ser coordenadas válidas")
+	}
+	return body, nil
+}
+
+// FicarOnline POST /api/motoristas/:id/online
+func (c *DisponibilidadeController) FicarOnline(ctx *fiber.Ctx) error {
+	motoristaID := models.MotoristaID(ctx.Params("id"))
+
+	posicao, err := lerPosicao(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	disponibilidade, err := c.registro.FicarOnline(motoristaID, posicao.Lat, posicao.Lng)
+	if err != nil {
+		var erroElegibilidade *services.ErroElegibilidade
+		if errors.As(err, &erroElegibilidade) {
+			return ctx.Status(statusElegibilidade(erroElegibilidade.Codigo)).JSON(fiber.Map{
+				"error": erroElegibilidade.Mensagem,
+				"code":  erroElegibilidade.Codigo,
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.JSON(disponibilidade)
+}
+
+// FicarOffline POST /api/motoristas/:id/offline
+func (c *DisponibilidadeController) FicarOffline(ctx *fiber.Ctx) error {
+	motoristaID := models.MotoristaID(ctx.Params("id"))
+
+	disponibilidade, err := c.registro.FicarOffline(motoristaID)
+	if err != nil {
+		if errors.Is(err, services.ErrMotoristaOffline) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.JSON(disponibilidade)
+}
+
+// Heartbeat POST /api/motoristas/:id/heartbeat
+func (c *DisponibilidadeController) Heartbeat(ctx *fiber.Ctx) error {
+	motoristaID := models.MotoristaID(ctx.Params("id"))
+
+	posicao, err := lerPosicao(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	disponibilidade, err := c.registro.Heartbeat(motoristaID, posicao.Lat, posicao.Lng)
+	if err != nil {
+		if errors.Is(err, services.ErrMotoristaOffline) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.JSON(disponibilidade)
+}
+
+// ConsultarDisponibilidade GET /api/motoristas/:id/disponibilidade
+func (c *DisponibilidadeController) ConsultarDisponibilidade(ctx *fiber.Ctx) error {
+	motoristaID := models.MotoristaID(ctx.Params("id"))
+
+	return ctx.JSON(c.registro.Consultar(motoristaID))
+}
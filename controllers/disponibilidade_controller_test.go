@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// elegibilidadeAprovada considera elegível qualquer motorista, exceto os suspensos
+type elegibilidadeAprovada struct {
+	suspensos map[models.MotoristaID]bool
+}
+
+func (e *elegibilidadeAprovada) VerificarElegibilidade(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	if e.suspensos[motoristaID] {
+		return nil, &services.ErroElegibilidade{Codigo: services.CodigoMotoristaSuspenso, Mensagem: "motorista suspenso"}
+	}
+	return &models.Motorista{ID: motoristaID, Status: models.StatusAprovado}, nil
+}
+
+func (e *elegibilidadeAprovada) ReservarMotorista(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	return e.VerificarElegibilidade(motoristaID)
+}
+
+func (e *elegibilidadeAprovada) LiberarMotorista(motoristaID models.MotoristaID) error {
+	return nil
+}
+
+func TestDisponibilidadeController(t *testing.T) {
+	setup := func() *fiber.App {
+		app := fiber.New()
+		elegibilidade := &elegibilidadeAprovada{suspensos: map[models.MotoristaID]bool{"suspenso": true}}
+		controller := NewDisponibilidadeController(services.NewRegistroDisponibilidade(elegibilidade, time.Minute))
+		app.Post("/api/motoristas/:id/online", controller.FicarOnline)
+		app.Post("/api/motoristas/:id/offline", controller.FicarOffline)
+		app.Post("/api/motoristas/:id/heartbeat", controller.Heartbeat)
+		app.Get("/api/motoristas/:id/disponibilidade", controller.ConsultarDisponibilidade)
+		return app
+	}
+
+	enviar := func(t *testing.T, app *fiber.App, method, path string, corpo interface{}) (int, map[string]interface{}) {
+		body, _ := json.Marshal(corpo)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var resposta map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&resposta)
+		return resp.StatusCode, resposta
+	}
+
+	posicao := map[string]float64{"lat": -8.0631, "lng": -34.8711}
+
+	t.Run("Ficar online, enviar heartbeat e ficar offline", func(t *testing.T) {
+		app := setup()
+
+		status, resposta := enviar(t, app, "POST", "/api/motoristas/1/online", posicao)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "disponivel", resposta["status"])
+
+		status, resposta = enviar(t, app, "POST", "/api/motoristas/1/heartbeat", map[string]float64{"lat": -8.07, "lng": -34.88})
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, -8.07, resposta["latitude"])
+
+		status, resposta = enviar(t, app, "POST", "/api/motoristas/1/offline", nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "offline", resposta["status"])
+
+		status, resposta = enviar(t, app, "GET", "/api/motoristas/1/disponibilidade", nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "offline", resposta["status"])
+	})
+
+	t.Run("Posição inválida é rejeitada", func(t *testing.T) {
+		app := setup()
+
+		status, _ := enviar(t, app, "POST", "/api/motoristas/1/online", map[string]float64{"lat": 120, "lng": 10})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("Heartbeat ou offline de motorista fora do registro retorna conflito", func(t *testing.T) {
+		app := setup()
+
+		status, _ := enviar(t, app, "POST", "/api/motoristas/2/heartbeat", posicao)
+		assert.Equal(t, fiber.StatusConflict, status)
+
+		status, _ = enviar(t, app, "POST", "/api/motoristas/2/offline", nil)
+		assert.Equal(t, fiber.StatusConflict, status)
+	})
+
+	t.Run("Motorista suspenso não fica online", func(t *testing.T) {
+		app := setup()
+
+		status, resposta := enviar(t, app, "POST", "/api/motoristas/suspenso/online", posicao)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		assert.Equal(t, services.CodigoMotoristaSuspenso, resposta["code"])
+	})
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// EstatisticasMotoristaController expõe as estatísticas de aceite das ofertas por motorista
+type EstatisticasMotoristaController struct {
+	estatisticas *services.EstatisticasMotoristas
+}
+
+// NewEstatisticasMotoristaController cria uma nova instância do controller
+func NewEstatisticasMotoristaController(estatisticas *services.EstatisticasMotoristas) *EstatisticasMotoristaController {
+	return &EstatisticasMotoristaController{
+		estatisticas: estatisticas,
+	}
+}
+
+// BuscarEstatisticas GET /notificacoes/motorista/:motoristaID/estatisticas
+// Período em ?janela=7d|30d (padrão 7d) ou personalizado em ?de=&ate= (RFC 3339 ou AAAA-MM-DD;
+// ate com apenas a data inclui o dia inteiro e, omitido, vale agora)
+func (c *EstatisticasMotoristaController) BuscarEstatisticas(ctx *fiber.Ctx) error {
+	motoristaID := models.MotoristaID(ctx.Params("motoristaID"))
+	if !motoristaID.Valido() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MotoristaID format",
+		})
+	}
+
+	var estatisticas services.EstatisticasAceite
+	var err error
+	if ctx.Query("de") != "" {
+		de, errDe := dataDaQuery(ctx.Query("de"), false)
+		ate, errAte := dataDaQuery(ctx.Query("ate"), true)
+		if errDe != nil || errAte != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid de or ate date, use RFC 3339 or YYYY-MM-DD",
+			})
+		}
+		if ate.IsZero() {
+			ate = time.Now()
+		}
+		estatisticas, err = c.estatisticas.Calcular(motoristaID, de, ate)
+	} else {
+		janela := ctx.Query("janela", services.JanelaEstatisticas7Dias)
+		estatisticas, err = c.estatisticas.CalcularJanela(motoristaID, janela)
+	}
+
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrPeriodoEstatisticasInvalido) {
+			status = fiber.StatusBadRequest
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.JSON(estatisticas)
+}
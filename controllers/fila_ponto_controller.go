@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// FilaPontoController expõe a posição dos motoristas nas filas dos pontos de táxi e aeroportos
+type FilaPontoController struct {
+	filas *services.FilaPontoService
+}
+
+// NewFilaPontoController cria uma nova instância do controller
+func NewFilaPontoController(filas *services.FilaPontoService) *FilaPontoController {
+	return &FilaPontoController{
+		filas: filas,
+	}
+}
+
+// ConsultarPosicao GET /api/motoristas/:id/fila
+func (c *FilaPontoController) ConsultarPosicao(ctx *fiber.Ctx) error {
+	posicao, err := c.filas.PosicaoDe(models.MotoristaID(ctx.Params("id")))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrForaDaFila) {
+			status = fiber.StatusNotFound
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.JSON(posicao)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -59,7 +60,7 @@ func (c *MotoristaController) CadastrarMotorista(ctx *fiber.Ctx) error {
 
 // UploadDocumento POST /api/motoristas/:id/documentos
 func (c *MotoristaController) UploadDocumento(ctx *fiber.Ctx) error {
-	motoristaID := ctx.Params("id")
+	motoristaID := models.MotoristaID(ctx.Params("id"))
 
 	var request services.UploadDocumentoRequest
 	if err := ctx.BodyParser(&request); err != nil {
@@ -68,8 +69,17 @@ func (c *MotoristaController) UploadDocumento(ctx *fiber.Ctx) error {
 		})
 	}
 
-	err := c.motoristaService.UploadDocumento(motoristaID, request)
+	versao, err := versaoIfMatch(ctx)
 	if err != nil {
+		return respostaPreCondicaoFalhou(ctx)
+	}
+
+	err = c.motoristaService.UploadDocumento(motoristaID, request, versao)
+	if err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(ctx)
+		}
+
 		statusCode := fiber.StatusBadRequest
 
 		if err.Error() == "Motorista não encontrado" {
@@ -88,7 +98,7 @@ func (c *MotoristaController) UploadDocumento(ctx *fiber.Ctx) error {
 
 // BuscarMotorista GET /api/motoristas/:id
 func (c *MotoristaController) BuscarMotorista(ctx *fiber.Ctx) error {
-	motoristaID := ctx.Params("id")
+	motoristaID := models.MotoristaID(ctx.Params("id"))
 
 	motorista, err := c.motoristaService.BuscarMotorista(motoristaID)
 	if err != nil {
@@ -97,27 +107,40 @@ func (c *MotoristaController) BuscarMotorista(ctx *fiber.Ctx) error {
 		})
 	}
 
+	definirETag(ctx, motorista.Versao)
 	return ctx.JSON(fiber.Map{
 		"motorista": fiber.Map{
-			"id":             motorista.ID,
-			"nome":           motorista.Nome,
-			"email":          motorista.Email,
-			"telefone":       motorista.Telefone,
-			"status":         motorista.Status,
-			"modelo_veiculo": motorista.ModeloVeiculo,
-			"placa_veiculo":  motorista.PlacaVeiculo,
-			"criado_em":      motorista.CriadoEm,
-			"documentos":     motorista.Documentos,
+			"id":                motorista.ID,
+			"nome":              motorista.Nome,
+			"email":             motorista.Email,
+			"telefone":          motorista.Telefone,
+			"status":            motorista.Status,
+			"modelo_veiculo":    motorista.ModeloVeiculo,
+			"placa_veiculo":     motorista.PlacaVeiculo,
+			"categoria_veiculo": motorista.CategoriaVeiculo.OuPadrao(),
+			"categoria_cnh":     motorista.CategoriaCNH,
+			"criado_em":         motorista.CriadoEm,
+			"documentos":        motorista.Documentos,
+			"versao":            motorista.Versao,
 		},
 	})
 }
 
 // ValidarDocumentos POST /api/motoristas/:id/validar-documentos
 func (c *MotoristaController) ValidarDocumentos(ctx *fiber.Ctx) error {
-	motoristaID := ctx.Params("id")
+	motoristaID := models.MotoristaID(ctx.Params("id"))
 
-	err := c.motoristaService.ValidarDocumentos(motoristaID)
+	versao, err := versaoIfMatch(ctx)
 	if err != nil {
+		return respostaPreCondicaoFalhou(ctx)
+	}
+
+	err = c.motoristaService.ValidarDocumentos(motoristaID, versao)
+	if err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(ctx)
+		}
+
 		statusCode := fiber.StatusBadRequest
 
 		if err.Error() == "Motorista não encontrado" {
@@ -136,10 +159,19 @@ func (c *MotoristaController) ValidarDocumentos(ctx *fiber.Ctx) error {
 
 // AprovarMotorista PUT /api/motoristas/:id/aprovar
 func (c *MotoristaController) AprovarMotorista(ctx *fiber.Ctx) error {
-	motoristaID := ctx.Params("id")
+	motoristaID := models.MotoristaID(ctx.Params("id"))
 
-	err := c.motoristaService.AprovarMotorista(motoristaID)
+	versao, err := versaoIfMatch(ctx)
 	if err != nil {
+		return respostaPreCondicaoFalhou(ctx)
+	}
+
+	err = c.motoristaService.AprovarMotorista(motoristaID, versao)
+	if err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(ctx)
+		}
+
 		statusCode := fiber.StatusBadRequest
 
 		if err.Error() == "Motorista não encontrado" {
@@ -158,7 +190,7 @@ func (c *MotoristaController) AprovarMotorista(ctx *fiber.Ctx) error {
 
 // RejeitarMotorista PUT /api/motoristas/:id/rejeitar
 func (c *MotoristaController) RejeitarMotorista(ctx *fiber.Ctx) error {
-	motoristaID := ctx.Params("id")
+	motoristaID := models.MotoristaID(ctx.Params("id"))
 
 	var request struct {
 		Motivo string `json:"motivo" validate:"required"`
@@ -176,8 +208,17 @@ func (c *MotoristaController) RejeitarMotorista(ctx *fiber.Ctx) error {
 		})
 	}
 
-	err := c.motoristaService.RejeitarMotorista(motoristaID, request.Motivo)
+	versao, err := versaoIfMatch(ctx)
 	if err != nil {
+		return respostaPreCondicaoFalhou(ctx)
+	}
+
+	err = c.motoristaService.RejeitarMotorista(motoristaID, request.Motivo, versao)
+	if err != nil {
+		if errors.Is(err, services.ErrVersaoConflito) {
+			return respostaPreCondicaoFalhou(ctx)
+		}
+
 		statusCode := fiber.StatusBadRequest
 
 		if err.Error() == "Motorista não encontrado" {
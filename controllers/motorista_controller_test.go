@@ -34,27 +34,27 @@ func (m *MockMotoristaService) ValidarDadosCadastro(request services.CadastroMot
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) UploadDocumento(motoristaID string, request services.UploadDocumentoRequest) error {
-	args := m.Called(motoristaID, request)
+func (m *MockMotoristaService) UploadDocumento(motoristaID models.MotoristaID, request services.UploadDocumentoRequest, versaoEsperada int) error {
+	args := m.Called(motoristaID, request, versaoEsperada)
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) ValidarDocumentos(motoristaID string) error {
-	args := m.Called(motoristaID)
+func (m *MockMotoristaService) ValidarDocumentos(motoristaID models.MotoristaID, versaoEsperada int) error {
+	args := m.Called(motoristaID, versaoEsperada)
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) AprovarMotorista(motoristaID string) error {
-	args := m.Called(motoristaID)
+func (m *MockMotoristaService) AprovarMotorista(motoristaID models.MotoristaID, versaoEsperada int) error {
+	args := m.Called(motoristaID, versaoEsperada)
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) RejeitarMotorista(motoristaID string, motivo string) error {
-	args := m.Called(motoristaID, motivo)
+func (m *MockMotoristaService) RejeitarMotorista(motoristaID models.MotoristaID, motivo string, versaoEsperada int) error {
+	args := m.Called(motoristaID, motivo, versaoEsperada)
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) BuscarMotorista(id string) (*models.Motorista, error) {
+func (m *MockMotoristaService) BuscarMotorista(id models.MotoristaID) (*models.Motorista, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -166,7 +166,7 @@ func TestMotoristaController(t *testing.T) {
 			Email: "joao.silva@email.com",
 		}
 
-		mockService.On("BuscarMotorista", "123").Return(motorista, nil)
+		mockService.On("BuscarMotorista", models.MotoristaID("123")).Return(motorista, nil)
 
 		req := httptest.NewRequest("GET", "/api/motoristas/123", nil)
 		resp, err := app.Test(req)
@@ -184,7 +184,7 @@ func TestMotoristaController(t *testing.T) {
 
 	t.Run("Buscar motorista não encontrado", func(t *testing.T) {
 		app, mockService := setup()
-		mockService.On("BuscarMotorista", "999").Return(nil, assert.AnError)
+		mockService.On("BuscarMotorista", models.MotoristaID("999")).Return(nil, assert.AnError)
 
 		req := httptest.NewRequest("GET", "/api/motoristas/999", nil)
 		resp, err := app.Test(req)
@@ -204,7 +204,7 @@ func TestMotoristaController(t *testing.T) {
 			Tamanho:        2 * 1024 * 1024,
 		}
 
-		mockService.On("UploadDocumento", "123", uploadRequest).Return(nil)
+		mockService.On("UploadDocumento", models.MotoristaID("123"), uploadRequest, 0).Return(nil)
 
 		body, _ := json.Marshal(uploadRequest)
 		req := httptest.NewRequest("POST", "/api/motoristas/123/documentos", bytes.NewReader(body))
@@ -224,7 +224,7 @@ func TestMotoristaController(t *testing.T) {
 
 	t.Run("Validar documentos", func(t *testing.T) {
 		app, mockService := setup()
-		mockService.On("ValidarDocumentos", "123").Return(nil)
+		mockService.On("ValidarDocumentos", models.MotoristaID("123"), 0).Return(nil)
 
 		req := httptest.NewRequest("POST", "/api/motoristas/123/validar-documentos", nil)
 		resp, err := app.Test(req)
@@ -242,7 +242,7 @@ func TestMotoristaController(t *testing.T) {
 
 	t.Run("Aprovar motorista", func(t *testing.T) {
 		app, mockService := setup()
-		mockService.On("AprovarMotorista", "123").Return(nil)
+		mockService.On("AprovarMotorista", models.MotoristaID("123"), 0).Return(nil)
 
 		req := httptest.NewRequest("PUT", "/api/motoristas/123/aprovar", nil)
 		resp, err := app.Test(req)
@@ -258,13 +258,40 @@ func TestMotoristaController(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Aprovar motorista com If-Match desatualizado", func(t *testing.T) {
+		app, mockService := setup()
+		mockService.On("AprovarMotorista", models.MotoristaID("123"), 2).Return(services.ErrVersaoConflito)
+
+		req := httptest.NewRequest("PUT", "/api/motoristas/123/aprovar", nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Aprovar motorista com If-Match inválido", func(t *testing.T) {
+		app, mockService := setup()
+
+		req := httptest.NewRequest("PUT", "/api/motoristas/123/aprovar", nil)
+		req.Header.Set("If-Match", `"abc"`)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+		mockService.AssertNotCalled(t, "AprovarMotorista", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Rejeitar motorista", func(t *testing.T) {
 		app, mockService := setup()
 		rejectRequest := map[string]string{
 			"motivo": "Documentos com problemas de qualidade",
 		}
 
-		mockService.On("RejeitarMotorista", "123", "Documentos com problemas de qualidade").Return(nil)
+		mockService.On("RejeitarMotorista", models.MotoristaID("123"), "Documentos com problemas de qualidade", 0).Return(nil)
 
 		body, _ := json.Marshal(rejectRequest)
 		req := httptest.NewRequest("PUT", "/api/motoristas/123/rejeitar", bytes.NewReader(body))
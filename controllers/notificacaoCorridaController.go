@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 	"taxi-service/models"
@@ -10,15 +11,98 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// ListNotificacoesCorrida - Lista todas as notificações
+// CabecalhoProximoCursor traz o cursor da próxima página da listagem de notificações
+const CabecalhoProximoCursor = "X-Next-Cursor"
+
+// filtroNotificacoesDaQuery lê os filtros das listagens de notificações: status (separados por
+// vírgula), motorista_id, corrida_id e o período de criação em de e ate (RFC 3339 ou
+// AAAA-MM-DD; ate com apenas a data inclui o dia inteiro)
+func filtroNotificacoesDaQuery(c *fiber.Ctx) (services.FiltroNotificacoes, error) {
+	var filtro services.FiltroNotificacoes
+
+	if texto := c.Query("status"); texto != "" {
+		for _, parte := range strings.Split(texto, ",") {
+			status := models.NotificacaoStatus(strings.TrimSpace(parte))
+			if !status.Valido() {
+				return filtro, errors.New("Invalid status. Valid values: pendente, aceita, recusada, expirada, cancelada")
+			}
+			filtro.Status = append(filtro.Status, status)
+		}
+	}
+	if texto := c.Query("motorista_id"); texto != "" {
+		filtro.MotoristaID = models.MotoristaID(texto)
+		if !filtro.MotoristaID.Valido() {
+			return filtro, errors.New("Invalid motorista_id format")
+		}
+	}
+	if texto := c.Query("corrida_id"); texto != "" {
+		corridaID, err := strconv.ParseUint(texto, 10, 32)
+		if err != nil {
+			return filtro, errors.New("Invalid corrida_id format")
+		}
+		filtro.CorridaID = uint(corridaID)
+	}
+
+	var err error
+	if filtro.CriadaDe, err = dataDaQuery(c.Query("de"), false); err != nil {
+		return filtro, errors.New("Invalid de date, use RFC 3339 or YYYY-MM-DD")
+	}
+	if filtro.CriadaAte, err = dataDaQuery(c.Query("ate"), true); err != nil {
+		return filtro, errors.New("Invalid ate date, use RFC 3339 or YYYY-MM-DD")
+	}
+	return filtro, nil
+}
+
+// dataDaQuery interpreta uma data RFC 3339 ou AAAA-MM-DD. Com fimDoDia, a data sem horário
+// vale até o início do dia seguinte.
+func dataDaQuery(texto string, fimDoDia bool) (time.Time, error) {
+	if texto == "" {
+		return time.Time{}, nil
+	}
+	if data, err := time.Parse(time.RFC3339, texto); err == nil {
+		return data, nil
+	}
+	data, err := time.ParseInLocation(time.DateOnly, texto, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if fimDoDia {
+		data = data.AddDate(0, 0, 1)
+	}
+	return data, nil
+}
+
+// respostaErroListagemNotificacoes traduz os erros da listagem paginada
+func respostaErroListagemNotificacoes(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrCursorNotificacoesInvalido) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid cursor",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to fetch notificacoes",
+	})
+}
+
+// ListNotificacoesCorrida - Lista as notificações em páginas, com filtros por status,
+// motorista, corrida e período (ver filtroNotificacoesDaQuery). O cursor da próxima página,
+// quando houver, vem no cabeçalho X-Next-Cursor e é enviado de volta em ?cursor=.
 func ListNotificacoesCorrida(c *fiber.Ctx) error {
-	notificacoes, err := services.ListNotificacoesCorrida()
+	filtro, err := filtroNotificacoesDaQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch notificacoes",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
-	return c.Status(fiber.StatusOK).JSON(notificacoes)
+
+	pagina, err := services.ListarNotificacoes(filtro, c.Query("cursor"), c.QueryInt("limite"))
+	if err != nil {
+		return respostaErroListagemNotificacoes(c, err)
+	}
+	if pagina.ProximoCursor != "" {
+		c.Set(CabecalhoProximoCursor, pagina.ProximoCursor)
+	}
+	return c.Status(fiber.StatusOK).JSON(pagina.Notificacoes)
 }
 
 // GetNotificacaoCorrida - Busca notificação por ID
@@ -63,7 +147,7 @@ func CreateNotificacaoCorrida(c *fiber.Ctx) error {
 	}
 
 	// Validar dados obrigatórios
-	if notificacao.MotoristaID == 0 {
+	if !notificacao.MotoristaID.Valido() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "MotoristaID is required",
 		})
@@ -86,6 +170,11 @@ func CreateNotificacaoCorrida(c *fiber.Ctx) error {
 
 	err := services.CreateNotificacaoCorrida(notificacao)
 	if err != nil {
+		if errors.Is(err, services.ErrTTLOfertaInvalido) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create notificacao",
 		})
@@ -104,14 +193,14 @@ func GetNotificacoesPendentesParaMotorista(c *fiber.Ctx) error {
 		})
 	}
 
-	motoristaID, err := strconv.ParseUint(motoristaIDParam, 10, 32)
-	if err != nil {
+	motoristaID := models.MotoristaID(motoristaIDParam)
+	if !motoristaID.Valido() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid MotoristaID format",
 		})
 	}
 
-	notificacoes, err := services.GetNotificacoesPendentesParaMotorista(uint(motoristaID))
+	notificacoes, err := services.GetNotificacoesPendentesParaMotorista(motoristaID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch pending notificacoes",
@@ -143,15 +232,27 @@ func AceitarNotificacaoCorrida(c *fiber.Ctx) error {
 		})
 	}
 
-	mID, err := strconv.ParseUint(motoristaIDParam, 10, 32)
-	if err != nil {
+	mID := models.MotoristaID(motoristaIDParam)
+	if !mID.Valido() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid MotoristaID format",
 		})
 	}
 
-	err = services.AceitarNotificacaoCorrida(uint(nID), uint(mID))
+	err = services.AceitarNotificacaoCorrida(uint(nID), mID)
 	if err != nil {
+		if errors.Is(err, services.ErrCorridaJaAceita) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Ride already accepted by another driver",
+			})
+		}
+		var erroElegibilidade *services.ErroElegibilidade
+		if errors.As(err, &erroElegibilidade) {
+			return c.Status(statusElegibilidade(erroElegibilidade.Codigo)).JSON(fiber.Map{
+				"error": erroElegibilidade.Mensagem,
+				"code":  erroElegibilidade.Codigo,
+			})
+		}
 		if strings.Contains(err.Error(), "expired") {
 			return c.Status(fiber.StatusGone).JSON(fiber.Map{
 				"error": "Notificacao expired",
@@ -197,14 +298,14 @@ func RecusarNotificacaoCorrida(c *fiber.Ctx) error {
 		})
 	}
 
-	mID, err := strconv.ParseUint(motoristaIDParam, 10, 32)
-	if err != nil {
+	mID := models.MotoristaID(motoristaIDParam)
+	if !mID.Valido() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid MotoristaID format",
 		})
 	}
 
-	err = services.RecusarNotificacaoCorrida(uint(nID), uint(mID))
+	err = services.RecusarNotificacaoCorrida(uint(nID), mID)
 	if err != nil {
 		if strings.Contains(err.Error(), "already processed") {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
@@ -253,47 +354,44 @@ func GetHistoricoNotificacoesMotorista(c *fiber.Ctx) error {
 		})
 	}
 
-	motoristaID, err := strconv.ParseUint(motoristaIDParam, 10, 32)
-	if err != nil {
+	motoristaID := models.MotoristaID(motoristaIDParam)
+	if !motoristaID.Valido() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid MotoristaID format",
 		})
 	}
 
-	historico, err := services.GetHistoricoNotificacoesMotorista(uint(motoristaID))
+	filtro, err := filtroNotificacoesDaQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch historico",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
+	filtro.MotoristaID = motoristaID
 
-	// Organizar histórico por status
-	aceitas := 0
-	recusadas := 0
-	expiradas := 0
-	pendentes := 0
+	pagina, err := services.ListarNotificacoes(filtro, c.Query("cursor"), c.QueryInt("limite"))
+	if err != nil {
+		return respostaErroListagemNotificacoes(c, err)
+	}
 
-	for _, notif := range historico {
-		switch notif.Status {
-		case models.NotificacaoAceita:
-			aceitas++
-		case models.NotificacaoRecusada:
-			recusadas++
-		case models.NotificacaoExpirada:
-			expiradas++
-		case models.NotificacaoPendente:
-			pendentes++
-		}
+	// As contagens cobrem todo o histórico filtrado, não só a página
+	porStatus, total, err := services.ContarNotificacoesPorStatus(filtro)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch historico",
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"motorista_id":    motoristaID,
-		"total_count":     len(historico),
-		"aceitas_count":   aceitas,
-		"recusadas_count": recusadas,
-		"expiradas_count": expiradas,
-		"pendentes_count": pendentes,
-		"historico":       historico,
+		"motorista_id":     motoristaID,
+		"total_count":      total,
+		"aceitas_count":    porStatus[models.NotificacaoAceita],
+		"recusadas_count":  porStatus[models.NotificacaoRecusada],
+		"expiradas_count":  porStatus[models.NotificacaoExpirada],
+		"pendentes_count":  porStatus[models.NotificacaoPendente],
+		"canceladas_count": porStatus[models.NotificacaoCancelada],
+		"historico":        pagina.Notificacoes,
+		"next_cursor":      pagina.ProximoCursor,
 	})
 }
 
@@ -332,63 +430,87 @@ func DeleteNotificacaoCorrida(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateNotificacaoStatus - Atualiza status de uma notificação (função auxiliar)
-func UpdateNotificacaoStatus(c *fiber.Ctx) error {
-	id := c.Params("id")
-	newStatus := c.Query("status")
-
-	if id == "" || newStatus == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "ID and status are required",
-		})
-	}
+// AlterarStatusNotificacaoRequest é o corpo da mudança manual de status. Por e motivo ficam
+// no histórico da notificação; o status também é aceito na query (?status=).
+type AlterarStatusNotificacaoRequest struct {
+	Status models.NotificacaoStatus `json:"status"`
+	Por    string                   `json:"por"`
+	Motivo string                   `json:"motivo"`
+}
 
-	notificacaoID, err := strconv.ParseUint(id, 10, 32)
+// UpdateNotificacaoStatus - Aplica uma mudança de status pedida pelo suporte (ex.: destravar
+// uma oferta presa). Só ofertas pendentes mudam: para aceita, recusada, expirada ou cancelada.
+func UpdateNotificacaoStatus(c *fiber.Ctx) error {
+	notificacaoID, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid ID format",
 		})
 	}
 
-	// Validar status
-	validStatuses := []string{
-		"pendente",
-		"aceita",
-		"recusada",
-		"expirada",
-	}
-
-	statusValido := false
-	for _, validStatus := range validStatuses {
-		if validStatus == newStatus {
-			statusValido = true
-			break
+	var request AlterarStatusNotificacaoRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body: " + err.Error(),
+			})
 		}
 	}
-
-	if !statusValido {
+	if request.Status == "" {
+		request.Status = models.NotificacaoStatus(c.Query("status"))
+	}
+	if request.Status == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid status. Valid values: pendente, aceita, recusada, expirada",
+			"error": "status is required",
 		})
 	}
 
-	// Buscar a notificação atual
-	notificacao, err := services.GetNotificacaoCorrida(uint(notificacaoID))
+	notificacao, err := services.AlterarStatusNotificacao(uint(notificacaoID), request.Status, request.Por, request.Motivo)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		var erroElegibilidade *services.ErroElegibilidade
+		switch {
+		case errors.Is(err, services.ErrStatusNotificacaoInvalido):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid status. Valid values: pendente, aceita, recusada, expirada, cancelada",
+			})
+		case errors.Is(err, services.ErrAutoriaMudancaObrigatoria):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrNotificacaoNaoEncontrada):
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Notificacao not found",
 			})
+		case errors.Is(err, services.ErrTransicaoNotificacaoInvalida):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   err.Error(),
+				"current": notificacao,
+			})
+		case errors.Is(err, services.ErrNotificacaoJaProcessada):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Notificacao already processed",
+			})
+		case errors.Is(err, services.ErrCorridaJaAceita):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Ride already accepted by another driver",
+			})
+		case errors.As(err, &erroElegibilidade):
+			return c.Status(statusElegibilidade(erroElegibilidade.Codigo)).JSON(fiber.Map{
+				"error": erroElegibilidade.Mensagem,
+				"code":  erroElegibilidade.Codigo,
+			})
+		case strings.Contains(err.Error(), "expired"):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "Notificacao expired",
+			})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch notificacao",
+			"error": "Failed to update notificacao status",
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message":    "Status update endpoint - implementation needed",
-		"current":    notificacao,
-		"new_status": newStatus,
-		"suggestion": "Use specific endpoints: /accept, /refuse, or /expire",
+		"message":     "Notificacao status updated successfully",
+		"notificacao": notificacao,
 	})
 }
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// NotificadorController expõe a caixa de entrada e as preferências de canais de notificação
+// de passageiros e motoristas
+type NotificadorController struct {
+	roteador *services.RoteadorNotificacoes
+	caixa    *services.CaixaEntrada
+}
+
+// NewNotificadorController cria uma nova instância do controller
+func NewNotificadorController(roteador *services.RoteadorNotificacoes, caixa *services.CaixaEntrada) *NotificadorController {
+	return &NotificadorController{
+		roteador: roteador,
+		caixa:    caixa,
+	}
+}
+
+// ListarCaixaEntrada GET /api/{motoristas|passageiros}/:id/notificacoes
+// Retorna as mensagens do destinatário, da mais nova à mais antiga
+func (c *NotificadorController) ListarCaixaEntrada(tipo models.TipoDestinatario) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		destinatario := models.Destinatario{Tipo: tipo, ID: ctx.Params("id")}
+		return ctx.JSON(c.caixa.Mensagens(destinatario))
+	}
+}
+
+// BuscarPreferencias GET /api/{motoristas|passageiros}/:id/notificacoes/preferencias
+func (c *NotificadorController) BuscarPreferencias(tipo models.TipoDestinatario) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		destinatario := models.Destinatario{Tipo: tipo, ID: ctx.Params("id")}
+		preferencias, err := c.roteador.Preferencias(destinatario)
+		if err != nil {
+			return respostaErroPreferencias(ctx, err)
+		}
+		return ctx.JSON(preferencias)
+	}
+}
+
+// DefinirPreferencias PUT /api/{motoristas|passageiros}/:id/notificacoes/preferencias
+func (c *NotificadorController) DefinirPreferencias(tipo models.TipoDestinatario) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var preferencias models.PreferenciasNotificacao
+		if err := ctx.BodyParser(&preferencias); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Dados inválidos",
+			})
+		}
+
+		destinatario := models.Destinatario{Tipo: tipo, ID: ctx.Params("id")}
+		if err := c.roteador.DefinirPreferencias(destinatario, preferencias); err != nil {
+			return respostaErroPreferencias(ctx, err)
+		}
+		return ctx.JSON(preferencias)
+	}
+}
+
+// respostaErroPreferencias traduz os erros das preferências de notificação em status HTTP
+func respostaErroPreferencias(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrPreferenciasInvalidas):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrDestinatarioNaoEncontrado):
+		status = fiber.StatusNotFound
+	}
+	return ctx.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/services"
+)
+
+// RetencaoNotificacoesController expõe a aplicação manual da política de retenção das notificações
+type RetencaoNotificacoesController struct {
+	retencao *services.RetencaoNotificacoes
+}
+
+// NewRetencaoNotificacoesController cria uma nova instância do controller
+func NewRetencaoNotificacoesController(retencao *services.RetencaoNotificacoes) *RetencaoNotificacoesController {
+	return &RetencaoNotificacoesController{
+		retencao: retencao,
+	}
+}
+
+// AplicarRetencaoRequest é o corpo da aplicação manual. Sem dias, vale o prazo configurado;
+// simular vale true quando omitido, para que nada seja removido sem pedido explícito.
+type AplicarRetencaoRequest struct {
+	Dias    int   `json:"dias"`
+	Simular *bool `json:"simular"`
+}
+
+// AplicarRetencao POST /notificacoes/retencao
+// Responde com o relatório das notificações arquivadas ou excluídas (ou que seriam, na simulação)
+func (c *RetencaoNotificacoesController) AplicarRetencao(ctx *fiber.Ctx) error {
+	var request AplicarRetencaoRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Corpo da requisição inválido: " + err.Error(),
+			})
+		}
+	}
+	if request.Dias < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "dias deve ser positivo",
+		})
+	}
+	simular := request.Simular == nil || *request.Simular
+
+	relatorio, err := c.retencao.Aplicar(request.Dias, simular)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrRetencaoInvalida) {
+			status = fiber.StatusBadRequest
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.JSON(relatorio)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/services"
+)
+
+// TarifaDinamicaController expõe a situação das zonas e os limites definidos pelo administrador
+type TarifaDinamicaController struct {
+	tarifaDinamica *services.TarifaDinamicaService
+}
+
+// NewTarifaDinamicaController cria uma nova instância do controller
+func NewTarifaDinamicaController(tarifaDinamica *services.TarifaDinamicaService) *TarifaDinamicaController {
+	return &TarifaDinamicaController{
+		tarifaDinamica: tarifaDinamica,
+	}
+}
+
+// ConsultarPosicao GET /tarifa-dinamica?lat=&lng=
+func (c *TarifaDinamicaController) ConsultarPosicao(ctx *fiber.Ctx) error {
+	lat, errLat := strconv.ParseFloat(ctx.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(ctx.Query("lng"), 64)
+	if errLat != nil || errLng != nil || !services.CoordenadasValidas(lat, lng) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "lat e lng são obrigatórios e devem ser coordenadas válidas",
+		})
+	}
+
+	return ctx.JSON(c.tarifaDinamica.Consultar(lat, lng))
+}
+
+// ConsultarZona GET /admin/tarifa-dinamica/zonas/:zona
+func (c *TarifaDinamicaController) ConsultarZona(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.tarifaDinamica.ConsultarZona(ctx.Params("zona")))
+}
+
+// DefinirLimite PUT /admin/tarifa-dinamica/zonas/:zona/limite
+// Corpo: {"multiplicadorMaximo": 1.5} ou {"desativar": true}
+func (c *TarifaDinamicaController) DefinirLimite(ctx *fiber.Ctx) error {
+	var body struct {
+		MultiplicadorMaximo float64 `json:"multiplicadorMaximo"`
+		Desativar           bool    `json:"desativar"`
+	}
+	if err := ctx.BodyParser(&body); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Corpo da requisição inválido",
+		})
+	}
+
+	limite := services.LimiteZona{MultiplicadorMaximo: body.MultiplicadorMaximo}
+	if body.Desativar {
+		limite.MultiplicadorMaximo = 1
+	}
+
+	zona := ctx.Params("zona")
+	if err := c.tarifaDinamica.DefinirLimite(zona, limite); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.JSON(c.tarifaDinamica.ConsultarZona(zona))
+}
+
+// RemoverLimite DELETE /admin/tarifa-dinamica/zonas/:zona/limite
+func (c *TarifaDinamicaController) RemoverLimite(ctx *fiber.Ctx) error {
+	c.tarifaDinamica.RemoverLimite(ctx.Params("zona"))
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
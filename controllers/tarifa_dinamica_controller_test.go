@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/services"
+)
+
+func TestTarifaDinamicaController(t *testing.T) {
+	app := fiber.New()
+	tarifa := services.NewTarifaDinamicaService(nil, nil, services.ConfigTarifaDinamica{Ativa: true})
+	t.Cleanup(tarifa.Parar)
+	controller := NewTarifaDinamicaController(tarifa)
+	app.Get("/api/tarifa-dinamica", controller.ConsultarPosicao)
+	app.Get("/api/admin/tarifa-dinamica/zonas/:zona", controller.ConsultarZona)
+	app.Put("/api/admin/tarifa-dinamica/zonas/:zona/limite", controller.DefinirLimite)
+	app.Delete("/api/admin/tarifa-dinamica/zonas/:zona/limite", controller.RemoverLimite)
+
+	enviar := func(t *testing.T, method, path string, corpo interface{}) (int, map[string]interface{}) {
+		body, _ := json.Marshal(corpo)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var resposta map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&resposta)
+		return resp.StatusCode, resposta
+	}
+
+	status, resposta := enviar(t, "GET", "/api/tarifa-dinamica?lat=-8.0631&lng=-34.8711", nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1.0, resposta["multiplicador"])
+	zona := url.PathEscape(resposta["zona"].(string))
+
+	status, _ = enviar(t, "GET", "/api/tarifa-dinamica?lat=0&lng=0", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, resposta = enviar(t, "PUT", "/api/admin/tarifa-dinamica/zonas/"+zona+"/limite", map[string]interface{}{"desativar": true})
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1.0, resposta["limite"].(map[string]interface{})["multiplicadorMaximo"])
+
+	status, _ = enviar(t, "PUT", "/api/admin/tarifa-dinamica/zonas/"+zona+"/limite", map[string]interface{}{"multiplicadorMaximo": 0.5})
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = enviar(t, "DELETE", "/api/admin/tarifa-dinamica/zonas/"+zona+"/limite", nil)
+	assert.Equal(t, fiber.StatusNoContent, status)
+	_, resposta = enviar(t, "GET", "/api/admin/tarifa-dinamica/zonas/"+zona, nil)
+	assert.Nil(t, resposta["limite"])
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errIfMatchInvalido indica um cabeçalho If-Match que não corresponde a nenhuma versão
+var errIfMatchInvalido = errors.New("If-Match não corresponde à versão atual")
+
+// definirETag publica a versão do recurso no cabeçalho ETag
+func definirETag(c *fiber.Ctx, versao int) {
+	c.Set(fiber.HeaderETag, `"`+strconv.Itoa(versao)+`"`)
+}
+
+// versaoIfMatch extrai a versão esperada do cabeçalho If-Match.
+// Retorna 0 quando o cabeçalho está ausente ou é "*", dispensando a verificação.
+func versaoIfMatch(c *fiber.Ctx) (int, error) {
+	valor := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if valor == "" || valor == "*" {
+		return 0, nil
+	}
+
+	valor = strings.TrimPrefix(valor, "W/")
+	valor = strings.Trim(valor, `"`)
+
+	versao, err := strconv.Atoi(valor)
+	if err != nil || versao <= 0 {
+		return 0, errIfMatchInvalido
+	}
+	return versao, nil
+}
+
+// respostaPreCondicaoFalhou responde 412 quando a versão enviada não é a atual
+func respostaPreCondicaoFalhou(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"error": "O recurso foi alterado por outra operação. Busque a versão atual e tente novamente",
+	})
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// WebhookController expõe as assinaturas de webhook e as entregas com falha
+type WebhookController struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookController cria uma nova instância do controller
+func NewWebhookController(webhookService *services.WebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+// AssinaturaWebhookRequest é o corpo do cadastro e da atualização de uma assinatura.
+// Sem segredo, um é gerado no cadastro; ativa vale true quando omitida.
+type AssinaturaWebhookRequest struct {
+	URL     string                     `json:"url"`
+	Eventos []models.TipoEventoWebhook `json:"eventos"`
+	Segredo string                     `json:"segredo"`
+	Ativa   *bool                      `json:"ativa"`
+}
+
+func (r AssinaturaWebhookRequest) assinatura() models.AssinaturaWebhook {
+	return models.AssinaturaWebhook{
+		URL:     r.URL,
+		Eventos: r.Eventos,
+		Segredo: r.Segredo,
+		Ativa:   r.Ativa == nil || *r.Ativa,
+	}
+}
+
+// semSegredo omite o segredo das respostas; ele só é mostrado no cadastro
+func semSegredo(assinatura models.AssinaturaWebhook) models.AssinaturaWebhook {
+	assinatura.Segredo = ""
+	return assinatura
+}
+
+// ListarAssinaturas GET /admin/webhooks
+func (c *WebhookController) ListarAssinaturas(ctx *fiber.Ctx) error {
+	assinaturas, err := c.webhookService.ListarAssinaturas()
+	if err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	for i := range assinaturas {
+		assinaturas[i] = semSegredo(assinaturas[i])
+	}
+	return ctx.JSON(fiber.Map{"assinaturas": assinaturas, "eventos": models.EventosWebhook})
+}
+
+// BuscarAssinatura GET /admin/webhooks/:id
+func (c *WebhookController) BuscarAssinatura(ctx *fiber.Ctx) error {
+	assinatura, err := c.webhookService.BuscarAssinatura(ctx.Params("id"))
+	if err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.JSON(semSegredo(assinatura))
+}
+
+// CriarAssinatura POST /admin/webhooks
+// A resposta traz o segredo usado nas assinaturas HMAC-SHA256 das entregas
+func (c *WebhookController) CriarAssinatura(ctx *fiber.Ctx) error {
+	var request AssinaturaWebhookRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Corpo da requisição inválido: " + err.Error(),
+		})
+	}
+
+	assinatura, err := c.webhookService.CriarAssinatura(request.assinatura())
+	if err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(assinatura)
+}
+
+// AtualizarAssinatura PUT /admin/webhooks/:id
+func (c *WebhookController) AtualizarAssinatura(ctx *fiber.Ctx) error {
+	var request AssinaturaWebhookRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Corpo da requisição inválido: " + err.Error(),
+		})
+	}
+
+	assinatura, err := c.webhookService.AtualizarAssinatura(ctx.Params("id"), request.assinatura())
+	if err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.JSON(semSegredo(assinatura))
+}
+
+// DeletarAssinatura DELETE /admin/webhooks/:id
+func (c *WebhookController) DeletarAssinatura(ctx *fiber.Ctx) error {
+	if err := c.webhookService.DeletarAssinatura(ctx.Params("id")); err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ListarFalhas GET /admin/webhooks/falhas
+// Entregas que esgotaram as tentativas, aguardando reenvio manual
+func (c *WebhookController) ListarFalhas(ctx *fiber.Ctx) error {
+	falhas, err := c.webhookService.ListarFalhas()
+	if err != nil {
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.JSON(fiber.Map{"falhas": falhas})
+}
+
+// ReenviarFalha POST /admin/webhooks/falhas/:id/reenviar
+// Faz uma nova tentativa imediata; responde 502 se o parceiro ainda recusar a entrega
+func (c *WebhookController) ReenviarFalha(ctx *fiber.Ctx) error {
+	entrega, err := c.webhookService.ReenviarFalha(ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrEntregaWebhook) {
+			return ctx.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error":   err.Error(),
+				"entrega": entrega,
+			})
+		}
+		return respostaErroWebhook(ctx, err)
+	}
+	return ctx.JSON(entrega)
+}
+
+// respostaErroWebhook traduz os erros do WebhookService em status HTTP
+func respostaErroWebhook(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrAssinaturaWebhookNaoEncontrada), errors.Is(err, services.ErrFalhaWebhookNaoEncontrada):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrAssinaturaWebhookInvalida):
+		status = fiber.StatusBadRequest
+	}
+	return ctx.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"taxi-service/models"
+	"taxi-service/services"
+)
+
+// ZonaController expõe o cadastro das zonas geográficas e a consulta por posição
+type ZonaController struct {
+	zonaService *services.ZonaService
+}
+
+// NewZonaController cria uma nova instância do controller
+func NewZonaController(zonaService *services.ZonaService) *ZonaController {
+	return &ZonaController{
+		zonaService: zonaService,
+	}
+}
+
+// ZonaRequest é o corpo do cadastro e da atualização de uma zona.
+// A geometria é um Polygon ou MultiPolygon do GeoJSON; ativa vale true quando omitida.
+type ZonaRequest struct {
+	Nome      string           `json:"nome"`
+	Tipo      models.TipoZona  `json:"tipo"`
+	Geometria models.Geometria `json:"geometria"`
+	Ativa     *bool            `json:"ativa"`
+}
+
+func (r ZonaRequest) zona() models.Zona {
+	return models.Zona{
+		Nome:      r.Nome,
+		Tipo:      r.Tipo,
+		Geometria: r.Geometria,
+		Ativa:     r.Ativa == nil || *r.Ativa,
+	}
+}
+
+// ListarZonas GET /admin/zonas
+func (c *ZonaController) ListarZonas(ctx *fiber.Ctx) error {
+	zonas, err := c.zonaService.ListarZonas()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.JSON(fiber.Map{"zonas": zonas})
+}
+
+// BuscarZona GET /admin/zonas/:id
+func (c *ZonaController) BuscarZona(ctx *fiber.Ctx) error {
+	zona, err := c.zonaService.BuscarZona(ctx.Params("id"))
+	if err != nil {
+		return respostaErroZona(ctx, err)
+	}
+
+	definirETag(ctx, zona.Versao)
+	return ctx.JSON(zona)
+}
+
+// CriarZona POST /admin/zonas
+func (c *ZonaController) CriarZona(ctx *fiber.Ctx) error {
+	var request ZonaRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Corpo da requisição inválido: " + err.Error(),
+		})
+	}
+
+	zona, err := c.zonaService.CriarZona(request.zona())
+	if err != nil {
+		return respostaErroZona(ctx, err)
+	}
+
+	definirETag(ctx, zona.Versao)
+	return ctx.Status(fiber.StatusCreated).JSON(zona)
+}
+
+// AtualizarZona PUT /admin/zonas/:id
+// Aceita If-Match com a versão lida; responde 412 se a zona foi alterada desde então.
+func (c *ZonaController) AtualizarZona(ctx *fiber.Ctx) error {
+	versao, err := versaoIfMatch(ctx)
+	if err != nil {
+		return respostaPreCondicaoFalhou(ctx)
+	}
+
+	var request ZonaRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Corpo da requisição inválido: " + err.Error(),
+		})
+	}
+
+	zona, err := c.zonaService.AtualizarZona(ctx.Params("id"), request.zona(), versao)
+	if err != nil {
+		return respostaErroZona(ctx, err)
+	}
+
+	definirETag(ctx, zona.Versao)
+	return ctx.JSON(zona)
+}
+
+// DeletarZona DELETE /admin/zonas/:id
+func (c *ZonaController) DeletarZona(ctx *fiber.Ctx) error {
+	if err := c.zonaService.DeletarZona(ctx.Params("id")); err != nil {
+		return respostaErroZona(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ImportarGeoJSON POST /admin/zonas/geojson
+// Corpo: FeatureCollection com as propriedades nome, tipo e (opcional) ativa em cada feature
+func (c *ZonaController) ImportarGeoJSON(ctx *fiber.Ctx) error {
+	zonas, err := c.zonaService.ImportarGeoJSON(ctx.Body())
+	if err != nil {
+		return respostaErroZona(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"zonas": zonas})
+}
+
+// ExportarGeoJSON GET /admin/zonas/geojson
+func (c *ZonaController) ExportarGeoJSON(ctx *fiber.Ctx) error {
+	data, err := c.zonaService.ExportarGeoJSON()
+	if err != nil {
+		return respostaErroZona(ctx, err)
+	}
+	ctx.Set(fiber.HeaderContentType, "application/geo+json")
+	return ctx.Send(data)
+}
+
+// Localizar GET /zonas/localizar?lat=&lng=
+// Informa as zonas ativas que contêm a posição e se um embarque ali seria aceito
+func (c *ZonaController) Localizar(ctx *fiber.Ctx) error {
+	lat, errLat := strconv.ParseFloat(ctx.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(ctx.Query("lng"), 64)
+	if errLat != nil || errLng != nil || !services.CoordenadasValidas(lat, lng) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "lat e lng são obrigatórios e devem ser coordenadas válidas",
+		})
+	}
+
+	resposta := fiber.Map{
+		"zonas":              c.zonaService.Localizar(lat, lng),
+		"embarque_permitido": true,
+	}
+	if err := c.zonaService.ValidarEmbarque(lat, lng); err != nil {
+		resposta["embarque_permitido"] = false
+		resposta["motivo"] = err.Error()
+	}
+	return ctx.JSON(resposta)
+}
+
+// respostaErroZona traduz os erros do ZonaService em status HTTP
+func respostaErroZona(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrVersaoConflito):
+		return respostaPreCondicaoFalhou(ctx)
+	case errors.Is(err, services.ErrZonaNaoEncontrada):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrZonaInvalida), errors.Is(err, services.ErrGeoJSONInvalido):
+		status = fiber.StatusBadRequest
+	}
+	return ctx.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/repositories"
+	"taxi-service/services"
+)
+
+func TestZonaController(t *testing.T) {
+	original, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(original) })
+
+	app := fiber.New()
+	controller := NewZonaController(services.NewZonaService(repositories.NewJSONZonaRepository()))
+	app.Get("/api/zonas/localizar", controller.Localizar)
+	app.Post("/api/admin/zonas", controller.CriarZona)
+	app.Get("/api/admin/zonas/:id", controller.BuscarZona)
+	app.Put("/api/admin/zonas/:id", controller.AtualizarZona)
+
+	enviar := func(t *testing.T, method, path, ifMatch string, corpo interface{}) (int, map[string]interface{}) {
+		body, _ := json.Marshal(corpo)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var resposta map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&resposta)
+		return resp.StatusCode, resposta
+	}
+
+	zona := map[string]interface{}{
+		"nome": "Recife",
+		"tipo": "area_atendimento",
+		"geometria": map[string]interface{}{
+			"type":        "Polygon",
+			"coordinates": [][][]float64{{{-35.0, -8.2}, {-34.8, -8.2}, {-34.8, -7.9}, {-35.0, -7.9}, {-35.0, -8.2}}},
+		},
+	}
+
+	status, criada := enviar(t, "POST", "/api/admin/zonas", "", zona)
+	require.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, true, criada["ativa"])
+	id := criada["id"].(string)
+
+	status, resposta := enviar(t, "GET", "/api/zonas/localizar?lat=-8.05&lng=-34.9", "", nil)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, resposta["embarque_permitido"])
+	assert.Len(t, resposta["zonas"], 1)
+
+	_, resposta = enviar(t, "GET", "/api/zonas/localizar?lat=-23.55&lng=-46.63", "", nil)
+	assert.Equal(t, false, resposta["embarque_permitido"])
+
+	zona["nome"] = "Grande Recife"
+	status, _ = enviar(t, "PUT", "/api/admin/zonas/"+id, `"1"`, zona)
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = enviar(t, "PUT", "/api/admin/zonas/"+id, `"1"`, zona)
+	assert.Equal(t, fiber.StatusPreconditionFailed, status)
+
+	zona["tipo"] = "desconhecido"
+	status, _ = enviar(t, "POST", "/api/admin/zonas", "", zona)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = enviar(t, "GET", "/api/admin/zonas/inexistente", "", nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
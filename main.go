@@ -2,11 +2,23 @@ package main
 
 import (
     "github.com/gofiber/fiber/v2"
+    "log"
+
+    "taxi-service/repositories"
     "taxi-service/routes"
     "taxi-service/services"
 )
 
 func main() {
+	// Converte identificadores numéricos de motorista gravados por versões anteriores
+	if err := repositories.MigrarIdentificadoresMotorista(
+		"data/corridas.json",
+		"data/notificacao_corrida.json",
+		"data/motoristas.json",
+	); err != nil {
+		log.Fatalf("Erro ao migrar identificadores de motorista: %v", err)
+	}
+
 	// Carrega as corridas do JSON
 	services.CarregarCorridasDoArquivo()
 
@@ -0,0 +1,183 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderIdempotencia é o cabeçalho enviado pelos clientes para identificar retentativas
+const HeaderIdempotencia = "Idempotency-Key"
+
+// ttlIdempotenciaPadrao é o tempo padrão de retenção das respostas armazenadas
+const ttlIdempotenciaPadrao = 24 * time.Hour
+
+// localIdempotencia marca a requisição já tratada pelo middleware
+const localIdempotencia = "idempotencia"
+
+// respostaIdempotente guarda a primeira resposta produzida para uma chave
+type respostaIdempotente struct {
+	impressao   string
+	emAndamento bool
+	status      int
+	contentType string
+	corpo       []byte
+	expiraEm    time.Time
+}
+
+// IdempotenciaStore armazena em memória as respostas associadas a cada Idempotency-Key,
+// por método e rota: a mesma chave em outra rota é outra requisição
+type IdempotenciaStore struct {
+	ttl           time.Duration
+	respostas     map[string]*respostaIdempotente
+	ultimaLimpeza time.Time
+	mutex         sync.Mutex
+}
+
+// NewIdempotenciaStore cria um novo armazenamento com o TTL informado
+func NewIdempotenciaStore(ttl time.Duration) *IdempotenciaStore {
+	if ttl <= 0 {
+		ttl = ttlIdempotenciaPadrao
+	}
+	return &IdempotenciaStore{
+		ttl:       ttl,
+		respostas: make(map[string]*respostaIdempotente),
+	}
+}
+
+// NewIdempotenciaStoreFromEnv cria o armazenamento usando IDEMPOTENCY_TTL (ex.: "24h", "30m")
+func NewIdempotenciaStoreFromEnv() *IdempotenciaStore {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil {
+		ttl = ttlIdempotenciaPadrao
+	}
+	return NewIdempotenciaStore(ttl)
+}
+
+// reservar registra a chave como em andamento ou devolve a entrada já existente
+func (s *IdempotenciaStore) reservar(chave, impressao string) (*respostaIdempotente, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	agora := time.Now()
+	s.limparExpiradas(agora)
+
+	if existente, ok := s.respostas[chave]; ok && agora.Before(existente.expiraEm) {
+		copia := *existente
+		return &copia, false
+	}
+
+	s.respostas[chave] = &respostaIdempotente{
+		impressao:   impressao,
+		emAndamento: true,
+		expiraEm:    agora.Add(s.ttl),
+	}
+	return nil, true
+}
+
+// concluir grava a resposta final de uma chave reservada
+func (s *IdempotenciaStore) concluir(chave string, status int, contentType string, corpo []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entrada, ok := s.respostas[chave]
+	if !ok {
+		return
+	}
+	entrada.emAndamento = false
+	entrada.status = status
+	entrada.contentType = contentType
+	entrada.corpo = append([]byte(nil), corpo...)
+	entrada.expiraEm = time.Now().Add(s.ttl)
+}
+
+// liberar descarta a reserva para que o cliente possa tentar novamente
+func (s *IdempotenciaStore) liberar(chave string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.respostas, chave)
+}
+
+// limparExpiradas remove entradas vencidas; deve ser chamada com o mutex travado
+func (s *IdempotenciaStore) limparExpiradas(agora time.Time) {
+	if agora.Sub(s.ultimaLimpeza) < time.Minute {
+		return
+	}
+	s.ultimaLimpeza = agora
+	for chave, entrada := range s.respostas {
+		if !agora.Before(entrada.expiraEm) {
+			delete(s.respostas, chave)
+		}
+	}
+}
+
+// impressaoRequisicao identifica o método, a rota e o corpo da requisição
+func impressaoRequisicao(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Idempotencia repete a primeira resposta para retentativas com a mesma Idempotency-Key.
+// Requisições sem o cabeçalho ou com métodos seguros seguem normalmente. Cada requisição é
+// tratada uma única vez, mesmo quando o middleware de um grupo também alcança rotas irmãs
+// com o mesmo prefixo (/corrida e /corridas) que têm o seu próprio.
+func Idempotencia(store *IdempotenciaStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		chave := c.Get(HeaderIdempotencia)
+		if chave == "" || c.Locals(localIdempotencia) != nil {
+			return c.Next()
+		}
+		c.Locals(localIdempotencia, true)
+		chave = c.Method() + " " + c.Path() + "\x00" + chave
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		impressao := impressaoRequisicao(c)
+		existente, reservada := store.reservar(chave, impressao)
+		if !reservada {
+			if existente.impressao != impressao {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"error": "Idempotency-Key já utilizada com uma requisição diferente",
+				})
+			}
+			if existente.emAndamento {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": "Requisição com esta Idempotency-Key ainda está em processamento",
+				})
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			if existente.contentType != "" {
+				c.Set(fiber.HeaderContentType, existente.contentType)
+			}
+			return c.Status(existente.status).Send(existente.corpo)
+		}
+
+		if err := c.Next(); err != nil {
+			store.liberar(chave)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		// Erros do servidor não são armazenados para permitir nova tentativa
+		if status >= fiber.StatusInternalServerError {
+			store.liberar(chave)
+			return nil
+		}
+
+		store.concluir(chave, status, string(c.Response().Header.ContentType()), c.Response().Body())
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencia(t *testing.T) {
+	// setup cria um app com um handler que conta quantas vezes foi executado. O grupo /corrida
+	// e a rota irmã /corridas compartilham o store, como nas rotas da aplicação.
+	setup := func(ttl time.Duration) (*fiber.App, *int) {
+		app := fiber.New()
+		chamadas := 0
+		store := NewIdempotenciaStore(ttl)
+		grupo := app.Group("/corrida", Idempotencia(store))
+		grupo.Post("/", func(c *fiber.Ctx) error {
+			chamadas++
+			return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": chamadas})
+		})
+		grupo.Put("/:id/aceitar", func(c *fiber.Ctx) error {
+			chamadas++
+			if chamadas > 1 {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "não está mais procurando por motorista"})
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+		grupo.Post("/falha", func(c *fiber.Ctx) error {
+			chamadas++
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "falha"})
+		})
+		app.Post("/corridas", Idempotencia(store), func(c *fiber.Ctx) error {
+			chamadas++
+			return c.SendStatus(fiber.StatusCreated)
+		})
+		return app, &chamadas
+	}
+
+	enviar := func(t *testing.T, app *fiber.App, method, path, chave, corpo string) (int, string, string) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(corpo))
+		req.Header.Set("Content-Type", "application/json")
+		if chave != "" {
+			req.Header.Set(HeaderIdempotencia, chave)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), resp.Header.Get("Idempotent-Replayed")
+	}
+
+	t.Run("Retentativa com mesma chave e corpo repete a primeira resposta", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		status1, body1, _ := enviar(t, app, "POST", "/corrida/", "chave-1", `{"passageiroID":1}`)
+		status2, body2, replay := enviar(t, app, "POST", "/corrida/", "chave-1", `{"passageiroID":1}`)
+
+		assert.Equal(t, fiber.StatusCreated, status1)
+		assert.Equal(t, status1, status2)
+		assert.Equal(t, body1, body2)
+		assert.Equal(t, "true", replay)
+		assert.Equal(t, 1, *chamadas)
+	})
+
+	t.Run("Aceite repetido não retorna erro", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		status1, _, _ := enviar(t, app, "PUT", "/corrida/1/aceitar", "aceite-1", `{"motoristaId":7}`)
+		status2, _, _ := enviar(t, app, "PUT", "/corrida/1/aceitar", "aceite-1", `{"motoristaId":7}`)
+
+		assert.Equal(t, fiber.StatusOK, status1)
+		assert.Equal(t, fiber.StatusOK, status2)
+		assert.Equal(t, 1, *chamadas)
+	})
+
+	t.Run("Mesma chave com corpo diferente é rejeitada", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		enviar(t, app, "POST", "/corrida/", "chave-2", `{"passageiroID":1}`)
+		status, _, _ := enviar(t, app, "POST", "/corrida/", "chave-2", `{"passageiroID":2}`)
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		assert.Equal(t, 1, *chamadas)
+	})
+
+	t.Run("Requisições sem chave não são deduplicadas", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		enviar(t, app, "POST", "/corrida/", "", `{"passageiroID":1}`)
+		enviar(t, app, "POST", "/corrida/", "", `{"passageiroID":1}`)
+
+		assert.Equal(t, 2, *chamadas)
+	})
+
+	t.Run("Erros do servidor não são armazenados", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		enviar(t, app, "POST", "/corrida/falha", "chave-3", `{}`)
+		enviar(t, app, "POST", "/corrida/falha", "chave-3", `{}`)
+
+		assert.Equal(t, 2, *chamadas)
+	})
+
+	t.Run("Resposta expira após o TTL", func(t *testing.T) {
+		app, chamadas := setup(20 * time.Millisecond)
+
+		enviar(t, app, "POST", "/corrida/", "chave-4", `{"passageiroID":1}`)
+		time.Sleep(30 * time.Millisecond)
+		_, _, replay := enviar(t, app, "POST", "/corrida/", "chave-4", `{"passageiroID":1}`)
+
+		assert.Equal(t, "", replay)
+		assert.Equal(t, 2, *chamadas)
+	})
+
+	t.Run("Rota irmã com prefixo semelhante não é afetada pelo grupo", func(t *testing.T) {
+		app, chamadas := setup(time.Hour)
+
+		status, _, _ := enviar(t, app, "POST", "/corrida/", "chave-5", `{}`)
+		require.Equal(t, fiber.StatusCreated, status)
+
+		// A mesma chave em outra rota é outra requisição
+		status, _, replay := enviar(t, app, "POST", "/corridas", "chave-5", `{}`)
+
+		assert.Equal(t, fiber.StatusCreated, status)
+		assert.Equal(t, "", replay)
+		assert.Equal(t, 2, *chamadas)
+	})
+}
@@ -0,0 +1,47 @@
+package models
+
+// CategoriaVeiculo é a categoria escolhida pelo passageiro e atendida pelo veículo do motorista
+type CategoriaVeiculo string
+
+const (
+	CategoriaComum     CategoriaVeiculo = "comum"
+	CategoriaExecutivo CategoriaVeiculo = "executivo"
+	CategoriaVan       CategoriaVeiculo = "van"
+	CategoriaMoto      CategoriaVeiculo = "moto"
+)
+
+// CategoriasVeiculo lista as categorias oferecidas, na ordem exibida ao passageiro
+var CategoriasVeiculo = []CategoriaVeiculo{CategoriaComum, CategoriaExecutivo, CategoriaVan, CategoriaMoto}
+
+// cnhPorCategoria lista as categorias de CNH que habilitam a conduzir cada categoria de veículo.
+// Moto exige A; carros exigem B ou superior; van (mais de 8 passageiros) exige D ou E.
+var cnhPorCategoria = map[CategoriaVeiculo][]CategoriaCNH{
+	CategoriaComum:     {CategoriaB, CategoriaC, CategoriaD, CategoriaE, CategoriaAB, CategoriaAC, CategoriaAD, CategoriaAE},
+	CategoriaExecutivo: {CategoriaB, CategoriaC, CategoriaD, CategoriaE, CategoriaAB, CategoriaAC, CategoriaAD, CategoriaAE},
+	CategoriaVan:       {CategoriaD, CategoriaE, CategoriaAD, CategoriaAE},
+	CategoriaMoto:      {CategoriaA, CategoriaAB, CategoriaAC, CategoriaAD, CategoriaAE},
+}
+
+// OuPadrao retorna a categoria comum quando nenhuma foi informada (corridas e cadastros antigos)
+func (c CategoriaVeiculo) OuPadrao() CategoriaVeiculo {
+	if c == "" {
+		return CategoriaComum
+	}
+	return c
+}
+
+// Valida indica se a categoria é uma das oferecidas
+func (c CategoriaVeiculo) Valida() bool {
+	_, existe := cnhPorCategoria[c]
+	return existe
+}
+
+// HabilitadaPor indica se a categoria de CNH permite conduzir veículos desta categoria
+func (c CategoriaVeiculo) HabilitadaPor(cnh CategoriaCNH) bool {
+	for _, permitida := range cnhPorCategoria[c.OuPadrao()] {
+		if permitida == cnh {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtendeCategoria(t *testing.T) {
+	tests := []struct {
+		name      string
+		veiculo   CategoriaVeiculo
+		cnh       CategoriaCNH
+		categoria CategoriaVeiculo
+		expected  bool
+	}{
+		{"Carro comum com CNH B", CategoriaComum, CategoriaB, CategoriaComum, true},
+		{"Cadastro antigo sem categoria atende comum", "", CategoriaB, "", true},
+		{"Executivo não atende corrida comum", CategoriaExecutivo, CategoriaB, CategoriaComum, false},
+		{"Moto com CNH A", CategoriaMoto, CategoriaA, CategoriaMoto, true},
+		{"Moto com CNH AB", CategoriaMoto, CategoriaAB, CategoriaMoto, true},
+		{"Moto sem CNH A", CategoriaMoto, CategoriaB, CategoriaMoto, false},
+		{"Carro com CNH apenas A", CategoriaComum, CategoriaA, CategoriaComum, false},
+		{"Van com CNH D", CategoriaVan, CategoriaD, CategoriaVan, true},
+		{"Van com CNH B", CategoriaVan, CategoriaB, CategoriaVan, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			motorista := Motorista{CategoriaVeiculo: tt.veiculo, CategoriaCNH: tt.cnh}
+			assert.Equal(t, tt.expected, motorista.AtendeCategoria(tt.categoria))
+		})
+	}
+
+	assert.False(t, CategoriaVeiculo("helicoptero").Valida())
+}
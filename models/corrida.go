@@ -24,29 +24,76 @@ const (
 	StatusCanceladaPorExcessoTempo = "cancelada por excesso de tempo"
 	StatusCanceladaPeloUsuario     = "cancelada pelo usuário"
 	StatusCanceladaPeloMotorista   = "cancelada pelo motorista"
+	StatusCanceladaSemMotorista    = "cancelada sem motorista disponível" // busca por motorista encerrada sem aceite
 )
 
 type Corrida struct {
 	gorm.Model
-	ID               int        `json:"id"`
-	Data             string     `json:"data"`             // dia da corrida
-	Horario          time.Time  `json:"horario"`          // horário de inicio
-	Tempo            int        `json:"tempo"`            // tempo para chegar ao destino
-	TempoEstimado    int        `json:"tempoEstimado"`    // tempo estimado em minutos
-	TempoDecorrido   int        `json:"tempoDecorrido"`   // tempo decorrido em minutos
-	Valor            int        `json:"valor"`            // valor da corrida (original)
-	Preco            float64    `json:"preco"`            // valor da corrida (float64)
-	Avaliacao        *int       `json:"avaliacao"`        // avaliacao 1, 2, 3, 4, 5 ou nil
-	Status           string     `json:"status"`           // status da corrida
-	CPFMotorista     *int       `json:"cpfMotorista"`     // chave estrangeira pro motorista responsavel (legacy)
-	MotoristaID      int        `json:"motoristaID"`      // ID do motorista
-	PassageiroID     int        `json:"passageiroID"`     // ID do passageiro
-	Origem           string     `json:"origem"`           // local de origem
-	Destino          string     `json:"destino"`          // local de destino
-	LocalDesembarque string     `json:"localDesembarque"` // local de desembarque
-	BonusAplicado    bool       `json:"bonusAplicado"`    // se bonus foi aplicado
-	DataInicio       time.Time  `json:"dataInicio"`       // data/hora de início
-	DataFim          *time.Time `json:"dataFim"`          // data/hora de fim (pode ser nil)
-	MotoristaLat     float64    `json:"motoristaLat"`     // latitude do motorista
-	MotoristaLng     float64    `json:"motoristaLng"`     // longitude do motorista
+	ID                    int              `json:"id"`
+	Data                  string           `json:"data"`                  // dia da corrida
+	Horario               time.Time        `json:"horario"`               // horário de inicio
+	Tempo                 int              `json:"tempo"`                 // tempo para chegar ao destino
+	TempoEstimado         int              `json:"tempoEstimado"`         // tempo estimado em minutos
+	TempoDecorrido        int              `json:"tempoDecorrido"`        // tempo decorrido em minutos
+	Valor                 int              `json:"valor"`                 // valor da corrida (original)
+	Preco                 float64          `json:"preco"`                 // valor da corrida (float64)
+	Avaliacao             *int             `json:"avaliacao"`             // avaliacao 1, 2, 3, 4, 5 ou nil
+	Status                string           `json:"status"`                // status da corrida
+	CPFMotorista          *int             `json:"cpfMotorista"`          // chave estrangeira pro motorista responsavel (legacy)
+	MotoristaID           MotoristaID      `json:"motoristaID"`           // ID do motorista
+	PassageiroID          int              `json:"passageiroID"`          // ID do passageiro
+	PassageiroNome        string           `json:"passageiroNome"`        // nome exibido ao motorista na oferta
+	Cidade                string           `json:"cidade"`                // define a estratégia de despacho
+	Categoria             CategoriaVeiculo `json:"categoria"`             // categoria de veículo pedida (padrão: comum)
+	ZonaTarifa            string           `json:"zonaTarifa"`            // zona de preço do embarque
+	MultiplicadorDinamico float64          `json:"multiplicadorDinamico"` // tarifa dinâmica vigente no pedido (1 = sem acréscimo)
+	Origem                string           `json:"origem"`                // local de origem
+	Destino               string           `json:"destino"`               // local de destino
+	OrigemLat             float64          `json:"origemLat"`             // latitude do ponto de embarque
+	OrigemLng             float64          `json:"origemLng"`             // longitude do ponto de embarque
+	DestinoLat            float64          `json:"destinoLat"`            // latitude do destino
+	DestinoLng            float64          `json:"destinoLng"`            // longitude do destino
+	LocalDesembarque      string           `json:"localDesembarque"`      // local de desembarque
+	BonusAplicado         bool             `json:"bonusAplicado"`         // se bonus foi aplicado
+	DataInicio            time.Time        `json:"dataInicio"`            // data/hora de início
+	DataFim               *time.Time       `json:"dataFim"`               // data/hora de fim (pode ser nil)
+	MotoristaLat          float64          `json:"motoristaLat"`          // latitude do motorista
+	MotoristaLng          float64          `json:"motoristaLng"`          // longitude do motorista
+	DeRua                 bool             `json:"deRua"`                 // iniciada pelo motorista com passageiro anônimo
+	Trajeto               []PontoTrajeto   `json:"trajeto,omitempty"`     // posições aceitas pelo taxímetro (corridas de rua)
+	DistanciaPercorridaKm float64          `json:"distanciaPercorridaKm"` // distância acumulada pelo taxímetro
+	Versao                int              `json:"versao"`                // incrementada a cada alteração (controle otimista)
+	Ofertas               []OfertaCorrida  `json:"ofertas"`               // histórico das ofertas enviadas aos motoristas
+}
+
+// OfertaCorrida registra uma oferta da corrida feita a um motorista durante a busca
+type OfertaCorrida struct {
+	NotificacaoID uint              `json:"notificacaoId"`
+	MotoristaID   MotoristaID       `json:"motoristaId"`
+	Rodada        int               `json:"rodada"`
+	DistanciaKm   float64           `json:"distanciaKm"`
+	Status        NotificacaoStatus `json:"status"`
+	OfertadaEm    time.Time         `json:"ofertadaEm"`
+	RespondidaEm  *time.Time        `json:"respondidaEm"`
+}
+
+// PontoTrajeto é uma posição do GPS do motorista registrada pelo taxímetro
+type PontoTrajeto struct {
+	Lat float64   `json:"lat"`
+	Lng float64   `json:"lng"`
+	Em  time.Time `json:"em"`
+}
+
+// ReciboCorrida é o comprovante de uma corrida de rua finalizada
+type ReciboCorrida struct {
+	CorridaID   int              `json:"corridaId"`
+	MotoristaID MotoristaID      `json:"motoristaId"`
+	Categoria   CategoriaVeiculo `json:"categoria"`
+	Inicio      time.Time        `json:"inicio"`
+	Fim         time.Time        `json:"fim"`
+	DuracaoMin  int              `json:"duracaoMin"`
+	DistanciaKm float64          `json:"distanciaKm"`
+	Bandeirada  float64          `json:"bandeirada"`
+	PorKm       float64          `json:"porKm"`
+	Valor       float64          `json:"valor"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DisponibilidadeMotorista é o estado operacional e a posição atual de um motorista
+type DisponibilidadeMotorista struct {
+	MotoristaID MotoristaID     `json:"motorista_id"`
+	Status      StatusMotorista `json:"status"` // disponivel, ocupado ou offline
+	Latitude    float64         `json:"latitude"`
+	Longitude   float64         `json:"longitude"`
+	UltimoSinal time.Time       `json:"ultimo_sinal"` // último online ou heartbeat recebido
+}
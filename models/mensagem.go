@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CanalNotificacao é o meio pelo qual uma mensagem chega ao destinatário
+type CanalNotificacao string
+
+const (
+	CanalInApp CanalNotificacao = "in_app" // caixa de entrada do aplicativo
+	CanalEmail CanalNotificacao = "email"
+	CanalSMS   CanalNotificacao = "sms"
+	CanalPush  CanalNotificacao = "push" // notificação push no celular
+)
+
+// CanaisNotificacao lista os canais reconhecidos
+var CanaisNotificacao = []CanalNotificacao{CanalInApp, CanalEmail, CanalSMS, CanalPush}
+
+// Valido indica se o canal é um dos reconhecidos
+func (c CanalNotificacao) Valido() bool {
+	for _, canal := range CanaisNotificacao {
+		if c == canal {
+			return true
+		}
+	}
+	return false
+}
+
+// TipoEventoMensagem identifica o acontecimento que gerou a mensagem e define seus canais
+type TipoEventoMensagem string
+
+const (
+	EventoMensagemNovaOferta        TipoEventoMensagem = "nova_oferta"        // motorista recebeu uma oferta de corrida
+	EventoMensagemCorridaAceita     TipoEventoMensagem = "corrida_aceita"     // passageiro: motorista a caminho
+	EventoMensagemMotoristaChegando TipoEventoMensagem = "motorista_chegando" // passageiro: motorista perto do embarque
+	EventoMensagemCorridaAtrasada   TipoEventoMensagem = "corrida_atrasada"   // passageiro: nova previsão de chegada
+	EventoMensagemCorridaCancelada  TipoEventoMensagem = "corrida_cancelada"  // passageiro ou motorista
+	EventoMensagemCorridaConcluida  TipoEventoMensagem = "corrida_concluida"
+	EventoMensagemCadastro          TipoEventoMensagem = "cadastro" // andamento do cadastro do motorista
+)
+
+// Critico indica avisos de segurança (ex.: cancelamento da corrida), sempre entregues pelos
+// canais do evento, mesmo desativados ou no horário de silêncio
+func (e TipoEventoMensagem) Critico() bool {
+	return e == EventoMensagemCorridaCancelada
+}
+
+// TipoDestinatario diferencia passageiros de motoristas
+type TipoDestinatario string
+
+const (
+	DestinatarioPassageiro TipoDestinatario = "passageiro"
+	DestinatarioMotorista  TipoDestinatario = "motorista"
+)
+
+// Destinatario é quem recebe a mensagem e os contatos usados por cada canal. Canais sem o
+// contato correspondente (ex.: SMS sem telefone) são ignorados.
+type Destinatario struct {
+	Tipo      TipoDestinatario `json:"tipo"`
+	ID        string           `json:"id"`
+	Email     string           `json:"email,omitempty"`
+	Telefone  string           `json:"telefone,omitempty"`
+	TokenPush string           `json:"token_push,omitempty"`
+}
+
+// Chave identifica o destinatário nas preferências e na caixa de entrada
+func (d Destinatario) Chave() string {
+	return string(d.Tipo) + ":" + d.ID
+}
+
+// Mensagem é um aviso a um passageiro ou motorista, entregue pelos canais do seu evento
+type Mensagem struct {
+	ID           string             `json:"id"`
+	Evento       TipoEventoMensagem `json:"evento"`
+	Destinatario Destinatario       `json:"destinatario"`
+	Titulo       string             `json:"titulo"`
+	Texto        string             `json:"texto"`
+	Dados        map[string]string  `json:"dados,omitempty"` // ex.: corrida_id, para o aplicativo abrir a tela certa
+	CriadaEm     time.Time          `json:"criada_em"`
+}
+
+// PreferenciasNotificacao são as escolhas de um destinatário sobre os canais. Os canais de
+// PorEvento substituem os padrões do evento; canais desativados nunca são usados. No horário
+// de silêncio, só a caixa de entrada recebe mensagens. Avisos críticos ignoram as escolhas.
+type PreferenciasNotificacao struct {
+	Desativados         []CanalNotificacao                        `json:"desativados,omitempty"`
+	PorEvento           map[TipoEventoMensagem][]CanalNotificacao `json:"por_evento,omitempty"`
+	Silencio            *HorarioSilencio                          `json:"silencio,omitempty"`
+	MaxOfertasPorMinuto int                                       `json:"max_ofertas_por_minuto,omitempty"` // motoristas; 0: sem limite
+}
+
+// HorarioSilencio é o intervalo diário [Inicio, Fim), em "HH:MM" no horário local, em que o
+// destinatário não quer ser incomodado. Intervalos com Inicio depois de Fim atravessam a
+// meia-noite (ex.: 22:00 às 06:00).
+type HorarioSilencio struct {
+	Inicio string `json:"inicio"`
+	Fim    string `json:"fim"`
+}
+
+// Validar confere o formato dos horários
+func (h HorarioSilencio) Validar() error {
+	inicio, errInicio := minutoDoDia(h.Inicio)
+	fim, errFim := minutoDoDia(h.Fim)
+	if errInicio != nil || errFim != nil {
+		return fmt.Errorf("horário de silêncio inválido: use HH:MM (ex.: 22:00 e 06:00)")
+	}
+	if inicio == fim {
+		return fmt.Errorf("horário de silêncio inválido: início e fim iguais")
+	}
+	return nil
+}
+
+// Contem indica se o instante está dentro do horário de silêncio
+func (h HorarioSilencio) Contem(em time.Time) bool {
+	inicio, errInicio := minutoDoDia(h.Inicio)
+	fim, errFim := minutoDoDia(h.Fim)
+	if errInicio != nil || errFim != nil {
+		return false
+	}
+	agora := em.Hour()*60 + em.Minute()
+	if inicio <= fim {
+		return agora >= inicio && agora < fim
+	}
+	return agora >= inicio || agora < fim
+}
+
+// minutoDoDia converte "HH:MM" em minutos desde a meia-noite
+func minutoDoDia(horario string) (int, error) {
+	instante, err := time.Parse("15:04", horario)
+	if err != nil {
+		return 0, err
+	}
+	return instante.Hour()*60 + instante.Minute(), nil
+}
+
+// Validar confere se todos os canais das preferências são reconhecidos
+func (p PreferenciasNotificacao) Validar() error {
+	for _, canal := range p.Desativados {
+		if !canal.Valido() {
+			return fmt.Errorf("canal de notificação inválido: %q. Use in_app, email, sms ou push", canal)
+		}
+	}
+	for _, canais := range p.PorEvento {
+		for _, canal := range canais {
+			if !canal.Valido() {
+				return fmt.Errorf("canal de notificação inválido: %q. Use in_app, email, sms ou push", canal)
+			}
+		}
+	}
+	if p.Silencio != nil {
+		if err := p.Silencio.Validar(); err != nil {
+			return err
+		}
+	}
+	if p.MaxOfertasPorMinuto < 0 {
+		return fmt.Errorf("máximo de ofertas por minuto não pode ser negativo")
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+    "bytes"
+    "encoding/json"
     "errors"
     "regexp"
     "strings"
@@ -8,6 +10,45 @@ import (
     "gorm.io/gorm"
 )
 
+// MotoristaID identifica um motorista no cadastro, nas corridas e nas notificações
+type MotoristaID string
+
+// UnmarshalJSON aceita também identificadores numéricos gravados antes da unificação;
+// o antigo 0 (motorista não definido) vira o identificador vazio
+func (id *MotoristaID) UnmarshalJSON(data []byte) error {
+    data = bytes.TrimSpace(data)
+    if bytes.Equal(data, []byte("null")) {
+        *id = ""
+        return nil
+    }
+    if len(data) > 0 && data[0] != '"' {
+        var numero json.Number
+        if err := json.Unmarshal(data, &numero); err != nil {
+            return errors.New("identificador de motorista inválido")
+        }
+        *id = MotoristaID(numero.String())
+        if valor, err := numero.Int64(); err == nil && valor == 0 {
+            *id = ""
+        }
+        return nil
+    }
+
+    var valor string
+    if err := json.Unmarshal(data, &valor); err != nil {
+        return err
+    }
+    *id = MotoristaID(valor)
+    return nil
+}
+
+// formatoMotoristaID aceita UUIDs do cadastro e identificadores numéricos legados
+var formatoMotoristaID = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
+// Valido indica se o identificador está em um formato reconhecido
+func (id MotoristaID) Valido() bool {
+    return formatoMotoristaID.MatchString(string(id))
+}
+
 // StatusMotorista representa os possíveis status de um motorista
 type StatusMotorista string
 
@@ -18,6 +59,7 @@ const (
     StatusRejeitado           StatusMotorista = "documentos_rejeitados"
     StatusAtivo               StatusMotorista = "ativo"
     StatusEncerrado           StatusMotorista = "encerrado"
+    StatusSuspenso            StatusMotorista = "suspenso"
     
     // Status operacionais (para notificações)
     StatusDisponivel          StatusMotorista = "disponivel"
@@ -43,7 +85,7 @@ const (
 // Motorista representa um motorista no sistema
 type Motorista struct {
     gorm.Model
-    ID             string            `json:"id"`
+    ID             MotoristaID     `json:"id"`
     Nome           string          `json:"nome" validate:"required,min=2,max=100"`
     DataNascimento time.Time       `json:"data_nascimento" validate:"required"`
     CPF            string          `json:"cpf" validate:"required"`
@@ -54,20 +96,24 @@ type Motorista struct {
     CRLV           *string         `json:"crlv"`
     PlacaVeiculo   string          `json:"placa_veiculo" validate:"required"`
     ModeloVeiculo  string          `json:"modelo_veiculo" validate:"required,min=3,max=100"`
+    CategoriaVeiculo CategoriaVeiculo `json:"categoria_veiculo"` // vazia nos cadastros antigos: comum
     Telefone       string          `json:"telefone" validate:"required"`
     Email          string          `json:"email" validate:"required,email"`
     Senha          string          `json:"senha" validate:"required,min=8"`
     Status         StatusMotorista `json:"status"`
+    StatusOperacional StatusMotorista `json:"status_operacional"` // disponivel, ocupado ou offline
     CriadoEm       time.Time       `json:"criado_em"`
     AtualizadoEm   time.Time       `json:"atualizado_em"`
     Documentos     []Documento     `json:"documentos"`
+    PreferenciasNotificacao *PreferenciasNotificacao `json:"preferencias_notificacao,omitempty" gorm:"serializer:json"` // canais, silêncio e limite de ofertas
+    Versao         int             `json:"versao"` // incrementada a cada atualização (controle otimista)
 }
 
 // Documento representa um documento enviado pelo motorista
 type Documento struct {
     gorm.Model
     ID             string      `json:"id"`
-    MotoristaID    uint      `json:"motorista_id"`
+    MotoristaID    MotoristaID `json:"motorista_id"`
     TipoDocumento  string    `json:"tipo_documento"` // CNH, CRLV, selfie_cnh
     CaminhoArquivo string    `json:"caminho_arquivo"`
     Formato        string    `json:"formato"`
@@ -97,6 +143,12 @@ func (d *Documento) BeforeCreate(tx *gorm.DB) error {
     return nil
 }
 
+// AtendeCategoria indica se o veículo e a CNH do motorista servem para corridas da categoria
+func (m *Motorista) AtendeCategoria(categoria CategoriaVeiculo) bool {
+    categoria = categoria.OuPadrao()
+    return m.CategoriaVeiculo.OuPadrao() == categoria && categoria.HabilitadaPor(m.CategoriaCNH)
+}
+
 // CalcularIdade calcula a idade baseada na data de nascimento
 func (m *Motorista) CalcularIdade() int {
     agora := time.Now()
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -360,3 +361,24 @@ func TestValidarDocumento(t *testing.T) {
 		})
 	}
 }
+
+func TestMotoristaIDUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected MotoristaID
+	}{
+		{"Texto", `"abc"`, "abc"},
+		{"Número legado", `101`, "101"},
+		{"Zero legado é motorista não definido", `0`, ""},
+		{"Nulo", `null`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id MotoristaID
+			assert.NoError(t, json.Unmarshal([]byte(tt.json), &id))
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+}
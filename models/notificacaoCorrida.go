@@ -11,11 +11,57 @@ const (
     NotificacaoAceita    NotificacaoStatus = "aceita"
     NotificacaoRecusada  NotificacaoStatus = "recusada"
     NotificacaoExpirada  NotificacaoStatus = "expirada"
+    // Oferta retirada porque outro motorista aceitou a mesma corrida ou pelo suporte
+    NotificacaoCancelada NotificacaoStatus = "cancelada"
 )
 
+// transicoesNotificacao lista para quais status uma oferta pode ir. Só a oferta pendente
+// muda de status; aceita, recusada, expirada e cancelada são finais.
+var transicoesNotificacao = map[NotificacaoStatus][]NotificacaoStatus{
+    NotificacaoPendente:  {NotificacaoAceita, NotificacaoRecusada, NotificacaoExpirada, NotificacaoCancelada},
+    NotificacaoAceita:    {},
+    NotificacaoRecusada:  {},
+    NotificacaoExpirada:  {},
+    NotificacaoCancelada: {},
+}
+
+// Valido indica se o status é um dos conhecidos
+func (s NotificacaoStatus) Valido() bool {
+    _, existe := transicoesNotificacao[s]
+    return existe
+}
+
+// Final indica se a oferta não muda mais de status
+func (s NotificacaoStatus) Final() bool {
+    return s.Valido() && len(transicoesNotificacao[s]) == 0
+}
+
+// PodeIrPara indica se a oferta pode passar deste status para o novo
+func (s NotificacaoStatus) PodeIrPara(novo NotificacaoStatus) bool {
+    for _, permitido := range transicoesNotificacao[s] {
+        if permitido == novo {
+            return true
+        }
+    }
+    return false
+}
+
+// AutorSistema identifica as mudanças de status feitas pelo próprio serviço (expiração,
+// cancelamento das demais ofertas de uma corrida aceita)
+const AutorSistema = "sistema"
+
+// MudancaStatusNotificacao registra quem mudou o status de uma oferta, quando e por quê
+type MudancaStatusNotificacao struct {
+    De     NotificacaoStatus `json:"de"`
+    Para   NotificacaoStatus `json:"para"`
+    Por    string            `json:"por"`
+    Motivo string            `json:"motivo"`
+    Em     time.Time         `json:"em"`
+}
+
 type NotificacaoCorrida struct {
     ID              uint              `json:"id"`
-    MotoristaID     uint              `json:"motorista_id"`
+    MotoristaID     MotoristaID       `json:"motorista_id"`
     CorridaID       uint              `json:"corrida_id"`
     PassageiroNome  string            `json:"passageiro_nome"`
     Valor           float64           `json:"valor"`
@@ -27,4 +73,11 @@ type NotificacaoCorrida struct {
     CreatedAt       time.Time         `json:"created_at"`
     UpdatedAt       time.Time         `json:"updated_at"`
     ExpiraEm        time.Time         `json:"expira_em"`
+    Cidade          string            `json:"cidade,omitempty"`
+    Categoria       CategoriaVeiculo  `json:"categoria,omitempty"`
+    // Tempo para responder, em segundos. Na criação, um valor informado substitui o da
+    // configuração (ferramentas administrativas); na resposta, é o tempo efetivo da oferta.
+    TTLSegundos     int               `json:"ttl_segundos"`
+    // Mudanças de status, da mais antiga para a mais recente
+    Historico       []MudancaStatusNotificacao `json:"historico,omitempty"`
 }
\ No newline at end of file
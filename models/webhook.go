@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TipoEventoWebhook é o evento entregue aos parceiros inscritos
+type TipoEventoWebhook string
+
+const (
+	EventoWebhookCorridaCriada     TipoEventoWebhook = "corrida.criada"
+	EventoWebhookCorridaAceita     TipoEventoWebhook = "corrida.aceita"
+	EventoWebhookMotoristaChegando TipoEventoWebhook = "corrida.motorista_chegando"
+	EventoWebhookCorridaAtrasada   TipoEventoWebhook = "corrida.atrasada"
+	EventoWebhookCorridaEncerrada  TipoEventoWebhook = "corrida.encerrada" // finalizada ou cancelada
+	EventoWebhookMotoristaStatus   TipoEventoWebhook = "motorista.status"  // disponível, ocupado ou offline
+	EventoWebhookTodos             TipoEventoWebhook = "*"                 // inscreve em todos os eventos
+)
+
+// EventosWebhook lista os eventos que podem ser assinados
+var EventosWebhook = []TipoEventoWebhook{
+	EventoWebhookCorridaCriada,
+	EventoWebhookCorridaAceita,
+	EventoWebhookMotoristaChegando,
+	EventoWebhookCorridaAtrasada,
+	EventoWebhookCorridaEncerrada,
+	EventoWebhookMotoristaStatus,
+}
+
+// Valido indica se o evento é um dos reconhecidos (ou "*")
+func (e TipoEventoWebhook) Valido() bool {
+	if e == EventoWebhookTodos {
+		return true
+	}
+	for _, evento := range EventosWebhook {
+		if e == evento {
+			return true
+		}
+	}
+	return false
+}
+
+// AssinaturaWebhook é a inscrição de um parceiro (ex.: balcão de hotel, BI) para receber
+// eventos por HTTP. As entregas são assinadas com HMAC-SHA256 usando o Segredo.
+type AssinaturaWebhook struct {
+	ID       string              `json:"id"`
+	URL      string              `json:"url"`
+	Eventos  []TipoEventoWebhook `json:"eventos"`
+	Segredo  string              `json:"segredo,omitempty"`
+	Ativa    bool                `json:"ativa"`
+	CriadaEm time.Time           `json:"criada_em"`
+}
+
+// Validar confere a URL de entrega e os eventos assinados
+func (a AssinaturaWebhook) Validar() error {
+	endereco, err := url.Parse(a.URL)
+	if err != nil || (endereco.Scheme != "http" && endereco.Scheme != "https") || endereco.Host == "" {
+		return fmt.Errorf("url de entrega inválida: %q", a.URL)
+	}
+	if len(a.Eventos) == 0 {
+		return errors.New("informe ao menos um evento")
+	}
+	for _, evento := range a.Eventos {
+		if !evento.Valido() {
+			return fmt.Errorf("evento de webhook inválido: %q", evento)
+		}
+	}
+	return nil
+}
+
+// Recebe indica se a assinatura está ativa e inscrita no evento
+func (a AssinaturaWebhook) Recebe(evento TipoEventoWebhook) bool {
+	if !a.Ativa {
+		return false
+	}
+	for _, inscrito := range a.Eventos {
+		if inscrito == evento || inscrito == EventoWebhookTodos {
+			return true
+		}
+	}
+	return false
+}
+
+// EntregaWebhook é o envio de um evento a uma assinatura. Entregas que esgotam as
+// tentativas ficam guardadas como falhas até serem reenviadas manualmente.
+type EntregaWebhook struct {
+	ID                string            `json:"id"`
+	AssinaturaID      string            `json:"assinatura_id"`
+	URL               string            `json:"url"`
+	Evento            TipoEventoWebhook `json:"evento"`
+	Corpo             json.RawMessage   `json:"corpo"`
+	Tentativas        int               `json:"tentativas"`
+	UltimoErro        string            `json:"ultimo_erro,omitempty"`
+	CriadaEm          time.Time         `json:"criada_em"`
+	UltimaTentativaEm *time.Time        `json:"ultima_tentativa_em,omitempty"`
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TipoZona define como uma zona é usada
+type TipoZona string
+
+const (
+	ZonaAreaAtendimento TipoZona = "area_atendimento" // embarques só são aceitos dentro destas áreas
+	ZonaRegiaoPreco     TipoZona = "regiao_preco"     // agrupa a demanda e a oferta da tarifa dinâmica
+	ZonaAreaRestrita    TipoZona = "area_restrita"    // embarques não são aceitos
+	ZonaAeroporto       TipoZona = "aeroporto"        // fila de táxis por ordem de chegada
+	ZonaPontoTaxi       TipoZona = "ponto_taxi"       // fila de táxis por ordem de chegada
+)
+
+// Valido indica se o tipo é um dos reconhecidos
+func (t TipoZona) Valido() bool {
+	switch t {
+	case ZonaAreaAtendimento, ZonaRegiaoPreco, ZonaAreaRestrita, ZonaAeroporto, ZonaPontoTaxi:
+		return true
+	}
+	return false
+}
+
+// ComFila indica se os motoristas dentro da zona formam uma fila por ordem de chegada
+func (t TipoZona) ComFila() bool {
+	return t == ZonaAeroporto || t == ZonaPontoTaxi
+}
+
+// Coordenada é um ponto no formato do GeoJSON: [longitude, latitude]
+type Coordenada [2]float64
+
+// Poligono é o anel externo seguido dos buracos; cada anel é fechado (primeiro ponto = último)
+type Poligono [][]Coordenada
+
+// Geometria é o contorno de uma zona. É lida de um Polygon ou MultiPolygon do GeoJSON e
+// sempre gravada como MultiPolygon.
+type Geometria struct {
+	Poligonos []Poligono
+}
+
+type geometriaGeoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// MarshalJSON grava a geometria como um MultiPolygon do GeoJSON
+func (g Geometria) MarshalJSON() ([]byte, error) {
+	poligonos := g.Poligonos
+	if poligonos == nil {
+		poligonos = []Poligono{}
+	}
+	coordenadas, err := json.Marshal(poligonos)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(geometriaGeoJSON{Type: "MultiPolygon", Coordinates: coordenadas})
+}
+
+// UnmarshalJSON aceita geometrias GeoJSON do tipo Polygon ou MultiPolygon
+func (g *Geometria) UnmarshalJSON(data []byte) error {
+	var geometria geometriaGeoJSON
+	if err := json.Unmarshal(data, &geometria); err != nil {
+		return err
+	}
+
+	switch geometria.Type {
+	case "Polygon":
+		var poligono Poligono
+		if err := json.Unmarshal(geometria.Coordinates, &poligono); err != nil {
+			return fmt.Errorf("coordenadas do Polygon inválidas: %w", err)
+		}
+		g.Poligonos = []Poligono{poligono}
+	case "MultiPolygon":
+		var poligonos []Poligono
+		if err := json.Unmarshal(geometria.Coordinates, &poligonos); err != nil {
+			return fmt.Errorf("coordenadas do MultiPolygon inválidas: %w", err)
+		}
+		g.Poligonos = poligonos
+	default:
+		return fmt.Errorf("geometria %q não suportada; use Polygon ou MultiPolygon", geometria.Type)
+	}
+	return nil
+}
+
+// Validar confere se cada anel tem ao menos três vértices, é fechado e tem coordenadas válidas
+func (g Geometria) Validar() error {
+	if len(g.Poligonos) == 0 {
+		return errors.New("geometria deve ter ao menos um polígono")
+	}
+	for _, poligono := range g.Poligonos {
+		if len(poligono) == 0 {
+			return errors.New("polígono sem anel externo")
+		}
+		for _, anel := range poligono {
+			if len(anel) < 4 {
+				return errors.New("cada anel deve ter ao menos 4 posições (3 vértices e o fechamento)")
+			}
+			if anel[0] != anel[len(anel)-1] {
+				return errors.New("cada anel deve terminar na mesma posição em que começa")
+			}
+			for _, ponto := range anel {
+				if ponto[0] < -180 || ponto[0] > 180 || ponto[1] < -90 || ponto[1] > 90 {
+					return fmt.Errorf("coordenada fora dos limites: [%g, %g]", ponto[0], ponto[1])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Zona é uma área geográfica usada como área de atendimento, região de preço, área restrita
+// ou ponto de táxi (aeroporto ou ponto comum)
+type Zona struct {
+	ID           string    `json:"id"`
+	Nome         string    `json:"nome"`
+	Tipo         TipoZona  `json:"tipo"`
+	Geometria    Geometria `json:"geometria"`
+	Ativa        bool      `json:"ativa"`
+	CriadoEm     time.Time `json:"criado_em"`
+	AtualizadoEm time.Time `json:"atualizado_em"`
+	Versao       int       `json:"versao"` // incrementada a cada atualização (controle otimista)
+}
+
+// Validar confere os campos obrigatórios e a geometria da zona
+func (z Zona) Validar() error {
+	if z.Nome == "" {
+		return errors.New("nome da zona é obrigatório")
+	}
+	if !z.Tipo.Valido() {
+		return fmt.Errorf("tipo de zona inválido: %q. Use area_atendimento, regiao_preco, area_restrita, aeroporto ou ponto_taxi", z.Tipo)
+	}
+	return z.Geometria.Validar()
+}
+
+// PosicaoFila é a situação de um motorista na fila de um ponto de táxi ou aeroporto
+type PosicaoFila struct {
+	MotoristaID MotoristaID `json:"motorista_id"`
+	ZonaID      string      `json:"zona_id"`
+	ZonaNome    string      `json:"zona_nome"`
+	Posicao     int         `json:"posicao"` // 1 é o próximo a receber corrida
+	Tamanho     int         `json:"tamanho"`
+	EntrouEm    time.Time   `json:"entrou_em"`
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// campoMotoristaIDNumerico encontra referências numéricas a motoristas ("motorista_id": 101)
+var campoMotoristaIDNumerico = regexp.MustCompile(`(?i)("motorista_?id"\s*:\s*)(-?\d+)\b`)
+
+// MigrarIdentificadoresMotorista converte para texto os identificadores numéricos de
+// motorista gravados antes da unificação do tipo models.MotoristaID. O antigo 0 (motorista
+// não definido) vira "", e não "0", que passaria por um identificador válido.
+// Arquivos inexistentes são ignorados e arquivos já migrados não são reescritos.
+func MigrarIdentificadoresMotorista(arquivos ...string) error {
+	for _, arquivo := range arquivos {
+		if err := migrarArquivoMotoristaID(arquivo); err != nil {
+			return fmt.Errorf("erro ao migrar %s: %w", arquivo, err)
+		}
+	}
+	return nil
+}
+
+// migrarArquivoMotoristaID migra um único arquivo JSON preservando sua formatação
+func migrarArquivoMotoristaID(arquivo string) error {
+	data, err := os.ReadFile(arquivo)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !campoMotoristaIDNumerico.Match(data) {
+		return nil
+	}
+
+	migrado := campoMotoristaIDNumerico.ReplaceAllFunc(data, func(campo []byte) []byte {
+		partes := campoMotoristaIDNumerico.FindSubmatch(campo)
+		valor := partes[2]
+		if numero, err := strconv.Atoi(string(valor)); err == nil && numero == 0 {
+			valor = nil
+		}
+		return []byte(string(partes[1]) + `"` + string(valor) + `"`)
+	})
+	return os.WriteFile(arquivo, migrado, 0644)
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func TestMigrarIdentificadoresMotorista(t *testing.T) {
+	dir := t.TempDir()
+
+	corridas := filepath.Join(dir, "corridas.json")
+	notificacoes := filepath.Join(dir, "notificacao_corrida.json")
+	motoristas := filepath.Join(dir, "motoristas.json")
+
+	require.NoError(t, os.WriteFile(corridas, []byte(`[{"ID": 1, "MotoristaID": 101, "PassageiroID": 201}]`), 0644))
+	require.NoError(t, os.WriteFile(notificacoes, []byte(`[{"id": 1, "motorista_id": 102, "corrida_id": 1001}]`), 0644))
+	require.NoError(t, os.WriteFile(motoristas, []byte(`[{"id": "abc", "documentos": [{"motorista_id": 0}]}]`), 0644))
+
+	err := MigrarIdentificadoresMotorista(corridas, notificacoes, motoristas, filepath.Join(dir, "inexistente.json"))
+	require.NoError(t, err)
+
+	t.Run("Corridas passam a referenciar o motorista por texto", func(t *testing.T) {
+		data, _ := os.ReadFile(corridas)
+		assert.JSONEq(t, `[{"ID": 1, "MotoristaID": "101", "PassageiroID": 201}]`, string(data))
+
+		var lidas []models.Corrida
+		require.NoError(t, json.Unmarshal(data, &lidas))
+		assert.Equal(t, models.MotoristaID("101"), lidas[0].MotoristaID)
+	})
+
+	t.Run("Notificações e documentos são migrados", func(t *testing.T) {
+		data, _ := os.ReadFile(notificacoes)
+		assert.JSONEq(t, `[{"id": 1, "motorista_id": "102", "corrida_id": 1001}]`, string(data))
+
+		// 0 era o motorista não definido: não pode virar a referência válida "0"
+		data, _ = os.ReadFile(motoristas)
+		assert.JSONEq(t, `[{"id": "abc", "documentos": [{"motorista_id": ""}]}]`, string(data))
+	})
+
+	t.Run("Migração é idempotente", func(t *testing.T) {
+		antes, _ := os.ReadFile(corridas)
+		require.NoError(t, MigrarIdentificadoresMotorista(corridas))
+		depois, _ := os.ReadFile(corridas)
+		assert.Equal(t, antes, depois)
+	})
+}
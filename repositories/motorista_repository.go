@@ -10,15 +10,19 @@ import (
 	"taxi-service/models"
 )
 
-// MotoristaRepository define a interface para operações com motoristas
+// ErrVersaoConflito indica que o registro foi alterado por outra operação desde a leitura
+var ErrVersaoConflito = errors.New("versão do registro não confere")
+
+// MotoristaRepository define a interface para operações com motoristas.
+// Atualizar só grava se a Versao do motorista for igual à armazenada e então a incrementa.
 type MotoristaRepository interface {
 	Criar(motorista *models.Motorista) error
-	BuscarPorID(id string) (*models.Motorista, error)
+	BuscarPorID(id models.MotoristaID) (*models.Motorista, error)
 	BuscarPorEmail(email string) (*models.Motorista, error)
 	BuscarPorCPF(cpf string) (*models.Motorista, error)
 	BuscarPorCNH(cnh string) (*models.Motorista, error)
 	Atualizar(motorista *models.Motorista) error
-	Deletar(id string) error
+	Deletar(id models.MotoristaID) error
 	ListarTodos() ([]*models.Motorista, error)
 }
 
@@ -35,11 +39,8 @@ func NewJSONMotoristaRepository() *JSONMotoristaRepository {
 	}
 }
 
-// lerMotoristas lê todos os motoristas do arquivo JSON
+// lerMotoristas lê todos os motoristas do arquivo JSON (deve ser chamada com o mutex travado)
 func (r *JSONMotoristaRepository) lerMotoristas() ([]*models.Motorista, error) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
 	// Criar diretório se não existir
 	if err := os.MkdirAll("./data", 0755); err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório: %w", err)
@@ -64,14 +65,18 @@ func (r *JSONMotoristaRepository) lerMotoristas() ([]*models.Motorista, error) {
 		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
 	}
 
+	// Registros anteriores ao controle de versão começam na versão 1
+	for _, motorista := range motoristas {
+		if motorista.Versao == 0 {
+			motorista.Versao = 1
+		}
+	}
+
 	return motoristas, nil
 }
 
-// salvarMotoristas salva todos os motoristas no arquivo JSON
+// salvarMotoristas salva todos os motoristas no arquivo JSON (deve ser chamada com o mutex travado)
 func (r *JSONMotoristaRepository) salvarMotoristas(motoristas []*models.Motorista) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	data, err := json.MarshalIndent(motoristas, "", "  ")
 	if err != nil {
 		return fmt.Errorf("erro ao serializar dados: %w", err)
@@ -86,6 +91,9 @@ func (r *JSONMotoristaRepository) salvarMotoristas(motoristas []*models.Motorist
 
 // Criar adiciona um novo motorista
 func (r *JSONMotoristaRepository) Criar(motorista *models.Motorista) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return err
@@ -98,12 +106,19 @@ func (r *JSONMotoristaRepository) Criar(motorista *models.Motorista) error {
 		}
 	}
 
+	if motorista.Versao == 0 {
+		motorista.Versao = 1
+	}
+
 	motoristas = append(motoristas, motorista)
 	return r.salvarMotoristas(motoristas)
 }
 
 // BuscarPorID busca um motorista por ID
-func (r *JSONMotoristaRepository) BuscarPorID(id string) (*models.Motorista, error) {
+func (r *JSONMotoristaRepository) BuscarPorID(id models.MotoristaID) (*models.Motorista, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return nil, err
@@ -120,6 +135,9 @@ func (r *JSONMotoristaRepository) BuscarPorID(id string) (*models.Motorista, err
 
 // BuscarPorEmail busca um motorista por email
 func (r *JSONMotoristaRepository) BuscarPorEmail(email string) (*models.Motorista, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return nil, err
@@ -136,6 +154,9 @@ func (r *JSONMotoristaRepository) BuscarPorEmail(email string) (*models.Motorist
 
 // BuscarPorCPF busca um motorista por CPF
 func (r *JSONMotoristaRepository) BuscarPorCPF(cpf string) (*models.Motorista, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return nil, err
@@ -152,6 +173,9 @@ func (r *JSONMotoristaRepository) BuscarPorCPF(cpf string) (*models.Motorista, e
 
 // BuscarPorCNH busca um motorista por CNH
 func (r *JSONMotoristaRepository) BuscarPorCNH(cnh string) (*models.Motorista, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return nil, err
@@ -166,8 +190,11 @@ func (r *JSONMotoristaRepository) BuscarPorCNH(cnh string) (*models.Motorista, e
 	return nil, errors.New("motorista não encontrado")
 }
 
-// Atualizar atualiza um motorista existente
+// Atualizar atualiza um motorista existente se a versão lida ainda for a atual
 func (r *JSONMotoristaRepository) Atualizar(motorista *models.Motorista) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return err
@@ -175,8 +202,17 @@ func (r *JSONMotoristaRepository) Atualizar(motorista *models.Motorista) error {
 
 	for i, m := range motoristas {
 		if m.ID == motorista.ID {
-			motoristas[i] = motorista
-			return r.salvarMotoristas(motoristas)
+			if m.Versao != motorista.Versao {
+				return ErrVersaoConflito
+			}
+			atualizado := *motorista
+			atualizado.Versao = m.Versao + 1
+			motoristas[i] = &atualizado
+			if err := r.salvarMotoristas(motoristas); err != nil {
+				return err
+			}
+			motorista.Versao = atualizado.Versao
+			return nil
 		}
 	}
 
@@ -184,7 +220,10 @@ func (r *JSONMotoristaRepository) Atualizar(motorista *models.Motorista) error {
 }
 
 // Deletar remove um motorista
-func (r *JSONMotoristaRepository) Deletar(id string) error {
+func (r *JSONMotoristaRepository) Deletar(id models.MotoristaID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return err
@@ -202,5 +241,8 @@ func (r *JSONMotoristaRepository) Deletar(id string) error {
 
 // ListarTodos retorna todos os motoristas
 func (r *JSONMotoristaRepository) ListarTodos() ([]*models.Motorista, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	return r.lerMotoristas()
 }
@@ -49,7 +49,7 @@ func TestJSONMotoristaRepository(t *testing.T) {
 	t.Run("Buscar motorista por ID", func(t *testing.T) {
 		motorista, err := repo.BuscarPorID("1")
 		require.NoError(t, err)
-		assert.Equal(t, "1", motorista.ID)
+		assert.Equal(t, models.MotoristaID("1"), motorista.ID)
 		assert.Equal(t, "João Silva", motorista.Nome)
 		assert.Equal(t, "joao.silva@email.com", motorista.Email)
 	})
@@ -57,21 +57,21 @@ func TestJSONMotoristaRepository(t *testing.T) {
 	t.Run("Buscar motorista por email", func(t *testing.T) {
 		motorista, err := repo.BuscarPorEmail("joao.silva@email.com")
 		require.NoError(t, err)
-		assert.Equal(t, "1", motorista.ID)
+		assert.Equal(t, models.MotoristaID("1"), motorista.ID)
 		assert.Equal(t, "João Silva", motorista.Nome)
 	})
 
 	t.Run("Buscar motorista por CPF", func(t *testing.T) {
 		motorista, err := repo.BuscarPorCPF("12345678909")
 		require.NoError(t, err)
-		assert.Equal(t, "1", motorista.ID)
+		assert.Equal(t, models.MotoristaID("1"), motorista.ID)
 		assert.Equal(t, "João Silva", motorista.Nome)
 	})
 
 	t.Run("Buscar motorista por CNH", func(t *testing.T) {
 		motorista, err := repo.BuscarPorCNH("12345678901")
 		require.NoError(t, err)
-		assert.Equal(t, "1", motorista.ID)
+		assert.Equal(t, models.MotoristaID("1"), motorista.ID)
 		assert.Equal(t, "João Silva", motorista.Nome)
 	})
 
@@ -91,6 +91,39 @@ func TestJSONMotoristaRepository(t *testing.T) {
 		assert.Equal(t, models.StatusAprovado, motoristaAtualizado.Status)
 	})
 
+	t.Run("Atualizar incrementa a versão", func(t *testing.T) {
+		motorista, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		versaoAnterior := motorista.Versao
+
+		err = repo.Atualizar(motorista)
+		require.NoError(t, err)
+		assert.Equal(t, versaoAnterior+1, motorista.Versao)
+
+		motoristaAtualizado, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, versaoAnterior+1, motoristaAtualizado.Versao)
+	})
+
+	t.Run("Atualização concorrente com versão antiga é rejeitada", func(t *testing.T) {
+		leituraAdmin, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		leituraMotorista, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+
+		leituraAdmin.Status = models.StatusAprovado
+		require.NoError(t, repo.Atualizar(leituraAdmin))
+
+		leituraMotorista.Documentos = append(leituraMotorista.Documentos, models.Documento{TipoDocumento: "CNH"})
+		err = repo.Atualizar(leituraMotorista)
+		assert.ErrorIs(t, err, ErrVersaoConflito)
+
+		atual, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusAprovado, atual.Status)
+		assert.Empty(t, atual.Documentos)
+	})
+
 	t.Run("Listar todos os motoristas", func(t *testing.T) {
 		motoristas, err := repo.ListarTodos()
 		require.NoError(t, err)
@@ -0,0 +1,451 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Erros do repositório de notificações. As mensagens são as já devolvidas pela API.
+var (
+	ErrNotificacaoNaoEncontrada = errors.New("notificacao not found")
+	// ErrStatusNotificacaoAlterado indica que o status mudou desde a leitura (ex.: a oferta
+	// foi recusada ou expirou enquanto o aceite era processado)
+	ErrStatusNotificacaoAlterado = errors.New("notificacao already processed")
+)
+
+// NotificacaoRepository define a interface para operações com as notificações (ofertas) de
+// corrida. As implementações devem ser seguras para uso concorrente: Criar atribui IDs únicos
+// e TrocarStatus é uma operação atômica de comparar e trocar.
+type NotificacaoRepository interface {
+	Criar(notificacao *models.NotificacaoCorrida) error
+	BuscarPorID(id uint) (models.NotificacaoCorrida, error)
+	ListarTodas() ([]models.NotificacaoCorrida, error)
+	ListarPorCorrida(corridaID uint) ([]models.NotificacaoCorrida, error)
+	ListarPorMotorista(motoristaID models.MotoristaID) ([]models.NotificacaoCorrida, error)
+	// Listar retorna as notificações que atendem ao filtro, em ordem crescente de ID
+	Listar(filtro FiltroNotificacoes) ([]models.NotificacaoCorrida, error)
+	// TrocarStatus grava mudanca.Para só se o status atual for um dos esperados, anexando a
+	// mudança ao histórico da notificação com o status de origem preenchido; caso contrário
+	// retorna ErrStatusNotificacaoAlterado junto com a notificação como está
+	TrocarStatus(id uint, esperados []models.NotificacaoStatus, mudanca models.MudancaStatusNotificacao) (models.NotificacaoCorrida, error)
+	Deletar(id uint) error
+	// DeletarLote remove as notificações informadas de uma vez, ignorando IDs inexistentes,
+	// e retorna quantas foram removidas
+	DeletarLote(ids []uint) (int, error)
+}
+
+// FiltroNotificacoes seleciona notificações por status, motorista, corrida e data de criação.
+// Campos vazios não filtram. AposID e Limite paginam o resultado, ordenado por ID.
+type FiltroNotificacoes struct {
+	Status      []models.NotificacaoStatus
+	MotoristaID models.MotoristaID
+	CorridaID   uint
+	CriadaDe    time.Time // inclusivo
+	CriadaAte   time.Time // exclusivo
+	AposID      uint      // só notificações com ID maior (cursor)
+	Limite      int       // 0 retorna todas
+}
+
+// Atende indica se a notificação passa pelo filtro (sem considerar AposID e Limite)
+func (f FiltroNotificacoes) Atende(notificacao models.NotificacaoCorrida) bool {
+	if f.MotoristaID != "" && notificacao.MotoristaID != f.MotoristaID {
+		return false
+	}
+	if f.CorridaID != 0 && notificacao.CorridaID != f.CorridaID {
+		return false
+	}
+	if !f.CriadaDe.IsZero() && notificacao.CreatedAt.Before(f.CriadaDe) {
+		return false
+	}
+	if !f.CriadaAte.IsZero() && !notificacao.CreatedAt.Before(f.CriadaAte) {
+		return false
+	}
+	if len(f.Status) == 0 {
+		return true
+	}
+	for _, status := range f.Status {
+		if notificacao.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// PersistenciaNotificacoes guarda de forma durável o conjunto de notificações
+type PersistenciaNotificacoes interface {
+	Carregar() ([]models.NotificacaoCorrida, error)
+	Salvar(notificacoes []models.NotificacaoCorrida) error
+}
+
+// MemoriaNotificacaoRepository implementa NotificacaoRepository em memória, protegido por
+// mutex. Cada alteração é gravada na persistência ainda com o mutex travado; se a gravação
+// falhar, ela é desfeita em memória. As notificações são carregadas no primeiro acesso.
+type MemoriaNotificacaoRepository struct {
+	persistencia PersistenciaNotificacoes // nil mantém as notificações apenas em memória
+	notificacoes []models.NotificacaoCorrida
+	proximoID    uint
+	carregado    bool
+	mutex        sync.Mutex
+}
+
+// NewMemoriaNotificacaoRepository cria o repositório sobre a persistência informada
+func NewMemoriaNotificacaoRepository(persistencia PersistenciaNotificacoes) *MemoriaNotificacaoRepository {
+	return &MemoriaNotificacaoRepository{
+		persistencia: persistencia,
+		proximoID:    1,
+	}
+}
+
+// NewJSONNotificacaoRepository cria o repositório persistido em ./data/notificacao_corrida.json
+func NewJSONNotificacaoRepository() *MemoriaNotificacaoRepository {
+	return NewMemoriaNotificacaoRepository(NewArquivoNotificacoes("./data/notificacao_corrida.json"))
+}
+
+// carregar lê as notificações da persistência no primeiro acesso (deve ser chamada com o
+// mutex travado)
+func (r *MemoriaNotificacaoRepository) carregar() error {
+	if r.carregado {
+		return nil
+	}
+	if r.persistencia != nil {
+		notificacoes, err := r.persistencia.Carregar()
+		if err != nil {
+			return err
+		}
+		r.notificacoes = notificacoes
+		for _, notificacao := range notificacoes {
+			if notificacao.ID >= r.proximoID {
+				r.proximoID = notificacao.ID + 1
+			}
+		}
+	}
+	r.carregado = true
+	return nil
+}
+
+// salvar grava o estado atual na persistência (deve ser chamada com o mutex travado)
+func (r *MemoriaNotificacaoRepository) salvar() error {
+	if r.persistencia == nil {
+		return nil
+	}
+	return r.persistencia.Salvar(r.notificacoes)
+}
+
+// indice retorna a posição da notificação ou -1 (deve ser chamada com o mutex travado)
+func (r *MemoriaNotificacaoRepository) indice(id uint) int {
+	for i, notificacao := range r.notificacoes {
+		if notificacao.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// filtrar copia as notificações que atendem ao critério (deve ser chamada com o mutex travado)
+func (r *MemoriaNotificacaoRepository) filtrar(criterio func(models.NotificacaoCorrida) bool) []models.NotificacaoCorrida {
+	encontradas := []models.NotificacaoCorrida{}
+	for _, notificacao := range r.notificacoes {
+		if criterio(notificacao) {
+			encontradas = append(encontradas, notificacao)
+		}
+	}
+	return encontradas
+}
+
+// Criar atribui o próximo ID à notificação e a grava
+func (r *MemoriaNotificacaoRepository) Criar(notificacao *models.NotificacaoCorrida) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+
+	notificacao.ID = r.proximoID
+	r.notificacoes = append(r.notificacoes, *notificacao)
+	if err := r.salvar(); err != nil {
+		r.notificacoes = r.notificacoes[:len(r.notificacoes)-1]
+		notificacao.ID = 0
+		return err
+	}
+	r.proximoID++
+	return nil
+}
+
+// BuscarPorID busca uma notificação por ID
+func (r *MemoriaNotificacaoRepository) BuscarPorID(id uint) (models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return models.NotificacaoCorrida{}, err
+	}
+
+	if i := r.indice(id); i >= 0 {
+		return r.notificacoes[i], nil
+	}
+	return models.NotificacaoCorrida{}, ErrNotificacaoNaoEncontrada
+}
+
+// ListarTodas retorna todas as notificações, na ordem de criação
+func (r *MemoriaNotificacaoRepository) ListarTodas() ([]models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+
+	return r.filtrar(func(models.NotificacaoCorrida) bool { return true }), nil
+}
+
+// ListarPorCorrida retorna as ofertas de uma corrida
+func (r *MemoriaNotificacaoRepository) ListarPorCorrida(corridaID uint) ([]models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+
+	return r.filtrar(func(n models.NotificacaoCorrida) bool { return n.CorridaID == corridaID }), nil
+}
+
+// ListarPorMotorista retorna as ofertas feitas a um motorista
+func (r *MemoriaNotificacaoRepository) ListarPorMotorista(motoristaID models.MotoristaID) ([]models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+
+	return r.filtrar(func(n models.NotificacaoCorrida) bool { return n.MotoristaID == motoristaID }), nil
+}
+
+// Listar retorna as notificações do filtro. As notificações são guardadas na ordem de criação,
+// que é a ordem dos IDs.
+func (r *MemoriaNotificacaoRepository) Listar(filtro FiltroNotificacoes) ([]models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+
+	encontradas := []models.NotificacaoCorrida{}
+	for _, notificacao := range r.notificacoes {
+		if notificacao.ID <= filtro.AposID || !filtro.Atende(notificacao) {
+			continue
+		}
+		encontradas = append(encontradas, notificacao)
+		if filtro.Limite > 0 && len(encontradas) == filtro.Limite {
+			break
+		}
+	}
+	return encontradas, nil
+}
+
+// TrocarStatus grava o novo status se o atual for um dos esperados e registra a mudança
+func (r *MemoriaNotificacaoRepository) TrocarStatus(id uint, esperados []models.NotificacaoStatus, mudanca models.MudancaStatusNotificacao) (models.NotificacaoCorrida, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return models.NotificacaoCorrida{}, err
+	}
+
+	i := r.indice(id)
+	if i < 0 {
+		return models.NotificacaoCorrida{}, ErrNotificacaoNaoEncontrada
+	}
+	anterior := r.notificacoes[i]
+
+	esperado := false
+	for _, status := range esperados {
+		if anterior.Status == status {
+			esperado = true
+			break
+		}
+	}
+	if !esperado {
+		return anterior, ErrStatusNotificacaoAlterado
+	}
+
+	mudanca.De = anterior.Status
+	historico := make([]models.MudancaStatusNotificacao, 0, len(anterior.Historico)+1)
+	r.notificacoes[i].Status = mudanca.Para
+	r.notificacoes[i].UpdatedAt = mudanca.Em
+	r.notificacoes[i].Historico = append(append(historico, anterior.Historico...), mudanca)
+	if err := r.salvar(); err != nil {
+		r.notificacoes[i] = anterior
+		return anterior, err
+	}
+	return r.notificacoes[i], nil
+}
+
+// Deletar remove uma notificação
+func (r *MemoriaNotificacaoRepository) Deletar(id uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+
+	i := r.indice(id)
+	if i < 0 {
+		return ErrNotificacaoNaoEncontrada
+	}
+	restantes := make([]models.NotificacaoCorrida, 0, len(r.notificacoes)-1)
+	restantes = append(restantes, r.notificacoes[:i]...)
+	restantes = append(restantes, r.notificacoes[i+1:]...)
+
+	anteriores := r.notificacoes
+	r.notificacoes = restantes
+	if err := r.salvar(); err != nil {
+		r.notificacoes = anteriores
+		return err
+	}
+	return nil
+}
+
+// DeletarLote remove as notificações informadas com uma única gravação
+func (r *MemoriaNotificacaoRepository) DeletarLote(ids []uint) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return 0, err
+	}
+
+	remover := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		remover[id] = true
+	}
+	restantes := make([]models.NotificacaoCorrida, 0, len(r.notificacoes))
+	for _, notificacao := range r.notificacoes {
+		if !remover[notificacao.ID] {
+			restantes = append(restantes, notificacao)
+		}
+	}
+	removidas := len(r.notificacoes) - len(restantes)
+	if removidas == 0 {
+		return 0, nil
+	}
+
+	anteriores := r.notificacoes
+	r.notificacoes = restantes
+	if err := r.salvar(); err != nil {
+		r.notificacoes = anteriores
+		return 0, err
+	}
+	return removidas, nil
+}
+
+// ArquivoNotificacoes persiste as notificações em um arquivo JSON. A gravação é feita em um
+// arquivo temporário renomeado sobre o original, para que uma queda no meio da escrita não
+// deixe o arquivo truncado.
+type ArquivoNotificacoes struct {
+	filePath string
+}
+
+// NewArquivoNotificacoes cria a persistência no arquivo informado
+func NewArquivoNotificacoes(filePath string) *ArquivoNotificacoes {
+	return &ArquivoNotificacoes{filePath: filePath}
+}
+
+// Carregar lê as notificações do arquivo; um arquivo inexistente ou vazio não tem notificações
+func (a *ArquivoNotificacoes) Carregar() ([]models.NotificacaoCorrida, error) {
+	data, err := os.ReadFile(a.filePath)
+	if os.IsNotExist(err) {
+		return []models.NotificacaoCorrida{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if len(data) == 0 {
+		return []models.NotificacaoCorrida{}, nil
+	}
+
+	var notificacoes []models.NotificacaoCorrida
+	if err := json.Unmarshal(data, &notificacoes); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
+	}
+	return notificacoes, nil
+}
+
+// NewJSONArquivoMortoNotificacoes cria o arquivo morto das notificações removidas pela
+// política de retenção, em ./data/notificacoes_arquivadas.json
+func NewJSONArquivoMortoNotificacoes() *ArquivoNotificacoes {
+	return NewArquivoNotificacoes("./data/notificacoes_arquivadas.json")
+}
+
+// Anexar acrescenta as notificações às já gravadas no arquivo. Uma notificação já arquivada
+// (ex.: retenção repetida após uma falha na remoção) é substituída, não duplicada.
+func (a *ArquivoNotificacoes) Anexar(notificacoes []models.NotificacaoCorrida) error {
+	gravadas, err := a.Carregar()
+	if err != nil {
+		return err
+	}
+
+	novas := make(map[uint]bool, len(notificacoes))
+	for _, notificacao := range notificacoes {
+		novas[notificacao.ID] = true
+	}
+	todas := make([]models.NotificacaoCorrida, 0, len(gravadas)+len(notificacoes))
+	for _, gravada := range gravadas {
+		if !novas[gravada.ID] {
+			todas = append(todas, gravada)
+		}
+	}
+	return a.Salvar(append(todas, notificacoes...))
+}
+
+// Salvar grava as notificações no arquivo
+func (a *ArquivoNotificacoes) Salvar(notificacoes []models.NotificacaoCorrida) error {
+	dir := filepath.Dir(a.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	if notificacoes == nil {
+		notificacoes = []models.NotificacaoCorrida{}
+	}
+	data, err := json.MarshalIndent(notificacoes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	temporario, err := os.CreateTemp(dir, filepath.Base(a.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(temporario.Name())
+
+	if _, err := temporario.Write(data); err != nil {
+		temporario.Close()
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	if err := temporario.Sync(); err != nil {
+		temporario.Close()
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	if err := temporario.Close(); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	if err := os.Chmod(temporario.Name(), 0644); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	if err := os.Rename(temporario.Name(), a.filePath); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
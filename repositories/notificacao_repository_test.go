@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// persistenciaComFalha recusa todas as gravações
+type persistenciaComFalha struct{}
+
+func (persistenciaComFalha) Carregar() ([]models.NotificacaoCorrida, error) { return nil, nil }
+func (persistenciaComFalha) Salvar([]models.NotificacaoCorrida) error {
+	return errors.New("disco cheio")
+}
+
+func TestMemoriaNotificacaoRepository(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), "notificacao_corrida.json")
+	repo := NewMemoriaNotificacaoRepository(NewArquivoNotificacoes(arquivo))
+	agora := time.Now()
+
+	primeira := &models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 10, Status: models.NotificacaoPendente}
+	segunda := &models.NotificacaoCorrida{MotoristaID: "2", CorridaID: 10, Status: models.NotificacaoPendente}
+	require.NoError(t, repo.Criar(primeira))
+	require.NoError(t, repo.Criar(segunda))
+	assert.Equal(t, uint(1), primeira.ID)
+	assert.Equal(t, uint(2), segunda.ID)
+
+	t.Run("TrocarStatus só grava a partir do status esperado", func(t *testing.T) {
+		aceite := models.MudancaStatusNotificacao{Para: models.NotificacaoAceita, Por: "motorista:1", Motivo: "aceita pelo motorista", Em: agora}
+		aceita, err := repo.TrocarStatus(primeira.ID, []models.NotificacaoStatus{models.NotificacaoPendente}, aceite)
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacaoAceita, aceita.Status)
+		require.Len(t, aceita.Historico, 1)
+		assert.Equal(t, models.NotificacaoPendente, aceita.Historico[0].De)
+		assert.Equal(t, "motorista:1", aceita.Historico[0].Por)
+
+		expiracao := models.MudancaStatusNotificacao{Para: models.NotificacaoExpirada, Por: models.AutorSistema, Em: agora}
+		atual, err := repo.TrocarStatus(primeira.ID, []models.NotificacaoStatus{models.NotificacaoPendente}, expiracao)
+		assert.ErrorIs(t, err, ErrStatusNotificacaoAlterado)
+		assert.Equal(t, models.NotificacaoAceita, atual.Status)
+		assert.Len(t, atual.Historico, 1, "mudança recusada não entra no histórico")
+
+		_, err = repo.TrocarStatus(99, []models.NotificacaoStatus{models.NotificacaoPendente}, aceite)
+		assert.ErrorIs(t, err, ErrNotificacaoNaoEncontrada)
+	})
+
+	t.Run("Notificações e próximo ID sobrevivem ao reinício", func(t *testing.T) {
+		reaberto := NewMemoriaNotificacaoRepository(NewArquivoNotificacoes(arquivo))
+		gravada, err := reaberto.BuscarPorID(primeira.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacaoAceita, gravada.Status)
+		assert.Len(t, gravada.Historico, 1)
+
+		daCorrida, err := reaberto.ListarPorCorrida(10)
+		require.NoError(t, err)
+		assert.Len(t, daCorrida, 2)
+
+		terceira := &models.NotificacaoCorrida{MotoristaID: "3", CorridaID: 11}
+		require.NoError(t, reaberto.Criar(terceira))
+		assert.Equal(t, uint(3), terceira.ID)
+
+		require.NoError(t, reaberto.Deletar(terceira.ID))
+		assert.ErrorIs(t, reaberto.Deletar(terceira.ID), ErrNotificacaoNaoEncontrada)
+	})
+
+	t.Run("Falha na gravação desfaz a alteração em memória", func(t *testing.T) {
+		comFalha := NewMemoriaNotificacaoRepository(persistenciaComFalha{})
+		notificacao := &models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 1}
+		assert.Error(t, comFalha.Criar(notificacao))
+
+		todas, err := comFalha.ListarTodas()
+		require.NoError(t, err)
+		assert.Empty(t, todas)
+	})
+}
+
+func TestListarNotificacoesComFiltro(t *testing.T) {
+	repo := NewMemoriaNotificacaoRepository(nil)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, dados := range []struct {
+		motorista models.MotoristaID
+		corrida   uint
+		status    models.NotificacaoStatus
+	}{
+		{"1", 10, models.NotificacaoAceita},
+		{"1", 11, models.NotificacaoRecusada},
+		{"2", 11, models.NotificacaoAceita},
+		{"1", 12, models.NotificacaoPendente},
+		{"1", 13, models.NotificacaoExpirada},
+	} {
+		require.NoError(t, repo.Criar(&models.NotificacaoCorrida{
+			MotoristaID: dados.motorista,
+			CorridaID:   dados.corrida,
+			Status:      dados.status,
+			CreatedAt:   base.AddDate(0, 0, i),
+		}))
+	}
+
+	ids := func(notificacoes []models.NotificacaoCorrida) []uint {
+		var ids []uint
+		for _, notificacao := range notificacoes {
+			ids = append(ids, notificacao.ID)
+		}
+		return ids
+	}
+
+	doMotorista, err := repo.Listar(FiltroNotificacoes{MotoristaID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 4, 5}, ids(doMotorista))
+
+	respondidas, err := repo.Listar(FiltroNotificacoes{Status: []models.NotificacaoStatus{models.NotificacaoAceita, models.NotificacaoRecusada}, CorridaID: 11})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 3}, ids(respondidas))
+
+	periodo, err := repo.Listar(FiltroNotificacoes{CriadaDe: base.AddDate(0, 0, 1), CriadaAte: base.AddDate(0, 0, 3)})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 3}, ids(periodo), "início inclusivo, fim exclusivo")
+
+	pagina, err := repo.Listar(FiltroNotificacoes{MotoristaID: "1", AposID: 2, Limite: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4}, ids(pagina))
+
+	removidas, err := repo.DeletarLote([]uint{1, 3, 99})
+	require.NoError(t, err)
+	assert.Equal(t, 2, removidas)
+	restantes, _ := repo.ListarTodas()
+	assert.Equal(t, []uint{2, 4, 5}, ids(restantes))
+}
+
+func TestArquivoNotificacoesAnexar(t *testing.T) {
+	arquivo := NewArquivoNotificacoes(filepath.Join(t.TempDir(), "arquivadas.json"))
+
+	require.NoError(t, arquivo.Anexar([]models.NotificacaoCorrida{{ID: 1, Status: models.NotificacaoAceita}}))
+	require.NoError(t, arquivo.Anexar([]models.NotificacaoCorrida{{ID: 1, Status: models.NotificacaoAceita}, {ID: 2}}))
+
+	arquivadas, err := arquivo.Carregar()
+	require.NoError(t, err)
+	assert.Len(t, arquivadas, 2, "reanexar a mesma notificação não a duplica")
+}
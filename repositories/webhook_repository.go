@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"taxi-service/models"
+)
+
+// Erros do repositório de webhooks
+var (
+	ErrAssinaturaWebhookNaoEncontrada = errors.New("assinatura de webhook não encontrada")
+	ErrFalhaWebhookNaoEncontrada      = errors.New("entrega com falha não encontrada")
+)
+
+// WebhookRepository guarda as assinaturas de webhook e as entregas que esgotaram as tentativas
+type WebhookRepository interface {
+	CriarAssinatura(assinatura models.AssinaturaWebhook) error
+	BuscarAssinatura(id string) (models.AssinaturaWebhook, error)
+	AtualizarAssinatura(assinatura models.AssinaturaWebhook) error
+	DeletarAssinatura(id string) error
+	ListarAssinaturas() ([]models.AssinaturaWebhook, error)
+
+	// SalvarFalha grava a entrega com falha, substituindo a de mesmo ID
+	SalvarFalha(entrega models.EntregaWebhook) error
+	BuscarFalha(id string) (models.EntregaWebhook, error)
+	RemoverFalha(id string) error
+	ListarFalhas() ([]models.EntregaWebhook, error)
+}
+
+// arquivoWebhooks é o conteúdo gravado no arquivo JSON
+type arquivoWebhooks struct {
+	Assinaturas []models.AssinaturaWebhook `json:"assinaturas"`
+	Falhas      []models.EntregaWebhook    `json:"falhas"`
+}
+
+// JSONWebhookRepository implementa WebhookRepository usando arquivo JSON
+type JSONWebhookRepository struct {
+	filePath string
+	mutex    sync.RWMutex
+}
+
+// NewJSONWebhookRepository cria uma nova instância do repositório
+func NewJSONWebhookRepository() *JSONWebhookRepository {
+	return &JSONWebhookRepository{
+		filePath: "./data/webhooks.json",
+	}
+}
+
+// ler lê o arquivo JSON (deve ser chamada com o mutex travado)
+func (r *JSONWebhookRepository) ler() (arquivoWebhooks, error) {
+	conteudo := arquivoWebhooks{
+		Assinaturas: []models.AssinaturaWebhook{},
+		Falhas:      []models.EntregaWebhook{},
+	}
+	data, err := os.ReadFile(r.filePath)
+	if os.IsNotExist(err) {
+		return conteudo, nil
+	}
+	if err != nil {
+		return conteudo, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if len(data) == 0 {
+		return conteudo, nil
+	}
+
+	if err := json.Unmarshal(data, &conteudo); err != nil {
+		return conteudo, fmt.Errorf("erro ao deserializar dados: %w", err)
+	}
+	return conteudo, nil
+}
+
+// salvar grava o arquivo JSON (deve ser chamada com o mutex travado)
+func (r *JSONWebhookRepository) salvar(conteudo arquivoWebhooks) error {
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	data, err := json.MarshalIndent(conteudo, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	if err := os.WriteFile(r.filePath, data, 0644); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
+
+// CriarAssinatura adiciona uma nova assinatura
+func (r *JSONWebhookRepository) CriarAssinatura(assinatura models.AssinaturaWebhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range conteudo.Assinaturas {
+		if a.ID == assinatura.ID {
+			return errors.New("assinatura com este ID já existe")
+		}
+	}
+
+	conteudo.Assinaturas = append(conteudo.Assinaturas, assinatura)
+	return r.salvar(conteudo)
+}
+
+// BuscarAssinatura busca uma assinatura por ID
+func (r *JSONWebhookRepository) BuscarAssinatura(id string) (models.AssinaturaWebhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return models.AssinaturaWebhook{}, err
+	}
+
+	for _, assinatura := range conteudo.Assinaturas {
+		if assinatura.ID == id {
+			return assinatura, nil
+		}
+	}
+	return models.AssinaturaWebhook{}, ErrAssinaturaWebhookNaoEncontrada
+}
+
+// AtualizarAssinatura substitui uma assinatura existente
+func (r *JSONWebhookRepository) AtualizarAssinatura(assinatura models.AssinaturaWebhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	for i, a := range conteudo.Assinaturas {
+		if a.ID == assinatura.ID {
+			conteudo.Assinaturas[i] = assinatura
+			return r.salvar(conteudo)
+		}
+	}
+	return ErrAssinaturaWebhookNaoEncontrada
+}
+
+// DeletarAssinatura remove uma assinatura
+func (r *JSONWebhookRepository) DeletarAssinatura(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	for i, assinatura := range conteudo.Assinaturas {
+		if assinatura.ID == id {
+			conteudo.Assinaturas = append(conteudo.Assinaturas[:i], conteudo.Assinaturas[i+1:]...)
+			return r.salvar(conteudo)
+		}
+	}
+	return ErrAssinaturaWebhookNaoEncontrada
+}
+
+// ListarAssinaturas retorna todas as assinaturas
+func (r *JSONWebhookRepository) ListarAssinaturas() ([]models.AssinaturaWebhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	conteudo, err := r.ler()
+	return conteudo.Assinaturas, err
+}
+
+// SalvarFalha grava a entrega com falha, substituindo a de mesmo ID
+func (r *JSONWebhookRepository) SalvarFalha(entrega models.EntregaWebhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	for i, falha := range conteudo.Falhas {
+		if falha.ID == entrega.ID {
+			conteudo.Falhas[i] = entrega
+			return r.salvar(conteudo)
+		}
+	}
+	conteudo.Falhas = append(conteudo.Falhas, entrega)
+	return r.salvar(conteudo)
+}
+
+// BuscarFalha busca uma entrega com falha por ID
+func (r *JSONWebhookRepository) BuscarFalha(id string) (models.EntregaWebhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return models.EntregaWebhook{}, err
+	}
+
+	for _, falha := range conteudo.Falhas {
+		if falha.ID == id {
+			return falha, nil
+		}
+	}
+	return models.EntregaWebhook{}, ErrFalhaWebhookNaoEncontrada
+}
+
+// RemoverFalha remove uma entrega com falha (ex.: depois de reenviada com sucesso)
+func (r *JSONWebhookRepository) RemoverFalha(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conteudo, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	for i, falha := range conteudo.Falhas {
+		if falha.ID == id {
+			conteudo.Falhas = append(conteudo.Falhas[:i], conteudo.Falhas[i+1:]...)
+			return r.salvar(conteudo)
+		}
+	}
+	return ErrFalhaWebhookNaoEncontrada
+}
+
+// ListarFalhas retorna as entregas com falha, da mais antiga à mais nova
+func (r *JSONWebhookRepository) ListarFalhas() ([]models.EntregaWebhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	conteudo, err := r.ler()
+	return conteudo.Falhas, err
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func TestJSONWebhookRepository(t *testing.T) {
+	// Usar arquivo temporário para testes
+	tempFile := "./data/test_webhooks.json"
+
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONWebhookRepository{
+		filePath: tempFile,
+	}
+
+	assinatura := models.AssinaturaWebhook{
+		ID:      "hotel",
+		URL:     "https://hotel.exemplo.com/webhooks",
+		Eventos: []models.TipoEventoWebhook{models.EventoWebhookCorridaAceita},
+		Segredo: "segredo",
+		Ativa:   true,
+	}
+	require.NoError(t, repo.CriarAssinatura(assinatura))
+	assert.Error(t, repo.CriarAssinatura(assinatura), "ID repetido")
+
+	assinatura.Ativa = false
+	require.NoError(t, repo.AtualizarAssinatura(assinatura))
+	salva, err := repo.BuscarAssinatura("hotel")
+	require.NoError(t, err)
+	assert.False(t, salva.Ativa)
+	assert.Equal(t, "segredo", salva.Segredo)
+
+	// Falhas: gravar de novo substitui a entrega de mesmo ID
+	falha := models.EntregaWebhook{ID: "e1", AssinaturaID: "hotel", Evento: models.EventoWebhookCorridaAceita, Tentativas: 5}
+	require.NoError(t, repo.SalvarFalha(falha))
+	falha.Tentativas = 6
+	require.NoError(t, repo.SalvarFalha(falha))
+
+	falhas, err := repo.ListarFalhas()
+	require.NoError(t, err)
+	require.Len(t, falhas, 1)
+	assert.Equal(t, 6, falhas[0].Tentativas)
+
+	require.NoError(t, repo.RemoverFalha("e1"))
+	_, err = repo.BuscarFalha("e1")
+	assert.ErrorIs(t, err, ErrFalhaWebhookNaoEncontrada)
+
+	require.NoError(t, repo.DeletarAssinatura("hotel"))
+	_, err = repo.BuscarAssinatura("hotel")
+	assert.ErrorIs(t, err, ErrAssinaturaWebhookNaoEncontrada)
+}
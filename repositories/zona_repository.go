@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"taxi-service/models"
+)
+
+// ErrZonaNaoEncontrada indica que não há zona com o ID informado
+var ErrZonaNaoEncontrada = errors.New("zona não encontrada")
+
+// ZonaRepository define a interface para operações com zonas geográficas.
+// Atualizar só grava se a Versao da zona for igual à armazenada e então a incrementa.
+type ZonaRepository interface {
+	Criar(zona *models.Zona) error
+	BuscarPorID(id string) (*models.Zona, error)
+	Atualizar(zona *models.Zona) error
+	Deletar(id string) error
+	ListarTodas() ([]*models.Zona, error)
+}
+
+// JSONZonaRepository implementa ZonaRepository usando arquivo JSON
+type JSONZonaRepository struct {
+	filePath string
+	mutex    sync.RWMutex
+}
+
+// NewJSONZonaRepository cria uma nova instância do repositório
+func NewJSONZonaRepository() *JSONZonaRepository {
+	return &JSONZonaRepository{
+		filePath: "./data/zonas.json",
+	}
+}
+
+// lerZonas lê todas as zonas do arquivo JSON (deve ser chamada com o mutex travado)
+func (r *JSONZonaRepository) lerZonas() ([]*models.Zona, error) {
+	data, err := os.ReadFile(r.filePath)
+	if os.IsNotExist(err) {
+		return []*models.Zona{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if len(data) == 0 {
+		return []*models.Zona{}, nil
+	}
+
+	var zonas []*models.Zona
+	if err := json.Unmarshal(data, &zonas); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
+	}
+	return zonas, nil
+}
+
+// salvarZonas salva todas as zonas no arquivo JSON (deve ser chamada com o mutex travado)
+func (r *JSONZonaRepository) salvarZonas(zonas []*models.Zona) error {
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	data, err := json.MarshalIndent(zonas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	if err := os.WriteFile(r.filePath, data, 0644); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
+
+// Criar adiciona uma nova zona
+func (r *JSONZonaRepository) Criar(zona *models.Zona) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	zonas, err := r.lerZonas()
+	if err != nil {
+		return err
+	}
+
+	for _, z := range zonas {
+		if z.ID == zona.ID {
+			return errors.New("zona com este ID já existe")
+		}
+	}
+
+	if zona.Versao == 0 {
+		zona.Versao = 1
+	}
+
+	zonas = append(zonas, zona)
+	return r.salvarZonas(zonas)
+}
+
+// BuscarPorID busca uma zona por ID
+func (r *JSONZonaRepository) BuscarPorID(id string) (*models.Zona, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	zonas, err := r.lerZonas()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zona := range zonas {
+		if zona.ID == id {
+			return zona, nil
+		}
+	}
+	return nil, ErrZonaNaoEncontrada
+}
+
+// Atualizar atualiza uma zona existente se a versão lida ainda for a atual
+func (r *JSONZonaRepository) Atualizar(zona *models.Zona) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	zonas, err := r.lerZonas()
+	if err != nil {
+		return err
+	}
+
+	for i, z := range zonas {
+		if z.ID == zona.ID {
+			if z.Versao != zona.Versao {
+				return ErrVersaoConflito
+			}
+			atualizada := *zona
+			atualizada.Versao = z.Versao + 1
+			zonas[i] = &atualizada
+			if err := r.salvarZonas(zonas); err != nil {
+				return err
+			}
+			zona.Versao = atualizada.Versao
+			return nil
+		}
+	}
+	return ErrZonaNaoEncontrada
+}
+
+// Deletar remove uma zona
+func (r *JSONZonaRepository) Deletar(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	zonas, err := r.lerZonas()
+	if err != nil {
+		return err
+	}
+
+	for i, zona := range zonas {
+		if zona.ID == id {
+			zonas = append(zonas[:i], zonas[i+1:]...)
+			return r.salvarZonas(zonas)
+		}
+	}
+	return ErrZonaNaoEncontrada
+}
+
+// ListarTodas retorna todas as zonas
+func (r *JSONZonaRepository) ListarTodas() ([]*models.Zona, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.lerZonas()
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func TestJSONZonaRepository(t *testing.T) {
+	// Usar arquivo temporário para testes
+	tempFile := "./data/test_zonas.json"
+
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONZonaRepository{
+		filePath: tempFile,
+	}
+
+	zona := &models.Zona{
+		ID:   "centro",
+		Nome: "Centro",
+		Tipo: models.ZonaAreaAtendimento,
+		Geometria: models.Geometria{Poligonos: []models.Poligono{{
+			{{-34.9, -8.1}, {-34.8, -8.1}, {-34.8, -8.0}, {-34.9, -8.1}},
+		}}},
+		Ativa: true,
+	}
+
+	t.Run("Criar e buscar zona", func(t *testing.T) {
+		require.NoError(t, repo.Criar(zona))
+		assert.Equal(t, 1, zona.Versao)
+		assert.Error(t, repo.Criar(zona), "ID duplicado")
+
+		encontrada, err := repo.BuscarPorID("centro")
+		require.NoError(t, err)
+		assert.Equal(t, zona.Geometria, encontrada.Geometria)
+	})
+
+	t.Run("Atualizar compara a versão", func(t *testing.T) {
+		alterada := *zona
+		alterada.Nome = "Centro expandido"
+		require.NoError(t, repo.Atualizar(&alterada))
+		assert.Equal(t, 2, alterada.Versao)
+
+		assert.ErrorIs(t, repo.Atualizar(zona), ErrVersaoConflito)
+	})
+
+	t.Run("Deletar zona", func(t *testing.T) {
+		require.NoError(t, repo.Deletar("centro"))
+		_, err := repo.BuscarPorID("centro")
+		assert.ErrorIs(t, err, ErrZonaNaoEncontrada)
+		assert.ErrorIs(t, repo.Deletar("centro"), ErrZonaNaoEncontrada)
+
+		zonas, err := repo.ListarTodas()
+		require.NoError(t, err)
+		assert.Empty(t, zonas)
+	})
+}
@@ -2,16 +2,19 @@ package routes
 
 import (
 	"taxi-service/controllers"
+	"taxi-service/middlewares"
 	"taxi-service/services"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupCorridaRoutes configura as rotas relacionadas a corridas.
-func SetupCorridaRoutes(api fiber.Router, corridaService *services.CorridaService) {
+func SetupCorridaRoutes(api fiber.Router, corridaService *services.CorridaService, idempotenciaStore *middlewares.IdempotenciaStore) {
 	corridaController := controllers.NewCorridaController(corridaService)
+	idempotencia := middlewares.Idempotencia(idempotenciaStore)
 
-	corridaGroup := api.Group("/corrida")
+	corridaGroup := api.Group("/corrida", idempotencia)
 	corridaGroup.Post("/", corridaController.CriarCorrida)
+	corridaGroup.Post("/rua", corridaController.IniciarCorridaDeRua) // Corrida de rua iniciada pelo motorista
 	corridaGroup.Get("/:id", corridaController.GetCorrida) // Nova rota
 	corridaGroup.Post("/monitorar", corridaController.MonitorarCorrida)
 	corridaGroup.Put("/:id/aceitar", corridaController.AceitarCorrida)
@@ -19,10 +22,13 @@ func SetupCorridaRoutes(api fiber.Router, corridaService *services.CorridaServic
 	corridaGroup.Post("/:id/cancelar", corridaController.CancelarCorrida) // Nova rota
 	corridaGroup.Post("/:id/finalizar", corridaController.FinalizarCorrida) // Nova rota
     corridaGroup.Post("/:id/cancelar/motorista", corridaController.CancelarCorridaPeloMotorista) 
+	corridaGroup.Get("/:id/recibo", corridaController.ReciboCorrida)  // Recibo da corrida de rua
+	corridaGroup.Post("/:id/recibo", corridaController.EnviarRecibo) // Envia o recibo por email
 
-	api.Post("/corridas/:id/avaliar", corridaController.AvaliarCorrida)
-	api.Post("/corridas", corridaController.CriarCorrida)
+	api.Post("/corridas/:id/avaliar", idempotencia, corridaController.AvaliarCorrida)
+	api.Post("/corridas", idempotencia, corridaController.CriarCorrida)
 	api.Get("/corridas", corridaController.ListarCorridas)
+	api.Get("/corridas/categorias", corridaController.ListarCategorias)
 
 	// Manter a rota OPTIONS para o CORS
 	corridaGroup.Options("/monitorar", func(c *fiber.Ctx) error {
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"taxi-service/controllers"
+	"taxi-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupFilaPontoRoutes configura a consulta das filas dos pontos de táxi e aeroportos
+func SetupFilaPontoRoutes(api fiber.Router, filas *services.FilaPontoService) {
+	filaPontoController := controllers.NewFilaPontoController(filas)
+
+	apiGroup := api.Group("/api")
+	apiGroup.Get("/motoristas/:id/fila", filaPontoController.ConsultarPosicao) // Posição na fila do ponto
+}
@@ -8,12 +8,12 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupMotoristaRoutes(api fiber.Router) {
+func SetupMotoristaRoutes(api fiber.Router, motoristaRepo repositories.MotoristaRepository, registro *services.RegistroDisponibilidade) {
 	// Inicializar dependências
-	motoristaRepo := repositories.NewJSONMotoristaRepository()
 	emailService := services.NewSMTPEmailServiceFromEnv()
 	motoristaService := services.NewMotoristaService(motoristaRepo, emailService)
 	motoristaController := controllers.NewMotoristaController(motoristaService)
+	disponibilidadeController := controllers.NewDisponibilidadeController(registro)
 
 	// Grupo de rotas da API
 	apiGroup := api.Group("/api")
@@ -27,6 +27,12 @@ func SetupMotoristaRoutes(api fiber.Router) {
 	motoristas.Put("/:id/aprovar", motoristaController.AprovarMotorista)              // Aprovar motorista
 	motoristas.Put("/:id/rejeitar", motoristaController.RejeitarMotorista)            // Rejeitar motorista
 
+	// Disponibilidade e posição atual
+	motoristas.Post("/:id/online", disponibilidadeController.FicarOnline)                      // Ficar disponível
+	motoristas.Post("/:id/offline", disponibilidadeController.FicarOffline)                    // Ficar offline
+	motoristas.Post("/:id/heartbeat", disponibilidadeController.Heartbeat)                     // Enviar posição atual
+	motoristas.Get("/:id/disponibilidade", disponibilidadeController.ConsultarDisponibilidade) // Consultar status
+
 	// Utilitários
 	motoristas.Post("/verificar-senha", motoristaController.VerificarForcaSenha)
 	motoristas.Post("/validar-documento", motoristaController.ValidarDocumentoUpload)
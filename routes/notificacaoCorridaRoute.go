@@ -3,13 +3,20 @@ package routes
 import (
     "github.com/gofiber/fiber/v2"
     "taxi-service/controllers"
+    "taxi-service/middlewares"
+    "taxi-service/services"
 )
 
-func NotificacaoCorridaRoutes(api fiber.Router) {
-    notificacoes := api.Group("/notificacoes")
+func NotificacaoCorridaRoutes(api fiber.Router, idempotenciaStore *middlewares.IdempotenciaStore, retencao *services.RetencaoNotificacoes, estatisticas *services.EstatisticasMotoristas) {
+    retencaoController := controllers.NewRetencaoNotificacoesController(retencao)
+    estatisticasController := controllers.NewEstatisticasMotoristaController(estatisticas)
+
+    // Métodos mutáveis aceitam o cabeçalho Idempotency-Key para retentativas seguras
+    notificacoes := api.Group("/notificacoes", middlewares.Idempotencia(idempotenciaStore))
 
     // ============= ROTAS CRUD BÁSICAS =============
-    // GET /notificacoes - Lista todas as notificações
+    // GET /notificacoes - Lista as notificações em páginas (?cursor=&limite=), com filtros por
+    // status, motorista_id, corrida_id e período de criação (?de=&ate=)
     notificacoes.Get("/", controllers.ListNotificacoesCorrida)
     
     // GET /notificacoes/:id - Busca notificação por ID
@@ -28,6 +35,10 @@ func NotificacaoCorridaRoutes(api fiber.Router) {
     // GET /notificacoes/motorista/:motoristaID/historico - Histórico do motorista
     notificacoes.Get("/motorista/:motoristaID/historico", controllers.GetHistoricoNotificacoesMotorista)
 
+    // GET /notificacoes/motorista/:motoristaID/estatisticas - Taxas de aceite, recusa e expiração
+    // e tempo médio de resposta em 7 ou 30 dias (?janela=) ou no período ?de=&ate=
+    notificacoes.Get("/motorista/:motoristaID/estatisticas", estatisticasController.BuscarEstatisticas)
+
     // ============= ROTAS DE AÇÕES =============
     // POST /notificacoes/:id/motorista/:motoristaID/accept - Aceitar notificação
     notificacoes.Post("/:id/motorista/:motoristaID/accept", controllers.AceitarNotificacaoCorrida)
@@ -39,6 +50,9 @@ func NotificacaoCorridaRoutes(api fiber.Router) {
     // POST /notificacoes/expire - Expirar notificações vencidas
     notificacoes.Post("/expire", controllers.ExpirarNotificacoesVencidas)
     
-    // PUT /notificacoes/:id/status - Atualizar status (auxiliar)
+    // POST /notificacoes/retencao - Arquiva ou exclui as notificações antigas (simulação por padrão)
+    notificacoes.Post("/retencao", retencaoController.AplicarRetencao)
+
+    // PUT /notificacoes/:id/status - Mudar o status de uma oferta pendente (suporte), com autor e motivo
     notificacoes.Put("/:id/status", controllers.UpdateNotificacaoStatus)
 }
\ No newline at end of file
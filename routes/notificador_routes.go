@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"taxi-service/controllers"
+	"taxi-service/models"
+	"taxi-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupNotificadorRoutes configura a caixa de entrada e as preferências de canais de notificação
+func SetupNotificadorRoutes(api fiber.Router, roteador *services.RoteadorNotificacoes, caixa *services.CaixaEntrada) {
+	notificadorController := controllers.NewNotificadorController(roteador, caixa)
+
+	apiGroup := api.Group("/api")
+	for prefixo, tipo := range map[string]models.TipoDestinatario{
+		"/motoristas/:id":  models.DestinatarioMotorista,
+		"/passageiros/:id": models.DestinatarioPassageiro,
+	} {
+		apiGroup.Get(prefixo+"/notificacoes", notificadorController.ListarCaixaEntrada(tipo))
+		apiGroup.Get(prefixo+"/notificacoes/preferencias", notificadorController.BuscarPreferencias(tipo))
+		apiGroup.Put(prefixo+"/notificacoes/preferencias", notificadorController.DefinirPreferencias(tipo))
+	}
+}
@@ -1,6 +1,11 @@
 package routes
 
 import (
+	"log"
+
+	"taxi-service/middlewares"
+	"taxi-service/models"
+	"taxi-service/repositories"
 	"taxi-service/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,8 +19,92 @@ func SetupRoutes(app *fiber.App) {
 	app.Use(cors.New())
 	app.Use(logger.New())
 
+	// Repositório de motoristas compartilhado entre cadastro e corridas
+	motoristaRepo := repositories.NewJSONMotoristaRepository()
+
 	// Crie uma instância do serviço de corrida
-	corridaService := services.NewCorridaService()
+	elegibilidadeService := services.NewElegibilidadeService(motoristaRepo)
+	corridaService := services.NewCorridaService(elegibilidadeService)
+
+	// Registro de motoristas online, mantido em sincronia com as corridas aceitas e encerradas
+	registroDisponibilidade := services.NewRegistroDisponibilidadeFromEnv(elegibilidadeService)
+	corridaService.RegistrarOuvinte(registroDisponibilidade.OuvirCorridas)
+
+	// Zonas geográficas: áreas de atendimento e restritas validam o embarque das novas corridas
+	zonaService := services.NewZonaServiceFromEnv(repositories.NewJSONZonaRepository())
+	corridaService.DefinirValidadorEmbarque(zonaService)
+
+	// Recibos das corridas de rua são enviados por email ao passageiro
+	corridaService.DefinirEnvioRecibo(services.NewSMTPEmailServiceFromEnv())
+
+	// Tarifa dinâmica por zona: pedidos de corrida são a demanda e motoristas disponíveis a oferta.
+	// Posições dentro de uma região de preço cadastrada usam a região; as demais, a grade.
+	configTarifaDinamica := services.ConfigTarifaDinamicaFromEnv()
+	regioesPreco := zonaService.RegioesPreco(services.NewGradeZonas(configTarifaDinamica.TamanhoZonaKm))
+	tarifaDinamica := services.NewTarifaDinamicaService(regioesPreco, registroDisponibilidade, configTarifaDinamica)
+	corridaService.DefinirPrecificador(tarifaDinamica)
+
+	// Novas corridas geram ofertas para os motoristas disponíveis próximos ao embarque
+	// e são reofertadas quando todos recusam ou deixam a oferta expirar
+	despachoService := services.NewDespachoServiceFromEnv(registroDisponibilidade, elegibilidadeService, corridaService)
+	corridaService.RegistrarOuvinte(despachoService.OuvirCorridas)
+	// Buscas que terminam sem aceite encerram a corrida e avisam o passageiro
+	despachoService.DefinirEncerrador(corridaService)
+	// Estratégias configuradas por zona valem para os embarques dentro das zonas cadastradas
+	despachoService.DefinirZonas(zonaService)
+	notificacaoRepo := repositories.NewJSONNotificacaoRepository()
+	services.DefinirRepositorioNotificacoes(notificacaoRepo)
+	services.DefinirConfigTTLOfertas(services.ConfigTTLOfertasFromEnv())
+	// Um único expirador acompanha os prazos das ofertas, reconstruído a partir das pendentes gravadas
+	if err := services.IniciarExpiracaoOfertas(services.PrecisaoExpiracaoFromEnv()); err != nil {
+		log.Printf("Erro ao reagendar a expiração das ofertas pendentes: %v", err)
+	}
+	services.RegistrarOuvinteNotificacao(despachoService.OuvirNotificacoes)
+	// A taxa de aceite dos últimos 30 dias pondera a distância na classificação dos candidatos
+	estatisticasMotoristas := services.NewEstatisticasMotoristas(notificacaoRepo)
+	despachoService.DefinirTaxasAceite(estatisticasMotoristas)
+	services.RegistrarAtribuidorCorrida(corridaService)
+
+	// Pontos de táxi e aeroportos: quem entra na zona disponível vai para o fim da fila e
+	// embarques dentro do ponto são ofertados ao primeiro da fila
+	filasPonto := services.NewFilaPontoService(zonaService)
+	registroDisponibilidade.RegistrarOuvinte(filasPonto.OuvirDisponibilidade)
+	despachoService.DefinirFilas(filasPonto)
+
+	// Avisos a passageiros e motoristas pela caixa de entrada do aplicativo, email, SMS e push,
+	// conforme o evento e as preferências de cada um. SMS e push usam o provedor local
+	// (console) até um gateway ser configurado.
+	provedorLocal := services.NewProvedorLocal()
+	caixaEntrada := services.NewCaixaEntrada()
+	notificador := services.NewRoteadorNotificacoes(
+		caixaEntrada,
+		services.NewCanalEmail(services.NewSMTPEmailServiceFromEnv()),
+		services.NewCanalSMS(provedorLocal),
+		services.NewCanalPush(provedorLocal),
+	)
+	// As preferências dos motoristas (canais, horário de silêncio e limite de ofertas) ficam no
+	// perfil, onde o despacho também as consulta
+	notificador.RegistrarArmazemPreferencias(models.DestinatarioMotorista, services.NewPreferenciasNoPerfil(motoristaRepo))
+	// Passageiros são avisados do motorista encontrado, da chegada, de atrasos, cancelamentos e da conclusão
+	avisosPassageiro := services.NewAvisosPassageiro(notificador)
+	corridaService.RegistrarOuvinte(avisosPassageiro.OuvirCorridas)
+	// Motoristas são avisados das novas ofertas e do cancelamento das corridas que aceitaram
+	despachoService.DefinirNotificador(notificador)
+	avisosMotorista := services.NewAvisosMotorista(notificador, motoristaRepo)
+	corridaService.RegistrarOuvinte(avisosMotorista.OuvirCorridas)
+
+	// Eventos das corridas e mudanças de status dos motoristas são enviados aos parceiros
+	// inscritos (balcões de hotel, BI) por webhooks assinados, com novas tentativas
+	webhookService := services.NewWebhookServiceFromEnv(repositories.NewJSONWebhookRepository())
+	corridaService.RegistrarOuvinte(webhookService.OuvirCorridas)
+	registroDisponibilidade.RegistrarOuvinte(webhookService.OuvirDisponibilidade)
+
+	// Notificações finalizadas mais antigas que o prazo de retenção vão para o arquivo morto
+	// (ou são excluídas) periodicamente
+	retencaoNotificacoes := services.NewRetencaoNotificacoesFromEnv(notificacaoRepo, repositories.NewJSONArquivoMortoNotificacoes())
+
+	// Armazenamento compartilhado das respostas por Idempotency-Key
+	idempotenciaStore := middlewares.NewIdempotenciaStoreFromEnv()
 
 	// Grupo de rotas da API
 	api := app.Group("/", logger.New())
@@ -26,7 +115,12 @@ func SetupRoutes(app *fiber.App) {
 	})
 
 	// Configura todas as rotas
-	SetupMotoristaRoutes(api)
-	SetupCorridaRoutes(api, corridaService)
-	NotificacaoCorridaRoutes(api)
+	SetupMotoristaRoutes(api, motoristaRepo, registroDisponibilidade)
+	SetupCorridaRoutes(api, corridaService, idempotenciaStore)
+	NotificacaoCorridaRoutes(api, idempotenciaStore, retencaoNotificacoes, estatisticasMotoristas)
+	SetupTarifaDinamicaRoutes(api, tarifaDinamica)
+	SetupZonaRoutes(api, zonaService)
+	SetupFilaPontoRoutes(api, filasPonto)
+	SetupNotificadorRoutes(api, notificador, caixaEntrada)
+	SetupWebhookRoutes(api, webhookService)
 }
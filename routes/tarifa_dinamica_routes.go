@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"taxi-service/controllers"
+	"taxi-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupTarifaDinamicaRoutes configura a consulta da tarifa dinâmica e os limites por zona
+func SetupTarifaDinamicaRoutes(api fiber.Router, tarifaDinamica *services.TarifaDinamicaService) {
+	tarifaDinamicaController := controllers.NewTarifaDinamicaController(tarifaDinamica)
+
+	apiGroup := api.Group("/api")
+	apiGroup.Get("/tarifa-dinamica", tarifaDinamicaController.ConsultarPosicao) // Multiplicador no ponto de embarque
+
+	zonas := apiGroup.Group("/admin/tarifa-dinamica/zonas")
+	zonas.Get("/:zona", tarifaDinamicaController.ConsultarZona)           // Demanda, oferta e limite da zona
+	zonas.Put("/:zona/limite", tarifaDinamicaController.DefinirLimite)    // Limitar ou desativar
+	zonas.Delete("/:zona/limite", tarifaDinamicaController.RemoverLimite) // Voltar ao padrão
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"taxi-service/controllers"
+	"taxi-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupWebhookRoutes configura as assinaturas de webhook e o reenvio das entregas com falha
+func SetupWebhookRoutes(api fiber.Router, webhookService *services.WebhookService) {
+	webhookController := controllers.NewWebhookController(webhookService)
+
+	webhooks := api.Group("/api/admin/webhooks")
+	webhooks.Get("/", webhookController.ListarAssinaturas)
+	webhooks.Post("/", webhookController.CriarAssinatura)
+	webhooks.Get("/falhas", webhookController.ListarFalhas)                // Entregas que esgotaram as tentativas
+	webhooks.Post("/falhas/:id/reenviar", webhookController.ReenviarFalha) // Nova tentativa manual
+	webhooks.Get("/:id", webhookController.BuscarAssinatura)
+	webhooks.Put("/:id", webhookController.AtualizarAssinatura)
+	webhooks.Delete("/:id", webhookController.DeletarAssinatura)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"taxi-service/controllers"
+	"taxi-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupZonaRoutes configura o cadastro das zonas geográficas e a consulta por posição
+func SetupZonaRoutes(api fiber.Router, zonaService *services.ZonaService) {
+	zonaController := controllers.NewZonaController(zonaService)
+
+	apiGroup := api.Group("/api")
+	apiGroup.Get("/zonas/localizar", zonaController.Localizar) // Zonas que contêm a posição
+
+	zonas := apiGroup.Group("/admin/zonas")
+	zonas.Get("/", zonaController.ListarZonas)
+	zonas.Post("/", zonaController.CriarZona)
+	zonas.Get("/geojson", zonaController.ExportarGeoJSON)  // FeatureCollection com todas as zonas
+	zonas.Post("/geojson", zonaController.ImportarGeoJSON) // Cadastra as features como novas zonas
+	zonas.Get("/:id", zonaController.BuscarZona)
+	zonas.Put("/:id", zonaController.AtualizarZona)
+	zonas.Delete("/:id", zonaController.DeletarZona)
+}
@@ -3,7 +3,6 @@ package services
 import (
 	"taxi-service/models"
 	"time"
-	"strconv"
 	"errors"
 	"encoding/json"
 	"os"
@@ -16,39 +15,157 @@ var corridas []models.Corrida
 
 
 
+// TipoEventoCorrida identifica uma mudança no ciclo de vida de uma corrida
+type TipoEventoCorrida string
+
+const (
+	EventoCorridaCriada     TipoEventoCorrida = "corrida_criada"
+	EventoCorridaAceita     TipoEventoCorrida = "corrida_aceita"
+	EventoCorridaEncerrada  TipoEventoCorrida = "corrida_encerrada"  // finalizada ou cancelada
+	EventoMotoristaChegando TipoEventoCorrida = "motorista_chegando" // motorista perto do embarque
+	EventoCorridaAtrasada   TipoEventoCorrida = "corrida_atrasada"   // tempo estimado ultrapassado
+)
+
+// EventoCorrida é entregue aos ouvintes com uma cópia da corrida após a mudança
+type EventoCorrida struct {
+	Tipo       TipoEventoCorrida
+	Corrida    models.Corrida
+	ETAMinutos int // previsão: até o embarque (aceita, chegando) ou até o destino (atrasada); 0 se desconhecida
+}
+
+// DistanciaChegadaKm é a distância do embarque em que o passageiro é avisado da chegada do motorista
+const DistanciaChegadaKm = 0.5
+
+// Erros de corrida que os chamadores precisam distinguir (as mensagens seguem o ID da corrida)
+var (
+	ErrCorridaNaoEncontrada = errors.New("não encontrada")
+	ErrCorridaIndisponivel  = errors.New("não está mais procurando por motorista")
+	ErrCategoriaInvalida    = errors.New("categoria de veículo inválida")
+)
+
+// OuvinteCorrida reage aos eventos publicados pelo CorridaService
+type OuvinteCorrida func(evento EventoCorrida)
+
+// PrecificadorCorrida completa a precificação de uma nova corrida antes de ela ser registrada
+// (ex.: zona e multiplicador da tarifa dinâmica)
+type PrecificadorCorrida interface {
+	Precificar(corrida *models.Corrida)
+}
+
+// ValidadorEmbarque recusa pontos de embarque não atendidos (ex.: fora da área de atendimento)
+type ValidadorEmbarque interface {
+	ValidarEmbarque(lat, lng float64) error
+}
+
 // CorridaService gerencia a lógica de negócio das corridas.
 type CorridaService struct {
-	corridas map[int]*models.Corrida
-	mutex    sync.RWMutex
-	nextID   int
+	corridas       map[int]*models.Corrida
+	mutex          sync.RWMutex
+	nextID         int
+	elegibilidade  ElegibilidadeService
+	ouvintes       []OuvinteCorrida
+	precificador   PrecificadorCorrida
+	validador      ValidadorEmbarque
+	envioRecibo    EnvioRecibo
+	agora          func() time.Time // relógio do taxímetro e do monitoramento de prazos
+	chegadaAvisada map[int]bool     // corridas cujo passageiro já foi avisado da chegada do motorista
 }
 
 // NewCorridaService cria uma nova instância de CorridaService.
-func NewCorridaService() *CorridaService {
+func NewCorridaService(elegibilidade ElegibilidadeService) *CorridaService {
 	service := &CorridaService{
-		corridas: make(map[int]*models.Corrida),
-		nextID:   1,
+		corridas:       make(map[int]*models.Corrida),
+		nextID:         1,
+		elegibilidade:  elegibilidade,
+		agora:          time.Now,
+		chegadaAvisada: make(map[int]bool),
 	}
 	// Inicia o monitoramento em background
 	go service.MonitorarCorridasAtivas()
 	return service
 }
 
+// DefinirPrecificador define quem precifica as novas corridas
+func (s *CorridaService) DefinirPrecificador(precificador PrecificadorCorrida) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.precificador = precificador
+}
+
+// DefinirValidadorEmbarque define quem valida o ponto de embarque das novas corridas
+func (s *CorridaService) DefinirValidadorEmbarque(validador ValidadorEmbarque) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.validador = validador
+}
+
+// RegistrarOuvinte inscreve uma função para receber os eventos das corridas.
+// Os ouvintes são chamados depois que o mutex é liberado, na ordem de registro.
+func (s *CorridaService) RegistrarOuvinte(ouvinte OuvinteCorrida) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ouvintes = append(s.ouvintes, ouvinte)
+}
+
+// publicarPendentes entrega aos ouvintes os eventos acumulados durante uma operação.
+// Deve ser adiada antes de travar o mutex, para rodar depois do Unlock adiado:
+//
+//	var eventos []EventoCorrida
+//	defer s.publicarPendentes(&eventos)
+//	s.mutex.Lock()
+//	defer s.mutex.Unlock()
+func (s *CorridaService) publicarPendentes(eventos *[]EventoCorrida) {
+	if len(*eventos) == 0 {
+		return
+	}
+
+	s.mutex.RLock()
+	ouvintes := append([]OuvinteCorrida(nil), s.ouvintes...)
+	s.mutex.RUnlock()
+
+	for _, evento := range *eventos {
+		for _, ouvinte := range ouvintes {
+			ouvinte(evento)
+		}
+	}
+}
+
 // CriarNovaCorrida cria uma nova corrida e a prepara para ser aceita.
+// Após o registro, os ouvintes recebem EventoCorridaCriada (ex.: despacho de ofertas).
 func (s *CorridaService) CriarNovaCorrida(corridaInput models.Corrida) (*models.Corrida, error) {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	corrida := &corridaInput
+	if !corrida.Categoria.OuPadrao().Valida() {
+		return nil, fmt.Errorf("%w: %q", ErrCategoriaInvalida, corrida.Categoria)
+	}
+	corrida.Categoria = corrida.Categoria.OuPadrao()
+	if s.validador != nil {
+		if err := s.validador.ValidarEmbarque(corrida.OrigemLat, corrida.OrigemLng); err != nil {
+			return nil, err
+		}
+	}
+	corrida.MultiplicadorDinamico = 1
+	if s.precificador != nil {
+		s.precificador.Precificar(corrida)
+	}
 	corrida.ID = s.nextID
 	s.nextID++
 	corrida.Status = models.StatusProcurandoMotorista
 	corrida.DataInicio = time.Now()
+	corrida.Versao = 1
 	// Em um sistema real, o tempo estimado seria calculado com base na distância, trânsito, etc.
 	// Para este exemplo, vamos fixar em 1 minuto para facilitar os testes.
 	corrida.TempoEstimado = 1 // minutos
 
 	s.corridas[corrida.ID] = corrida
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaCriada, Corrida: *corrida})
 
 	return corrida, nil
 }
@@ -60,79 +177,148 @@ func (s *CorridaService) GetCorridaPorID(id int) (*models.Corrida, error) {
 
 	corrida, exists := s.corridas[id]
 	if !exists {
-		return nil, fmt.Errorf("corrida com ID %d não encontrada", id)
+		return nil, fmt.Errorf("corrida com ID %d %w", id, ErrCorridaNaoEncontrada)
 	}
 	return corrida, nil
 }
 
 // AceitarCorrida permite que um motorista aceite uma corrida.
-func (s *CorridaService) AceitarCorrida(corridaID int, motoristaID int) error {
+func (s *CorridaService) AceitarCorrida(corridaID int, motoristaID models.MotoristaID, versaoEsperada int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	corrida, exists := s.corridas[corridaID]
 	if !exists {
-		return fmt.Errorf("corrida com ID %d não encontrada", corridaID)
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	if err := verificarVersao(corrida.Versao, versaoEsperada); err != nil {
+		return err
 	}
 
 	if corrida.Status != models.StatusProcurandoMotorista {
-		return fmt.Errorf("corrida %d não está mais procurando por motorista", corridaID)
+		return fmt.Errorf("corrida %d %w", corridaID, ErrCorridaIndisponivel)
+	}
+
+	if s.motoristaEmCorridaAtiva(motoristaID) {
+		return &ErroElegibilidade{
+			Codigo:   CodigoMotoristaOcupado,
+			Mensagem: "motorista já está em outra corrida",
+		}
+	}
+
+	// Verifica cadastro, CNH, disponibilidade e categoria antes de marcar o motorista como
+	// ocupado, para que uma recusa não grave nada no cadastro
+	motorista, err := s.elegibilidade.VerificarElegibilidade(motoristaID)
+	if err != nil {
+		return err
+	}
+	if err := VerificarCategoria(motorista, corrida.Categoria); err != nil {
+		return err
+	}
+	if _, err := s.elegibilidade.ReservarMotorista(motoristaID); err != nil {
+		return err
 	}
 
 	corrida.Status = models.StatusMotoristaEncontrado
 	corrida.MotoristaID = motoristaID
-	fmt.Printf("Corrida %d: Motorista %d aceitou a corrida.\n", corrida.ID, corrida.MotoristaID)
+	corrida.Versao++
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaAceita, Corrida: *corrida, ETAMinutos: minutosAteEmbarque(*corrida)})
+	fmt.Printf("Corrida %d: Motorista %s aceitou a corrida.\n", corrida.ID, corrida.MotoristaID)
 
 	return nil
 }
 
 // AtualizarPosicao atualiza a localização do motorista para uma corrida específica.
-func (s *CorridaService) AtualizarPosicao(corridaID int, lat, lng float64) error {
+// Em corridas de rua em andamento, a posição também alimenta o taxímetro.
+func (s *CorridaService) AtualizarPosicao(corridaID int, lat, lng float64, versaoEsperada int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	corrida, exists := s.corridas[corridaID]
 	if !exists {
-		return fmt.Errorf("corrida com ID %d não encontrada", corridaID)
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	if err := verificarVersao(corrida.Versao, versaoEsperada); err != nil {
+		return err
 	}
 
 	corrida.MotoristaLat = lat
 	corrida.MotoristaLng = lng
+	if corrida.DeRua && corrida.DataFim == nil {
+		registrarNoTaximetro(corrida, lat, lng, s.agora())
+	}
+	corrida.Versao++
+	if evento, chegando := s.verificarChegada(corrida); chegando {
+		eventos = append(eventos, evento)
+	}
 	return nil
 }
 
 // CancelarCorrida cancela uma corrida que está em andamento.
-func (s *CorridaService) CancelarCorrida(corridaID int) error {
+func (s *CorridaService) CancelarCorrida(corridaID int, versaoEsperada int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	corrida, exists := s.corridas[corridaID]
 	if !exists {
-		return fmt.Errorf("corrida com ID %d não encontrada", corridaID)
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	if err := verificarVersao(corrida.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
+	if corridaEncerrada(corrida) {
+		return fmt.Errorf("corrida %d %w", corridaID, ErrCorridaIndisponivel)
 	}
 
 	corrida.Status = models.StatusCanceladaPeloUsuario
 	now := time.Now()
     corrida.DataFim = &now
+	corrida.Versao++
+	s.liberarMotorista(corrida)
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: *corrida})
 	fmt.Printf("Corrida %d: Cancelada pelo usuário.\n", corrida.ID)
 
 	return nil
 }
 
 // FinalizarCorrida finaliza uma corrida, aplicando a lógica de tempo.
-func (s *CorridaService) FinalizarCorrida(corridaID int) error {
+func (s *CorridaService) FinalizarCorrida(corridaID int, versaoEsperada int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	corrida, exists := s.corridas[corridaID]
 	if !exists {
-		return fmt.Errorf("corrida com ID %d não encontrada", corridaID)
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	if err := verificarVersao(corrida.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
+	if corridaEncerrada(corrida) {
+		return fmt.Errorf("corrida %d %w", corridaID, ErrCorridaIndisponivel)
 	}
 
 	duracaoReal := time.Since(corrida.DataInicio)
 	duracaoEstimada := time.Duration(corrida.TempoEstimado) * time.Minute
 
-	if duracaoReal < duracaoEstimada {
+	if corrida.DeRua {
+		// Corridas de rua não têm tempo estimado: o valor final é o do taxímetro
+		corrida.Status = models.StatusFinalizada
+		fmt.Printf("Corrida %d: Corrida de rua finalizada. Valor: R$ %.2f.\n", corrida.ID, corrida.Preco)
+	} else if duracaoReal < duracaoEstimada {
 		corrida.Status = models.StatusConcluidaAntecedencia
 		corrida.BonusAplicado = true
 		fmt.Printf("Corrida %d: Finalizada com antecedência! Bônus aplicado.\n", corrida.ID)
@@ -147,8 +333,11 @@ func (s *CorridaService) FinalizarCorrida(corridaID int) error {
 		fmt.Printf("Corrida %d: Finalizada no tempo previsto.\n", corrida.ID)
 	}
 
-	now := time.Now()
+	now := s.agora()
     corrida.DataFim = &now
+	corrida.Versao++
+	s.liberarMotorista(corrida)
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: *corrida})
 
 	return nil
 }
@@ -160,27 +349,42 @@ func (s *CorridaService) MonitorarCorridasAtivas() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		s.mutex.Lock()
-		for _, corrida := range s.corridas {
-			// Apenas verifica corridas que estão em andamento
-			if corrida.Status == models.StatusMotoristaEncontrado || corrida.Status == models.StatusCorridaIniciada {
-				duracaoReal := time.Since(corrida.DataInicio)
-				duracaoEstimada := time.Duration(corrida.TempoEstimado) * time.Minute
-
-				// Lógica para cancelamento automático
-				if duracaoReal > duracaoEstimada+time.Duration(15)*time.Minute {
-					corrida.Status = models.StatusCanceladaPorExcessoTempo
-					now := time.Now()
-    				corrida.DataFim = &now
-					fmt.Printf("Corrida %d: Cancelada automaticamente por excesso de tempo.\n", corrida.ID)
-				} else if duracaoReal > duracaoEstimada && corrida.Status != models.StatusAtrasado {
-					// Lógica para marcar como atrasado
-					corrida.Status = models.StatusAtrasado
-					fmt.Printf("Corrida %d: Marcada como atrasada.\n", corrida.ID)
-				}
+		s.VerificarPrazos()
+	}
+}
+
+// VerificarPrazos marca como atrasadas as corridas que passaram do tempo estimado e cancela
+// as que passaram da tolerância, publicando EventoCorridaAtrasada e EventoCorridaEncerrada.
+func (s *CorridaService) VerificarPrazos() {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, corrida := range s.corridas {
+		// Apenas verifica corridas que estão em andamento
+		if corrida.Status == models.StatusMotoristaEncontrado || corrida.Status == models.StatusCorridaIniciada || corrida.Status == models.StatusAtrasado {
+			duracaoReal := s.agora().Sub(corrida.DataInicio)
+			duracaoEstimada := time.Duration(corrida.TempoEstimado) * time.Minute
+
+			// Lógica para cancelamento automático
+			if duracaoReal > duracaoEstimada+time.Duration(15)*time.Minute {
+				corrida.Status = models.StatusCanceladaPorExcessoTempo
+				now := s.agora()
+				corrida.DataFim = &now
+				corrida.Versao++
+				s.liberarMotorista(corrida)
+				eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: *corrida})
+				fmt.Printf("Corrida %d: Cancelada automaticamente por excesso de tempo.\n", corrida.ID)
+			} else if duracaoReal > duracaoEstimada && corrida.Status != models.StatusAtrasado {
+				// Lógica para marcar como atrasado; a nova previsão considera o trecho que falta
+				eta := minutosRestantes(*corrida)
+				corrida.Status = models.StatusAtrasado
+				corrida.Versao++
+				eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaAtrasada, Corrida: *corrida, ETAMinutos: eta})
+				fmt.Printf("Corrida %d: Marcada como atrasada.\n", corrida.ID)
 			}
 		}
-		s.mutex.Unlock()
 	}
 }
 
@@ -216,25 +420,24 @@ func GetCorridas() []models.Corrida {
 	return corridas
 }
 
-// ALTERADO: A função agora aceita o ID do motorista como string para alinhar com o modelo e o controller.
-func (s *CorridaService) CancelarCorridaPeloMotorista(corridaID int, motoristaIDStr string) error {
+// CancelarCorridaPeloMotorista cancela a corrida a pedido do motorista responsável por ela.
+func (s *CorridaService) CancelarCorridaPeloMotorista(corridaID int, motoristaID models.MotoristaID, versaoEsperada int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Converte o ID string do motorista para int para comparação,
-	// já que o campo na struct Corrida é int.
-	motoristaID, err := strconv.Atoi(motoristaIDStr)
-	if err != nil {
-		return fmt.Errorf("ID do motorista inválido: '%s'", motoristaIDStr)
-	}
-
 	corrida, exists := s.corridas[corridaID]
 	if !exists {
-		return fmt.Errorf("corrida com ID %d não encontrada", corridaID)
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
 	}
 
 	if corrida.MotoristaID != motoristaID {
-		return fmt.Errorf("motorista %d não tem permissão para cancelar a corrida %d", motoristaID, corridaID)
+		return fmt.Errorf("motorista %s não tem permissão para cancelar a corrida %d", motoristaID, corridaID)
+	}
+
+	if err := verificarVersao(corrida.Versao, versaoEsperada); err != nil {
+		return err
 	}
 
 	switch corrida.Status {
@@ -250,7 +453,169 @@ func (s *CorridaService) CancelarCorridaPeloMotorista(corridaID int, motoristaID
 	corrida.Status = models.StatusCanceladaPeloMotorista
 	now := time.Now()
 	corrida.DataFim = &now
-	fmt.Printf("Corrida %d: Cancelada pelo motorista %d.\n", corrida.ID, motoristaID)
+	corrida.Versao++
+	s.liberarMotorista(corrida)
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: *corrida})
+	fmt.Printf("Corrida %d: Cancelada pelo motorista %s.\n", corrida.ID, motoristaID)
+
+	return nil
+}
+
+// EncerrarSemMotorista cancela a corrida cuja busca por motorista terminou sem aceite. Só
+// corridas ainda procurando motorista mudam; os ouvintes recebem EventoCorridaEncerrada
+// (ex.: aviso ao passageiro).
+func (s *CorridaService) EncerrarSemMotorista(corridaID int) error {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	corrida, exists := s.corridas[corridaID]
+	if !exists {
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+	if corrida.Status != models.StatusProcurandoMotorista {
+		return fmt.Errorf("corrida %d %w", corridaID, ErrCorridaIndisponivel)
+	}
+
+	corrida.Status = models.StatusCanceladaSemMotorista
+	now := s.agora()
+	corrida.DataFim = &now
+	corrida.Versao++
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: *corrida})
+	fmt.Printf("Corrida %d: Cancelada por falta de motorista.\n", corrida.ID)
+
+	return nil
+}
 
+// RegistrarOferta adiciona uma oferta ao histórico da corrida.
+// O histórico não altera a versão, pois não muda o estado da corrida.
+func (s *CorridaService) RegistrarOferta(corridaID int, oferta models.OfertaCorrida) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	corrida, exists := s.corridas[corridaID]
+	if !exists {
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	corrida.Ofertas = append(corrida.Ofertas, oferta)
 	return nil
-}
\ No newline at end of file
+}
+
+// AtualizarOferta registra no histórico a resposta ou a expiração de uma oferta.
+func (s *CorridaService) AtualizarOferta(corridaID int, notificacaoID uint, status models.NotificacaoStatus, em time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	corrida, exists := s.corridas[corridaID]
+	if !exists {
+		return fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+
+	for i := range corrida.Ofertas {
+		if corrida.Ofertas[i].NotificacaoID == notificacaoID {
+			corrida.Ofertas[i].Status = status
+			corrida.Ofertas[i].RespondidaEm = &em
+			return nil
+		}
+	}
+	return fmt.Errorf("oferta %d não encontrada na corrida %d", notificacaoID, corridaID)
+}
+
+// motoristaEmCorridaAtiva verifica se o motorista já tem uma corrida não encerrada.
+// Deve ser chamada com o mutex travado.
+func (s *CorridaService) motoristaEmCorridaAtiva(motoristaID models.MotoristaID) bool {
+	for _, corrida := range s.corridas {
+		if corrida.MotoristaID != motoristaID || corrida.DataFim != nil {
+			continue
+		}
+		switch corrida.Status {
+		case models.StatusMotoristaEncontrado,
+			models.StatusCorridaIniciada,
+			models.StatusEmAndamento,
+			models.StatusAtrasado:
+			return true
+		}
+	}
+	return false
+}
+
+// corridaEncerrada indica se a corrida já foi concluída ou cancelada. Corridas finalizadas
+// com atraso mantêm o status atrasado, por isso a data de fim também conta.
+func corridaEncerrada(corrida *models.Corrida) bool {
+	if corrida.DataFim != nil {
+		return true
+	}
+	switch corrida.Status {
+	case models.StatusFinalizada,
+		models.StatusConcluidaAntecedencia,
+		models.StatusConcluidaNoTempo,
+		models.StatusCanceladaPorExcessoTempo,
+		models.StatusCanceladaPeloUsuario,
+		models.StatusCanceladaPeloMotorista,
+		models.StatusCanceladaSemMotorista:
+		return true
+	}
+	return false
+}
+
+// verificarChegada gera EventoMotoristaChegando na primeira posição do motorista a até
+// DistanciaChegadaKm do embarque. Deve ser chamada com o mutex travado.
+func (s *CorridaService) verificarChegada(corrida *models.Corrida) (EventoCorrida, bool) {
+	if corrida.Status != models.StatusMotoristaEncontrado || s.chegadaAvisada[corrida.ID] {
+		return EventoCorrida{}, false
+	}
+	if !CoordenadasValidas(corrida.MotoristaLat, corrida.MotoristaLng) || !CoordenadasValidas(corrida.OrigemLat, corrida.OrigemLng) {
+		return EventoCorrida{}, false
+	}
+
+	distancia := DistanciaKm(corrida.MotoristaLat, corrida.MotoristaLng, corrida.OrigemLat, corrida.OrigemLng)
+	if distancia > DistanciaChegadaKm {
+		return EventoCorrida{}, false
+	}
+	s.chegadaAvisada[corrida.ID] = true
+	return EventoCorrida{Tipo: EventoMotoristaChegando, Corrida: *corrida, ETAMinutos: minutosDeViagem(distancia)}, true
+}
+
+// minutosAteEmbarque estima quanto o motorista leva até o embarque. Retorna 0 sem coordenadas.
+func minutosAteEmbarque(corrida models.Corrida) int {
+	if !CoordenadasValidas(corrida.MotoristaLat, corrida.MotoristaLng) || !CoordenadasValidas(corrida.OrigemLat, corrida.OrigemLng) {
+		return 0
+	}
+	return minutosDeViagem(DistanciaKm(corrida.MotoristaLat, corrida.MotoristaLng, corrida.OrigemLat, corrida.OrigemLng))
+}
+
+// minutosRestantes estima quanto falta para o fim da corrida pela posição do motorista: até o
+// embarque e dele ao destino enquanto o passageiro não embarcou. Retorna 0 sem coordenadas.
+func minutosRestantes(corrida models.Corrida) int {
+	if !CoordenadasValidas(corrida.MotoristaLat, corrida.MotoristaLng) {
+		return 0
+	}
+
+	var distancia float64
+	pontoLat, pontoLng := corrida.MotoristaLat, corrida.MotoristaLng
+	if corrida.Status == models.StatusMotoristaEncontrado {
+		if !CoordenadasValidas(corrida.OrigemLat, corrida.OrigemLng) {
+			return 0
+		}
+		distancia += DistanciaKm(pontoLat, pontoLng, corrida.OrigemLat, corrida.OrigemLng)
+		pontoLat, pontoLng = corrida.OrigemLat, corrida.OrigemLng
+	}
+	if CoordenadasValidas(corrida.DestinoLat, corrida.DestinoLng) {
+		distancia += DistanciaKm(pontoLat, pontoLng, corrida.DestinoLat, corrida.DestinoLng)
+	} else if corrida.Status != models.StatusMotoristaEncontrado {
+		return 0
+	}
+	return minutosDeViagem(distancia)
+}
+
+// liberarMotorista devolve o motorista da corrida ao status disponível.
+func (s *CorridaService) liberarMotorista(corrida *models.Corrida) {
+	if corrida.MotoristaID == "" {
+		return
+	}
+	if err := s.elegibilidade.LiberarMotorista(corrida.MotoristaID); err != nil {
+		fmt.Printf("Corrida %d: erro ao liberar motorista %s: %v\n", corrida.ID, corrida.MotoristaID, err)
+	}
+}
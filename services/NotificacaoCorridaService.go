@@ -1,153 +1,382 @@
 package services
 
 import (
-    "encoding/json"
+    "encoding/base64"
     "errors"
-    "os"
-    "path/filepath"
+    "fmt"
+    "strconv"
+    "strings"
+    "sync"
     "taxi-service/models"
+    "taxi-service/repositories"
     "time"
 )
 
-const notificacaoCorridaFile = "./data/notificacao_corrida.json"
+// OuvinteNotificacao é chamado depois que uma notificação sai do status pendente
+type OuvinteNotificacao func(notificacao models.NotificacaoCorrida)
 
-// ============= FUNÇÕES AUXILIARES DE ARQUIVO =============
+var (
+    ouvintesNotificacao      []OuvinteNotificacao
+    ouvintesNotificacaoMutex sync.RWMutex
 
-func readNotificacoesCorrida() ([]models.NotificacaoCorrida, error) {
-    
-    // Criar diretório se não existir
-    dataDir := filepath.Dir(notificacaoCorridaFile)
-    if err := os.MkdirAll(dataDir, 0755); err != nil {
-        return nil, err
+    repositorioNotificacoes      repositories.NotificacaoRepository
+    repositorioNotificacoesMutex sync.RWMutex
+
+    // aceitesMutex protege aceitesEmAndamento, para que dois motoristas aceitando ofertas da
+    // mesma corrida ao mesmo tempo não ganhem ambos. Nunca é mantido durante chamadas a
+    // outros serviços ou aos ouvintes.
+    aceitesMutex sync.Mutex
+    // aceitesEmAndamento liga cada corrida com um aceite sendo atribuído à oferta aceita
+    aceitesEmAndamento = make(map[uint]uint)
+
+    atribuidorCorrida      AtribuidorCorrida
+    atribuidorCorridaMutex sync.RWMutex
+
+    expiradorOfertas      *ExpiradorOfertas
+    expiradorOfertasMutex sync.Mutex
+
+    configTTLOfertas      = ConfigTTLOfertas{Padrao: TTLOfertaPadrao}
+    configTTLOfertasMutex sync.RWMutex
+)
+
+// DefinirConfigTTLOfertas define como o tempo de resposta das novas ofertas é resolvido
+func DefinirConfigTTLOfertas(config ConfigTTLOfertas) {
+    configTTLOfertasMutex.Lock()
+    defer configTTLOfertasMutex.Unlock()
+
+    configTTLOfertas = config
+}
+
+// ttlDaOferta retorna o tempo de resposta da oferta: o informado nela, se houver, ou o
+// resolvido pela configuração para a cidade, a categoria e o horário de criação
+func ttlDaOferta(notificacao models.NotificacaoCorrida, em time.Time) (time.Duration, error) {
+    if notificacao.TTLSegundos != 0 {
+        ttl := time.Duration(notificacao.TTLSegundos) * time.Second
+        if ttl < TTLOfertaMinimo || ttl > TTLOfertaMaximo {
+            return 0, ErrTTLOfertaInvalido
+        }
+        return ttl, nil
+    }
+
+    configTTLOfertasMutex.RLock()
+    config := configTTLOfertas
+    configTTLOfertasMutex.RUnlock()
+    return config.TTLPara(notificacao.Cidade, notificacao.Categoria, em), nil
+}
+
+// DefinirRepositorioNotificacoes define onde as notificações são guardadas
+func DefinirRepositorioNotificacoes(repo repositories.NotificacaoRepository) {
+    repositorioNotificacoesMutex.Lock()
+    defer repositorioNotificacoesMutex.Unlock()
+
+    repositorioNotificacoes = repo
+}
+
+// notificacoesRepo retorna o repositório definido ou, se nenhum foi definido, o persistido
+// em ./data/notificacao_corrida.json
+func notificacoesRepo() repositories.NotificacaoRepository {
+    repositorioNotificacoesMutex.RLock()
+    repo := repositorioNotificacoes
+    repositorioNotificacoesMutex.RUnlock()
+    if repo != nil {
+        return repo
     }
 
-    file, err := os.Open(notificacaoCorridaFile)
+    repositorioNotificacoesMutex.Lock()
+    defer repositorioNotificacoesMutex.Unlock()
+    if repositorioNotificacoes == nil {
+        repositorioNotificacoes = repositories.NewJSONNotificacaoRepository()
+    }
+    return repositorioNotificacoes
+}
+
+// AtribuidorCorrida entrega a corrida ao motorista que aceitou a oferta (ex.: CorridaService)
+type AtribuidorCorrida interface {
+    AceitarCorrida(corridaID int, motoristaID models.MotoristaID, versaoEsperada int) error
+}
+
+// RegistrarAtribuidorCorrida define quem atribui as corridas quando uma oferta é aceita
+func RegistrarAtribuidorCorrida(atribuidor AtribuidorCorrida) {
+    atribuidorCorridaMutex.Lock()
+    defer atribuidorCorridaMutex.Unlock()
+
+    atribuidorCorrida = atribuidor
+}
+
+// ErrCorridaJaAceita indica que outra oferta da mesma corrida já foi aceita
+var ErrCorridaJaAceita = errors.New("ride already accepted by another driver")
+
+// Erros do repositório de notificações, reexportados para os controllers
+var (
+    ErrNotificacaoNaoEncontrada = repositories.ErrNotificacaoNaoEncontrada
+    ErrNotificacaoJaProcessada  = repositories.ErrStatusNotificacaoAlterado
+)
+
+// Erros da mudança manual de status (AlterarStatusNotificacao)
+var (
+    ErrStatusNotificacaoInvalido    = errors.New("invalid notificacao status")
+    ErrTransicaoNotificacaoInvalida = errors.New("invalid notificacao status transition")
+    ErrAutoriaMudancaObrigatoria    = errors.New("por and motivo are required")
+)
+
+// Motivos registrados no histórico das mudanças feitas pelo próprio serviço
+const (
+    motivoAceiteMotorista   = "aceita pelo motorista"
+    motivoRecusaMotorista   = "recusada pelo motorista"
+    motivoPrazoVencido      = "prazo de resposta vencido"
+    motivoOutraOfertaAceita = "outra oferta da corrida foi aceita"
+    motivoCorridaAtribuida  = "corrida já atribuída a outro motorista"
+    motivoBuscaEncerrada    = "busca por motorista encerrada sem aceite"
+)
+
+// autorMotorista identifica no histórico as mudanças feitas pelo motorista da oferta
+func autorMotorista(motoristaID models.MotoristaID) string {
+    return "motorista:" + string(motoristaID)
+}
+
+// mudancaStatus monta a mudança gravada pelo repositório, que completa o status de origem
+func mudancaStatus(para models.NotificacaoStatus, por, motivo string, em time.Time) models.MudancaStatusNotificacao {
+    return models.MudancaStatusNotificacao{Para: para, Por: por, Motivo: motivo, Em: em}
+}
+
+// RegistrarOuvinteNotificacao inscreve uma função para saber quando ofertas são
+// aceitas, recusadas ou expiram (ex.: o despacho reoferta a corrida ao próximo motorista)
+func RegistrarOuvinteNotificacao(ouvinte OuvinteNotificacao) {
+    ouvintesNotificacaoMutex.Lock()
+    defer ouvintesNotificacaoMutex.Unlock()
+
+    ouvintesNotificacao = append(ouvintesNotificacao, ouvinte)
+}
+
+// publicarNotificacao entrega a notificação com o novo status aos ouvintes
+func publicarNotificacao(notificacao models.NotificacaoCorrida) {
+    ouvintesNotificacaoMutex.RLock()
+    ouvintes := append([]OuvinteNotificacao(nil), ouvintesNotificacao...)
+    ouvintesNotificacaoMutex.RUnlock()
+
+    for _, ouvinte := range ouvintes {
+        ouvinte(notificacao)
+    }
+}
+
+// publicarPendentesNotificacao entrega as notificações acumuladas durante uma operação.
+// Deve ser adiada antes de travar aceitesMutex, pois os ouvintes podem criar novas ofertas.
+func publicarPendentesNotificacao(notificacoes *[]models.NotificacaoCorrida) {
+    for _, notificacao := range *notificacoes {
+        publicarNotificacao(notificacao)
+    }
+}
+
+// somentePendente é o único status do qual uma oferta sai (ver models.NotificacaoStatus.PodeIrPara)
+var somentePendente = []models.NotificacaoStatus{models.NotificacaoPendente}
+
+// reservadaParaAceite indica se a oferta tem um aceite em andamento (deve ser chamada com
+// aceitesMutex travado)
+func reservadaParaAceite(notificacao models.NotificacaoCorrida) bool {
+    reservada, emAndamento := aceitesEmAndamento[notificacao.CorridaID]
+    return emAndamento && reservada == notificacao.ID
+}
+
+// expirarSeVencida marca a oferta pendente como expirada se o prazo já passou. A oferta com
+// aceite em andamento não expira: se o aceite falhar, liberarReserva a expira em seguida.
+// Retorna a oferta expirada e true quando foi este chamador que a expirou (deve ser chamada
+// com aceitesMutex travado).
+func expirarSeVencida(notificacao models.NotificacaoCorrida, agora time.Time) (models.NotificacaoCorrida, bool) {
+    if notificacao.Status != models.NotificacaoPendente || agora.Before(notificacao.ExpiraEm) || reservadaParaAceite(notificacao) {
+        return notificacao, false
+    }
+    mudanca := mudancaStatus(models.NotificacaoExpirada, models.AutorSistema, motivoPrazoVencido, agora)
+    expirada, err := notificacoesRepo().TrocarStatus(notificacao.ID, somentePendente, mudanca)
     if err != nil {
-        if os.IsNotExist(err) {
-            return []models.NotificacaoCorrida{}, nil
-        }
-        return nil, err
+        return notificacao, false
     }
-    defer file.Close()
+    return expirada, true
+}
+
+// expirarOferta é chamada pelo expirador quando o prazo de uma oferta vence
+func expirarOferta(notificacaoID uint) {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
 
-    var notificacoes []models.NotificacaoCorrida
-    err = json.NewDecoder(file).Decode(&notificacoes)
+    notificacao, err := notificacoesRepo().BuscarPorID(notificacaoID)
     if err != nil {
-        return nil, err
+        // Removida enquanto aguardava
+        return
+    }
+    if expirada, ok := expirarSeVencida(notificacao, time.Now()); ok {
+        publicar = append(publicar, expirada)
     }
-    
-    return notificacoes, nil
 }
 
-func writeNotificacoesCorrida(notificacoes []models.NotificacaoCorrida) error {
-    
-    // Garantir que o diretório existe antes de escrever
-    dataDir := filepath.Dir(notificacaoCorridaFile)
-    if err := os.MkdirAll(dataDir, 0755); err != nil {
-        return err
+// IniciarExpiracaoOfertas troca o expirador das ofertas por um novo com a precisão informada
+// e reagenda os prazos das ofertas pendentes gravadas, para que as criadas antes de um
+// reinício também expirem. Ofertas que venceram com o serviço parado expiram em seguida.
+func IniciarExpiracaoOfertas(precisao time.Duration) error {
+    // O novo expirador entra antes da leitura: ofertas criadas durante a reconstrução são
+    // agendadas nele e, se também forem lidas, apenas agendadas duas vezes
+    expirador := NewExpiradorOfertas(precisao, expirarOferta)
+    expiradorOfertasMutex.Lock()
+    if expiradorOfertas != nil {
+        expiradorOfertas.Parar()
     }
-    
-    data, err := json.MarshalIndent(notificacoes, "", "  ")
+    expiradorOfertas = expirador
+    expiradorOfertasMutex.Unlock()
+
+    notificacoes, err := notificacoesRepo().ListarTodas()
     if err != nil {
         return err
     }
-    
-    err = os.WriteFile(notificacaoCorridaFile, data, 0644)
-    if err != nil {
-        return err
+    for _, notificacao := range notificacoes {
+        if notificacao.Status == models.NotificacaoPendente {
+            expirador.Agendar(notificacao.ID, notificacao.ExpiraEm)
+        }
     }
-    
     return nil
 }
 
+// agendarExpiracao registra o prazo da oferta no expirador, criando um com a precisão
+// padrão se IniciarExpiracaoOfertas não foi chamada
+func agendarExpiracao(notificacao models.NotificacaoCorrida) {
+    expiradorOfertasMutex.Lock()
+    if expiradorOfertas == nil {
+        expiradorOfertas = NewExpiradorOfertas(PrecisaoExpiracaoPadrao, expirarOferta)
+    }
+    expirador := expiradorOfertas
+    expiradorOfertasMutex.Unlock()
+
+    expirador.Agendar(notificacao.ID, notificacao.ExpiraEm)
+}
+
 // ============= FUNÇÕES PRINCIPAIS DE SERVIÇO =============
 
 // ListNotificacoesCorrida - Lista todas as notificações
 func ListNotificacoesCorrida() ([]models.NotificacaoCorrida, error) {
-    return readNotificacoesCorrida()
+    return notificacoesRepo().ListarTodas()
 }
 
-// GetNotificacaoCorrida - Busca notificação por ID
-func GetNotificacaoCorrida(id uint) (models.NotificacaoCorrida, error) {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// Tamanho das páginas da listagem de notificações
+const (
+    LimitePaginaNotificacoesPadrao = 100
+    LimitePaginaNotificacoesMaximo = 500
+)
+
+// ErrCursorNotificacoesInvalido indica um cursor que não foi gerado pela listagem
+var ErrCursorNotificacoesInvalido = errors.New("invalid cursor")
+
+// FiltroNotificacoes seleciona as notificações por status, motorista, corrida e período de criação
+type FiltroNotificacoes = repositories.FiltroNotificacoes
+
+// PaginaNotificacoes é uma página da listagem; ProximoCursor fica vazio na última
+type PaginaNotificacoes struct {
+    Notificacoes  []models.NotificacaoCorrida `json:"notificacoes"`
+    ProximoCursor string                      `json:"proximo_cursor,omitempty"`
+}
+
+// ListarNotificacoes retorna uma página das notificações do filtro, em ordem de criação. O
+// cursor é o ProximoCursor da página anterior (vazio na primeira); como aponta para o último
+// ID entregue, novas notificações não deslocam as páginas seguintes. Limites fora do intervalo
+// aceito usam o padrão ou o máximo.
+func ListarNotificacoes(filtro FiltroNotificacoes, cursor string, limite int) (PaginaNotificacoes, error) {
+    aposID, err := lerCursorNotificacoes(cursor)
     if err != nil {
-        return models.NotificacaoCorrida{}, err
+        return PaginaNotificacoes{}, err
     }
-    
+    switch {
+    case limite <= 0:
+        limite = LimitePaginaNotificacoesPadrao
+    case limite > LimitePaginaNotificacoesMaximo:
+        limite = LimitePaginaNotificacoesMaximo
+    }
+
+    // Um item a mais indica se há próxima página
+    filtro.AposID = aposID
+    filtro.Limite = limite + 1
+    notificacoes, err := notificacoesRepo().Listar(filtro)
+    if err != nil {
+        return PaginaNotificacoes{}, err
+    }
+
+    pagina := PaginaNotificacoes{Notificacoes: notificacoes}
+    if len(notificacoes) > limite {
+        pagina.Notificacoes = notificacoes[:limite]
+        pagina.ProximoCursor = cursorNotificacoes(notificacoes[limite-1].ID)
+    }
+    return pagina, nil
+}
+
+// ContarNotificacoesPorStatus conta as notificações do filtro por status, sem paginação
+func ContarNotificacoesPorStatus(filtro FiltroNotificacoes) (map[models.NotificacaoStatus]int, int, error) {
+    filtro.AposID, filtro.Limite = 0, 0
+    notificacoes, err := notificacoesRepo().Listar(filtro)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    contagem := make(map[models.NotificacaoStatus]int)
     for _, notificacao := range notificacoes {
-        if notificacao.ID == id {
-            return notificacao, nil
-        }
+        contagem[notificacao.Status]++
     }
-    
-    return models.NotificacaoCorrida{}, errors.New("notificacao not found")
+    return contagem, len(notificacoes), nil
+}
+
+// cursorNotificacoes codifica o último ID entregue em um cursor opaco
+func cursorNotificacoes(id uint) string {
+    return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+// lerCursorNotificacoes retorna o ID a partir do qual a próxima página começa
+func lerCursorNotificacoes(cursor string) (uint, error) {
+    if cursor == "" {
+        return 0, nil
+    }
+    texto, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return 0, ErrCursorNotificacoesInvalido
+    }
+    id, err := strconv.ParseUint(string(texto), 10, 32)
+    if err != nil {
+        return 0, ErrCursorNotificacoesInvalido
+    }
+    return uint(id), nil
+}
+
+// GetNotificacaoCorrida - Busca notificação por ID
+func GetNotificacaoCorrida(id uint) (models.NotificacaoCorrida, error) {
+    return notificacoesRepo().BuscarPorID(id)
 }
 
 // CreateNotificacaoCorrida - Cria nova notificação para motorista
 func CreateNotificacaoCorrida(notificacao *models.NotificacaoCorrida) error {
-    notificacoes, err := readNotificacoesCorrida()
+    now := time.Now()
+    ttl, err := ttlDaOferta(*notificacao, now)
     if err != nil {
         return err
     }
 
-    // Atribuir novo ID (máximo + 1)
-    var maxID uint = 0
-    for _, n := range notificacoes {
-        if n.ID > maxID {
-            maxID = n.ID
-        }
-    }
-    notificacao.ID = maxID + 1
-
     // Definir valores padrão
-    now := time.Now()
     notificacao.Status = models.NotificacaoPendente
     notificacao.CreatedAt = now
     notificacao.UpdatedAt = now
-    notificacao.ExpiraEm = now.Add(20 * time.Second) // Expira em 20 segundos
-
-    // Adicionar nova notificação à lista
-    notificacoes = append(notificacoes, *notificacao)
+    notificacao.TTLSegundos = int(ttl / time.Second)
+    notificacao.ExpiraEm = now.Add(ttl)
 
-    if err := writeNotificacoesCorrida(notificacoes); err != nil {
+    // O repositório atribui o ID
+    if err := notificacoesRepo().Criar(notificacao); err != nil {
         return err
     }
 
-    // Iniciar rotina para expiração automática após 21 segundos
-    go func(id uint) {
-        time.Sleep(21 * time.Second) // 20s + 1s de margem
-
-        // Ler novamente as notificações
-        notificacoes, err := readNotificacoesCorrida()
-        if err != nil {
-            // Idealmente logar erro, mas estamos em goroutine
-            return
-        }
-
-        updated := false
-        now := time.Now()
-        for i, n := range notificacoes {
-            if n.ID == id && n.Status == models.NotificacaoPendente && now.After(n.ExpiraEm) {
-                notificacoes[i].Status = models.NotificacaoExpirada
-                notificacoes[i].UpdatedAt = now
-                updated = true
-                break
-            }
-        }
-
-        if updated {
-            _ = writeNotificacoesCorrida(notificacoes) // Ignorar erro por simplicidade
-        }
-    }(notificacao.ID)
+    // A oferta expira quando o prazo vence, sem resposta do motorista
+    agendarExpiracao(*notificacao)
 
     return nil
 }
 
 // GetNotificacoesPendentesParaMotorista - Busca notificações pendentes para um motorista específico
-func GetNotificacoesPendentesParaMotorista(motoristaID uint) ([]models.NotificacaoCorrida, error) {
+func GetNotificacoesPendentesParaMotorista(motoristaID models.MotoristaID) ([]models.NotificacaoCorrida, error) {
     
-    notificacoes, err := readNotificacoesCorrida()
+    notificacoes, err := notificacoesRepo().ListarPorMotorista(motoristaID)
     if err != nil {
         return nil, err
     }
@@ -156,160 +385,287 @@ func GetNotificacoesPendentesParaMotorista(motoristaID uint) ([]models.Notificac
     agora := time.Now()
     
     for _, notificacao := range notificacoes {
-        // Verificar se é para o motorista e está pendente
-        if notificacao.MotoristaID == motoristaID && notificacao.Status == models.NotificacaoPendente {
-            // Verificar se não expirou
-            if agora.Before(notificacao.ExpiraEm) {
-                notificacoesPendentes = append(notificacoesPendentes, notificacao)
-            } else {
-            }
+        // Pendente e ainda dentro do prazo
+        if notificacao.Status == models.NotificacaoPendente && agora.Before(notificacao.ExpiraEm) {
+            notificacoesPendentes = append(notificacoesPendentes, notificacao)
         }
     }
     
     return notificacoesPendentes, nil
 }
 
-// AceitarNotificacaoCorrida - Aceita uma notificação de corrida
-func AceitarNotificacaoCorrida(notificacaoID uint, motoristaID uint) error {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// AceitarNotificacaoCorrida - Aceita uma notificação de corrida.
+// O aceite atribui a corrida ao motorista pelo AtribuidorCorrida registrado e cancela as
+// demais ofertas pendentes da mesma corrida. Retorna ErrCorridaJaAceita para quem perde a
+// disputa: outra oferta já foi aceita, está sendo aceita ou a corrida já tem motorista.
+func AceitarNotificacaoCorrida(notificacaoID uint, motoristaID models.MotoristaID) error {
+    return aceitarOferta(notificacaoID, motoristaID, autorMotorista(motoristaID), motivoAceiteMotorista)
+}
+
+// aceitarOferta faz o aceite registrando no histórico da oferta quem aceitou e por quê
+func aceitarOferta(notificacaoID uint, motoristaID models.MotoristaID, por, motivo string) error {
+    notificacao, err := reservarAceite(notificacaoID, motoristaID)
     if err != nil {
         return err
     }
-    
-    for i, notificacao := range notificacoes {
-        if notificacao.ID == notificacaoID && notificacao.MotoristaID == motoristaID {
-            // Verificar se ainda está pendente e não expirou
-            if notificacao.Status != models.NotificacaoPendente {
-                return errors.New("notificacao already processed")
-            }
-            
-            if time.Now().After(notificacao.ExpiraEm) {
-                // Marcar como expirada
-                notificacoes[i].Status = models.NotificacaoExpirada
-                notificacoes[i].UpdatedAt = time.Now()
-                writeNotificacoesCorrida(notificacoes)
-                return errors.New("notificacao expired")
-            }
-            
-            // Aceitar a notificação
-            notificacoes[i].Status = models.NotificacaoAceita
-            notificacoes[i].UpdatedAt = time.Now()
-            
-            if err := writeNotificacoesCorrida(notificacoes); err != nil {
-                return err
-            }
-            
-            return nil
+
+    atribuidorCorridaMutex.RLock()
+    atribuidor := atribuidorCorrida
+    atribuidorCorridaMutex.RUnlock()
+
+    // A atribuição roda fora do aceitesMutex: os ouvintes da corrida aceita (ex.: o
+    // despacho) podem estar criando ofertas
+    if atribuidor != nil {
+        err := atribuidor.AceitarCorrida(int(notificacao.CorridaID), motoristaID, 0)
+        switch {
+        case err == nil:
+        case errors.Is(err, ErrCorridaNaoEncontrada):
+            // Oferta avulsa, criada sem corrida no CorridaService: vale só o aceite da oferta
+        case errors.Is(err, ErrCorridaIndisponivel):
+            concluirAceite(notificacao, mudancaStatus(models.NotificacaoCancelada, models.AutorSistema, motivoCorridaAtribuida, time.Now()))
+            return ErrCorridaJaAceita
+        default:
+            liberarReserva(notificacao)
+            return err
         }
     }
-    
-    return errors.New("notificacao not found")
+
+    return concluirAceite(notificacao, mudancaStatus(models.NotificacaoAceita, por, motivo, time.Now()))
 }
 
-// RecusarNotificacaoCorrida - Recusa uma notificação de corrida
-func RecusarNotificacaoCorrida(notificacaoID uint, motoristaID uint) error {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// reservarAceite valida a oferta e reserva a corrida para ela, barrando aceites simultâneos
+// de outras ofertas da mesma corrida, e a recusa e a expiração da própria oferta, até
+// concluirAceite ou liberarReserva
+func reservarAceite(notificacaoID uint, motoristaID models.MotoristaID) (models.NotificacaoCorrida, error) {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
+
+    repo := notificacoesRepo()
+    notificacao, err := repo.BuscarPorID(notificacaoID)
     if err != nil {
-        return err
+        return models.NotificacaoCorrida{}, err
     }
-    
-    for i, notificacao := range notificacoes {
-        if notificacao.ID == notificacaoID && notificacao.MotoristaID == motoristaID {
-            // Verificar se ainda está pendente
-            if notificacao.Status != models.NotificacaoPendente {
-                return errors.New("notificacao already processed")
-            }
-            
-            // Recusar a notificação
-            notificacoes[i].Status = models.NotificacaoRecusada
-            notificacoes[i].UpdatedAt = time.Now()
-            
-            if err := writeNotificacoesCorrida(notificacoes); err != nil {
-                return err
-            }
-            
-            return nil
+    if notificacao.MotoristaID != motoristaID {
+        return models.NotificacaoCorrida{}, ErrNotificacaoNaoEncontrada
+    }
+
+    if notificacao.Status == models.NotificacaoCancelada {
+        return notificacao, ErrCorridaJaAceita
+    }
+
+    // Verificar se ainda está pendente e não expirou
+    if notificacao.Status != models.NotificacaoPendente {
+        return notificacao, ErrNotificacaoJaProcessada
+    }
+
+    agora := time.Now()
+    if agora.After(notificacao.ExpiraEm) {
+        if expirada, ok := expirarSeVencida(notificacao, agora); ok {
+            publicar = append(publicar, expirada)
         }
+        return notificacao, errors.New("notificacao expired")
     }
-    
-    return errors.New("notificacao not found")
+
+    // Em ofertas simultâneas (broadcast) apenas o primeiro aceite leva a corrida
+    if _, emAndamento := aceitesEmAndamento[notificacao.CorridaID]; emAndamento {
+        return notificacao, ErrCorridaJaAceita
+    }
+    outras, err := repo.ListarPorCorrida(notificacao.CorridaID)
+    if err != nil {
+        return notificacao, err
+    }
+    for _, outra := range outras {
+        if outra.ID != notificacao.ID && outra.Status == models.NotificacaoAceita {
+            return notificacao, ErrCorridaJaAceita
+        }
+    }
+
+    aceitesEmAndamento[notificacao.CorridaID] = notificacao.ID
+    return notificacao, nil
 }
 
-// ExpirarNotificacoesVencidas - Marca como expiradas as notificações que passaram do tempo limite
-func ExpirarNotificacoesVencidas() error {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// concluirAceite grava o resultado da oferta reservada; quando aceita, as demais ofertas
+// pendentes da corrida são canceladas. Libera a reserva da corrida.
+func concluirAceite(reservada models.NotificacaoCorrida, mudanca models.MudancaStatusNotificacao) error {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    defer liberarReserva(reservada)
+
+    repo := notificacoesRepo()
+    agora := mudanca.Em
+    concluida, err := repo.TrocarStatus(reservada.ID, somentePendente, mudanca)
     if err != nil {
         return err
     }
-    
-    agora := time.Now()
-    expiradas := 0
-    
-    for i, notificacao := range notificacoes {
-        if notificacao.Status == models.NotificacaoPendente && agora.After(notificacao.ExpiraEm) {
-            notificacoes[i].Status = models.NotificacaoExpirada
-            notificacoes[i].UpdatedAt = agora
-            expiradas++
-        }
+    publicar = append(publicar, concluida)
+
+    if mudanca.Para != models.NotificacaoAceita {
+        return nil
     }
-    
-    if expiradas > 0 {
-        if err := writeNotificacoesCorrida(notificacoes); err != nil {
-            return err
+    outras, err := repo.ListarPorCorrida(reservada.CorridaID)
+    if err != nil {
+        return err
+    }
+    for _, outra := range outras {
+        if outra.ID == reservada.ID || outra.Status != models.NotificacaoPendente {
+            continue
+        }
+        // Ofertas recusadas ou expiradas nesse meio-tempo ficam como estão
+        cancelamento := mudancaStatus(models.NotificacaoCancelada, models.AutorSistema, motivoOutraOfertaAceita, agora)
+        if cancelada, err := repo.TrocarStatus(outra.ID, somentePendente, cancelamento); err == nil {
+            publicar = append(publicar, cancelada)
         }
-    } else {
     }
-    
+
     return nil
 }
 
-// GetHistoricoNotificacoesMotorista - Busca histórico de notificações de um motorista
-func GetHistoricoNotificacoesMotorista(motoristaID uint) ([]models.NotificacaoCorrida, error) {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// liberarReserva desfaz a reserva da corrida. Se o aceite falhou depois do prazo da oferta,
+// que não expira enquanto reservada, ela é expirada agora.
+func liberarReserva(reservada models.NotificacaoCorrida) {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
+
+    delete(aceitesEmAndamento, reservada.CorridaID)
+    atual, err := notificacoesRepo().BuscarPorID(reservada.ID)
     if err != nil {
-        return nil, err
+        return
     }
+    if expirada, ok := expirarSeVencida(atual, time.Now()); ok {
+        publicar = append(publicar, expirada)
+    }
+}
+
+// RecusarNotificacaoCorrida - Recusa uma notificação de corrida
+func RecusarNotificacaoCorrida(notificacaoID uint, motoristaID models.MotoristaID) error {
+    _, err := recusarOferta(notificacaoID, motoristaID, autorMotorista(motoristaID), motivoRecusaMotorista)
+    return err
+}
+
+// recusarOferta faz a recusa registrando no histórico da oferta quem recusou e por quê
+func recusarOferta(notificacaoID uint, motoristaID models.MotoristaID, por, motivo string) (models.NotificacaoCorrida, error) {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
+
+    repo := notificacoesRepo()
+    notificacao, err := repo.BuscarPorID(notificacaoID)
+    if err != nil {
+        return models.NotificacaoCorrida{}, err
+    }
+    if notificacao.MotoristaID != motoristaID {
+        return models.NotificacaoCorrida{}, ErrNotificacaoNaoEncontrada
+    }
+
+    // A oferta com aceite em andamento não pode mais ser recusada
+    if reservadaParaAceite(notificacao) {
+        return notificacao, ErrNotificacaoJaProcessada
+    }
+
+    // Só recusa se ainda estiver pendente
+    recusada, err := repo.TrocarStatus(notificacaoID, somentePendente, mudancaStatus(models.NotificacaoRecusada, por, motivo, time.Now()))
+    if err != nil {
+        return recusada, err
+    }
+    publicar = append(publicar, recusada)
     
-    var historico []models.NotificacaoCorrida
-    for _, notificacao := range notificacoes {
-        if notificacao.MotoristaID == motoristaID {
-            historico = append(historico, notificacao)
+    return recusada, nil
+}
+
+// retirarOferta expira ou cancela a oferta pendente fora do fluxo normal (ex.: suporte
+// destravando uma oferta presa), mesmo antes do prazo
+func retirarOferta(notificacaoID uint, status models.NotificacaoStatus, por, motivo string) (models.NotificacaoCorrida, error) {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
+
+    repo := notificacoesRepo()
+    notificacao, err := repo.BuscarPorID(notificacaoID)
+    if err != nil {
+        return models.NotificacaoCorrida{}, err
+    }
+    if reservadaParaAceite(notificacao) {
+        return notificacao, ErrNotificacaoJaProcessada
+    }
+
+    retirada, err := repo.TrocarStatus(notificacaoID, somentePendente, mudancaStatus(status, por, motivo, time.Now()))
+    if err != nil {
+        return retirada, err
+    }
+    publicar = append(publicar, retirada)
+
+    return retirada, nil
+}
+
+// AlterarStatusNotificacao aplica uma mudança de status pedida pelo suporte, registrando
+// quem a fez e por quê. Só a oferta pendente muda de status: o aceite e a recusa seguem o
+// mesmo fluxo do motorista (o aceite atribui a corrida e cancela as demais ofertas) e a
+// expiração e o cancelamento valem mesmo antes do prazo. Status finais não mudam.
+func AlterarStatusNotificacao(notificacaoID uint, novo models.NotificacaoStatus, por, motivo string) (models.NotificacaoCorrida, error) {
+    por, motivo = strings.TrimSpace(por), strings.TrimSpace(motivo)
+    if !novo.Valido() {
+        return models.NotificacaoCorrida{}, fmt.Errorf("%w: %q", ErrStatusNotificacaoInvalido, novo)
+    }
+    if por == "" || motivo == "" {
+        return models.NotificacaoCorrida{}, ErrAutoriaMudancaObrigatoria
+    }
+
+    atual, err := notificacoesRepo().BuscarPorID(notificacaoID)
+    if err != nil {
+        return models.NotificacaoCorrida{}, err
+    }
+    if !atual.Status.PodeIrPara(novo) {
+        return atual, fmt.Errorf("%w: %s -> %s", ErrTransicaoNotificacaoInvalida, atual.Status, novo)
+    }
+
+    switch novo {
+    case models.NotificacaoAceita:
+        if err := aceitarOferta(notificacaoID, atual.MotoristaID, por, motivo); err != nil {
+            return atual, err
         }
+        return notificacoesRepo().BuscarPorID(notificacaoID)
+    case models.NotificacaoRecusada:
+        return recusarOferta(notificacaoID, atual.MotoristaID, por, motivo)
+    default:
+        return retirarOferta(notificacaoID, novo, por, motivo)
     }
-    
-    return historico, nil
 }
 
-// DeleteNotificacaoCorrida - Remove uma notificação (para limpeza de dados antigos)
-func DeleteNotificacaoCorrida(id uint) error {
-    
-    notificacoes, err := readNotificacoesCorrida()
+// ExpirarNotificacoesVencidas - Marca como expiradas as notificações que passaram do tempo limite
+func ExpirarNotificacoesVencidas() error {
+    var publicar []models.NotificacaoCorrida
+    defer publicarPendentesNotificacao(&publicar)
+
+    notificacoes, err := notificacoesRepo().ListarTodas()
     if err != nil {
         return err
     }
     
-    newNotificacoes := []models.NotificacaoCorrida{}
-    found := false
-    
+    aceitesMutex.Lock()
+    defer aceitesMutex.Unlock()
+
+    agora := time.Now()
     for _, notificacao := range notificacoes {
-        if notificacao.ID != id {
-            newNotificacoes = append(newNotificacoes, notificacao)
-        } else {
-            found = true
+        if expirada, ok := expirarSeVencida(notificacao, agora); ok {
+            publicar = append(publicar, expirada)
         }
     }
     
-    if !found {
-        return errors.New("notificacao not found")
-    }
-    
-    if err := writeNotificacoesCorrida(newNotificacoes); err != nil {
-        return err
-    }
-    
     return nil
-}
\ No newline at end of file
+}
+
+// GetHistoricoNotificacoesMotorista - Busca histórico de notificações de um motorista
+func GetHistoricoNotificacoesMotorista(motoristaID models.MotoristaID) ([]models.NotificacaoCorrida, error) {
+    return notificacoesRepo().ListarPorMotorista(motoristaID)
+}
+
+// DeleteNotificacaoCorrida - Remove uma notificação (para limpeza de dados antigos)
+func DeleteNotificacaoCorrida(id uint) error {
+    return notificacoesRepo().Deletar(id)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// AvisosMotorista transforma os eventos do ciclo de vida das corridas em mensagens ao
+// motorista designado: cancelamento pelo passageiro ou automático por excesso de tempo.
+// As novas ofertas são avisadas pelo DespachoService (MensagemNovaOferta).
+type AvisosMotorista struct {
+	notificador Notificador
+	motoristas  repositories.MotoristaRepository
+}
+
+// NewAvisosMotorista cria os avisos entregues pelo notificador informado. O email e o telefone
+// do motorista vêm do cadastro, para que os avisos críticos também saiam por SMS e email no
+// horário de silêncio; sem cadastro (nil), as mensagens ficam na caixa de entrada.
+func NewAvisosMotorista(notificador Notificador, motoristas repositories.MotoristaRepository) *AvisosMotorista {
+	return &AvisosMotorista{notificador: notificador, motoristas: motoristas}
+}
+
+// OuvirCorridas é o OuvinteCorrida que envia o aviso de cada evento ao motorista da corrida
+func (a *AvisosMotorista) OuvirCorridas(evento EventoCorrida) {
+	mensagem, existe := MensagemMotorista(evento)
+	if !existe {
+		return
+	}
+	if a.motoristas != nil {
+		if motorista, err := a.motoristas.BuscarPorID(evento.Corrida.MotoristaID); err == nil {
+			mensagem.Destinatario.Email = motorista.Email
+			mensagem.Destinatario.Telefone = motorista.Telefone
+		}
+	}
+	if err := a.notificador.Notificar(mensagem); err != nil {
+		fmt.Printf("Corrida %d: erro ao avisar o motorista %s: %v\n", evento.Corrida.ID, evento.Corrida.MotoristaID, err)
+	}
+}
+
+func destinatarioMotorista(motoristaID models.MotoristaID) models.Destinatario {
+	return models.Destinatario{
+		Tipo: models.DestinatarioMotorista,
+		ID:   string(motoristaID),
+	}
+}
+
+// MensagemMotorista monta o aviso do evento ao motorista da corrida. Retorna false para
+// eventos que não geram aviso, corridas sem motorista e cancelamentos feitos pelo próprio motorista.
+func MensagemMotorista(evento EventoCorrida) (models.Mensagem, bool) {
+	corrida := evento.Corrida
+	if evento.Tipo != EventoCorridaEncerrada || corrida.MotoristaID == "" {
+		return models.Mensagem{}, false
+	}
+
+	mensagem := models.Mensagem{
+		Evento:       models.EventoMensagemCorridaCancelada,
+		Destinatario: destinatarioMotorista(corrida.MotoristaID),
+		Titulo:       "Corrida cancelada",
+		Dados: map[string]string{
+			"corrida_id": strconv.Itoa(corrida.ID),
+			"status":     corrida.Status,
+		},
+	}
+	switch corrida.Status {
+	case models.StatusCanceladaPeloUsuario:
+		mensagem.Texto = fmt.Sprintf("O passageiro cancelou a corrida %d.", corrida.ID)
+	case models.StatusCanceladaPorExcessoTempo:
+		mensagem.Texto = fmt.Sprintf("A corrida %d foi cancelada automaticamente por excesso de tempo.", corrida.ID)
+	default:
+		return models.Mensagem{}, false
+	}
+	return mensagem, true
+}
+
+// MensagemNovaOferta monta o aviso de uma oferta de corrida ao motorista escolhido
+func MensagemNovaOferta(oferta models.NotificacaoCorrida) models.Mensagem {
+	return models.Mensagem{
+		Evento:       models.EventoMensagemNovaOferta,
+		Destinatario: destinatarioMotorista(oferta.MotoristaID),
+		Titulo:       "Nova corrida",
+		Texto: fmt.Sprintf("%s a %s do embarque (%.1f km). Valor estimado: R$ %.2f.",
+			oferta.PassageiroNome, oferta.TempoEstimado, oferta.DistanciaKm, oferta.Valor),
+		Dados: map[string]string{
+			"notificacao_id": strconv.FormatUint(uint64(oferta.ID), 10),
+			"corrida_id":     strconv.FormatUint(uint64(oferta.CorridaID), 10),
+			"valor":          strconv.FormatFloat(oferta.Valor, 'f', 2, 64),
+		},
+	}
+}
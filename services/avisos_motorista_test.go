@@ -0,0 +1,109 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+var caixaMotoristaTeste = models.Destinatario{Tipo: models.DestinatarioMotorista, ID: "7"}
+
+func TestAvisosMotorista(t *testing.T) {
+	corridaTeste := models.Corrida{
+		PassageiroID: 42,
+		OrigemLat:    -8.0476,
+		OrigemLng:    -34.8770,
+		DestinoLat:   -8.1120,
+		DestinoLng:   -34.9150,
+	}
+
+	t.Run("Cancelamento pelo passageiro chega ao motorista", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.RegistrarOuvinte(NewAvisosMotorista(roteador, nil).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+
+		require.NoError(t, corridas.CancelarCorrida(corrida.ID, 0))
+
+		mensagens := caixa.Mensagens(caixaMotoristaTeste)
+		require.Len(t, mensagens, 1)
+		assert.Equal(t, models.EventoMensagemCorridaCancelada, mensagens[0].Evento)
+		assert.Equal(t, strconv.Itoa(corrida.ID), mensagens[0].Dados["corrida_id"])
+		assert.Contains(t, mensagens[0].Texto, "passageiro cancelou")
+	})
+
+	t.Run("Cancelamento automático por excesso de tempo", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		relogio := novoRelogioTeste(time.Now())
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.agora = relogio.Agora
+		corridas.RegistrarOuvinte(NewAvisosMotorista(roteador, nil).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+
+		relogio.Avancar(20 * time.Minute)
+		corridas.VerificarPrazos()
+
+		mensagens := caixa.Mensagens(caixaMotoristaTeste)
+		require.Len(t, mensagens, 1)
+		assert.Contains(t, mensagens[0].Texto, "excesso de tempo")
+	})
+
+	t.Run("Cancelamento chega ao motorista no horário de silêncio", func(t *testing.T) {
+		original, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(t.TempDir()))
+		t.Cleanup(func() { _ = os.Chdir(original) })
+
+		// Silêncio o dia todo, com SMS e caixa de entrada desativados no perfil
+		motoristas := repositories.NewJSONMotoristaRepository()
+		require.NoError(t, motoristas.Criar(&models.Motorista{ID: "7", Nome: "João", Telefone: "81988887777", Email: "joao@exemplo.com"}))
+		roteador, caixa, provedor := novoNotificadorTeste()
+		roteador.agora = func() time.Time { return time.Date(2026, 6, 30, 23, 30, 0, 0, time.Local) }
+		roteador.RegistrarArmazemPreferencias(models.DestinatarioMotorista, NewPreferenciasNoPerfil(motoristas))
+		require.NoError(t, roteador.DefinirPreferencias(caixaMotoristaTeste, models.PreferenciasNotificacao{
+			Desativados: []models.CanalNotificacao{models.CanalSMS, models.CanalInApp},
+			Silencio:    &models.HorarioSilencio{Inicio: "22:00", Fim: "06:00"},
+		}))
+
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.RegistrarOuvinte(NewAvisosMotorista(roteador, motoristas).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+		require.NoError(t, corridas.CancelarCorrida(corrida.ID, 0))
+
+		// Aviso crítico: ignora o silêncio e os canais desativados
+		mensagens := caixa.Mensagens(caixaMotoristaTeste)
+		require.Len(t, mensagens, 1)
+		assert.Equal(t, models.EventoMensagemCorridaCancelada, mensagens[0].Evento)
+		envios := provedor.Envios()
+		require.Len(t, envios, 1)
+		assert.Equal(t, "81988887777", envios[0].Para)
+
+		// Uma nova oferta no mesmo horário respeita o silêncio e os canais desativados
+		oferta := MensagemNovaOferta(models.NotificacaoCorrida{ID: 1, MotoristaID: "7", CorridaID: uint(corrida.ID)})
+		assert.Empty(t, roteador.CanaisPara(oferta))
+	})
+
+	t.Run("Cancelamento pelo próprio motorista, conclusão e corridas sem motorista não geram aviso", func(t *testing.T) {
+		for _, corrida := range []models.Corrida{
+			{ID: 1, MotoristaID: "7", Status: models.StatusCanceladaPeloMotorista},
+			{ID: 2, MotoristaID: "7", Status: models.StatusFinalizada},
+			{ID: 3, Status: models.StatusCanceladaPeloUsuario},
+		} {
+			_, existe := MensagemMotorista(EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: corrida})
+			assert.False(t, existe, "corrida %d", corrida.ID)
+		}
+	})
+}
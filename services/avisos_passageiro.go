@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"taxi-service/models"
+)
+
+// AvisosPassageiro transforma os eventos do ciclo de vida das corridas em mensagens ao
+// passageiro: motorista encontrado, motorista chegando, atraso com a nova previsão,
+// cancelamento (automático, pelo motorista ou por falta de motorista) e conclusão
+type AvisosPassageiro struct {
+	notificador Notificador
+}
+
+// NewAvisosPassageiro cria os avisos entregues pelo notificador informado
+func NewAvisosPassageiro(notificador Notificador) *AvisosPassageiro {
+	return &AvisosPassageiro{notificador: notificador}
+}
+
+// OuvirCorridas é o OuvinteCorrida que envia o aviso de cada evento ao passageiro
+func (a *AvisosPassageiro) OuvirCorridas(evento EventoCorrida) {
+	mensagem, existe := MensagemPassageiro(evento)
+	if !existe {
+		return
+	}
+	if err := a.notificador.Notificar(mensagem); err != nil {
+		fmt.Printf("Corrida %d: erro ao avisar o passageiro %d: %v\n", evento.Corrida.ID, evento.Corrida.PassageiroID, err)
+	}
+}
+
+// MensagemPassageiro monta o aviso do evento ao passageiro da corrida. Retorna false para
+// eventos que não geram aviso e para corridas sem passageiro identificado (corridas de rua).
+func MensagemPassageiro(evento EventoCorrida) (models.Mensagem, bool) {
+	corrida := evento.Corrida
+	if corrida.DeRua || corrida.PassageiroID == 0 {
+		return models.Mensagem{}, false
+	}
+
+	mensagem := models.Mensagem{
+		Destinatario: models.Destinatario{
+			Tipo: models.DestinatarioPassageiro,
+			ID:   strconv.Itoa(corrida.PassageiroID),
+		},
+		Dados: map[string]string{
+			"corrida_id": strconv.Itoa(corrida.ID),
+			"status":     corrida.Status,
+		},
+	}
+	if evento.ETAMinutos > 0 {
+		mensagem.Dados["eta_minutos"] = strconv.Itoa(evento.ETAMinutos)
+	}
+
+	switch evento.Tipo {
+	case EventoCorridaAceita:
+		mensagem.Evento = models.EventoMensagemCorridaAceita
+		mensagem.Titulo = "Motorista encontrado"
+		mensagem.Texto = fmt.Sprintf("O motorista %s aceitou sua corrida e está a caminho do embarque.", corrida.MotoristaID)
+		if evento.ETAMinutos > 0 {
+			mensagem.Texto += fmt.Sprintf(" Chegada prevista em %d min.", evento.ETAMinutos)
+		}
+	case EventoMotoristaChegando:
+		mensagem.Evento = models.EventoMensagemMotoristaChegando
+		mensagem.Titulo = "Motorista chegando"
+		mensagem.Texto = fmt.Sprintf("Seu motorista está a cerca de %d min do local de embarque.", evento.ETAMinutos)
+	case EventoCorridaAtrasada:
+		mensagem.Evento = models.EventoMensagemCorridaAtrasada
+		mensagem.Titulo = "Corrida atrasada"
+		mensagem.Texto = "Sua corrida passou do tempo estimado."
+		if evento.ETAMinutos > 0 {
+			mensagem.Texto += fmt.Sprintf(" Nova previsão de chegada ao destino: %d min.", evento.ETAMinutos)
+		}
+	case EventoCorridaEncerrada:
+		switch corrida.Status {
+		case models.StatusCanceladaPorExcessoTempo:
+			mensagem.Evento = models.EventoMensagemCorridaCancelada
+			mensagem.Titulo = "Corrida cancelada"
+			mensagem.Texto = "Sua corrida foi cancelada automaticamente por excesso de tempo."
+		case models.StatusCanceladaPeloMotorista:
+			mensagem.Evento = models.EventoMensagemCorridaCancelada
+			mensagem.Titulo = "Corrida cancelada"
+			mensagem.Texto = "O motorista cancelou sua corrida."
+		case models.StatusCanceladaSemMotorista:
+			mensagem.Evento = models.EventoMensagemCorridaCancelada
+			mensagem.Titulo = "Nenhum motorista disponível"
+			mensagem.Texto = "Não encontramos um motorista para sua corrida. Tente pedir novamente em alguns minutos."
+		case models.StatusCanceladaPeloUsuario:
+			// O próprio passageiro cancelou
+			return models.Mensagem{}, false
+		default:
+			mensagem.Evento = models.EventoMensagemCorridaConcluida
+			mensagem.Titulo = "Corrida concluída"
+			mensagem.Texto = "Você chegou ao destino. Obrigado por viajar conosco!"
+			if corrida.Preco > 0 {
+				mensagem.Texto += fmt.Sprintf(" Valor: R$ %.2f.", corrida.Preco)
+			}
+		}
+	default:
+		return models.Mensagem{}, false
+	}
+	return mensagem, true
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+var caixaPassageiroTeste = models.Destinatario{Tipo: models.DestinatarioPassageiro, ID: "42"}
+
+func TestAvisosPassageiro(t *testing.T) {
+	corridaTeste := models.Corrida{
+		PassageiroID: 42,
+		OrigemLat:    -8.0476,
+		OrigemLng:    -34.8770,
+		DestinoLat:   -8.1120,
+		DestinoLng:   -34.9150,
+	}
+
+	t.Run("Motorista encontrado e chegando, avisado uma única vez", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.RegistrarOuvinte(NewAvisosPassageiro(roteador).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+
+		// A 3 km do embarque: ainda não está chegando
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, -8.0200, -34.8770, 0))
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, -8.0450, -34.8770, 0))
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, -8.0470, -34.8770, 0))
+
+		mensagens := caixa.Mensagens(caixaPassageiroTeste)
+		require.Len(t, mensagens, 2)
+		assert.Equal(t, models.EventoMensagemMotoristaChegando, mensagens[0].Evento)
+		assert.Equal(t, "1", mensagens[0].Dados["eta_minutos"])
+		assert.Equal(t, models.EventoMensagemCorridaAceita, mensagens[1].Evento)
+		assert.Contains(t, mensagens[1].Texto, "motorista 7")
+	})
+
+	t.Run("Atraso com nova previsão e cancelamento automático", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		relogio := novoRelogioTeste(time.Now())
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.agora = relogio.Agora
+		corridas.RegistrarOuvinte(NewAvisosPassageiro(roteador).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, -8.0476, -34.8770, 0))
+
+		relogio.Avancar(2 * time.Minute)
+		corridas.VerificarPrazos()
+		corridas.VerificarPrazos()
+
+		mensagens := caixa.Mensagens(caixaPassageiroTeste)
+		require.Len(t, mensagens, 3, "o atraso é avisado uma única vez")
+		atraso := mensagens[0]
+		assert.Equal(t, models.EventoMensagemCorridaAtrasada, atraso.Evento)
+		// Do embarque ao destino são cerca de 8 km: 17 min a 30 km/h
+		assert.Equal(t, "17", atraso.Dados["eta_minutos"])
+		assert.Contains(t, atraso.Texto, "17 min")
+
+		relogio.Avancar(15 * time.Minute)
+		corridas.VerificarPrazos()
+
+		atual, err := corridas.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCanceladaPorExcessoTempo, atual.Status)
+		cancelamento := caixa.Mensagens(caixaPassageiroTeste)[0]
+		assert.Equal(t, models.EventoMensagemCorridaCancelada, cancelamento.Evento)
+		assert.Contains(t, cancelamento.Texto, "excesso de tempo")
+	})
+
+	t.Run("Corrida concluída", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.RegistrarOuvinte(NewAvisosPassageiro(roteador).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+		require.NoError(t, corridas.FinalizarCorrida(corrida.ID, 0))
+
+		mensagens := caixa.Mensagens(caixaPassageiroTeste)
+		require.NotEmpty(t, mensagens)
+		assert.Equal(t, models.EventoMensagemCorridaConcluida, mensagens[0].Evento)
+		assert.Equal(t, strconv.Itoa(corrida.ID), mensagens[0].Dados["corrida_id"])
+	})
+
+	t.Run("Cancelamento pelo próprio passageiro e corridas de rua não geram aviso", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.RegistrarOuvinte(NewAvisosPassageiro(roteador).OuvirCorridas)
+		corrida, err := corridas.CriarNovaCorrida(corridaTeste)
+		require.NoError(t, err)
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+		require.NoError(t, corridas.CancelarCorrida(corrida.ID, 0))
+		assert.Len(t, caixa.Mensagens(caixaPassageiroTeste), 1)
+
+		_, existe := MensagemPassageiro(EventoCorrida{
+			Tipo:    EventoCorridaEncerrada,
+			Corrida: models.Corrida{ID: 9, DeRua: true, Status: models.StatusFinalizada},
+		})
+		assert.False(t, existe)
+	})
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"taxi-service/models"
+)
+
+// MensagensPorCaixa é quantas mensagens cada caixa de entrada guarda; as mais antigas saem
+const MensagensPorCaixa = 200
+
+// CaixaEntrada é o canal in-app: guarda as mensagens de cada destinatário para o aplicativo
+type CaixaEntrada struct {
+	mensagens map[string][]models.Mensagem // chave: Destinatario.Chave(), da mais antiga à mais nova
+	mutex     sync.RWMutex
+}
+
+// NewCaixaEntrada cria a caixa de entrada em memória
+func NewCaixaEntrada() *CaixaEntrada {
+	return &CaixaEntrada{
+		mensagens: make(map[string][]models.Mensagem),
+	}
+}
+
+// Canal implementa CanalEntrega
+func (c *CaixaEntrada) Canal() models.CanalNotificacao {
+	return models.CanalInApp
+}
+
+// Entregar guarda a mensagem na caixa do destinatário
+func (c *CaixaEntrada) Entregar(mensagem models.Mensagem) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	chave := mensagem.Destinatario.Chave()
+	caixa := append(c.mensagens[chave], mensagem)
+	if len(caixa) > MensagensPorCaixa {
+		caixa = caixa[len(caixa)-MensagensPorCaixa:]
+	}
+	c.mensagens[chave] = caixa
+	return nil
+}
+
+// Mensagens retorna as mensagens do destinatário, da mais nova à mais antiga
+func (c *CaixaEntrada) Mensagens(destinatario models.Destinatario) []models.Mensagem {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	caixa := c.mensagens[destinatario.Chave()]
+	mensagens := make([]models.Mensagem, 0, len(caixa))
+	for i := len(caixa) - 1; i >= 0; i-- {
+		mensagens = append(mensagens, caixa[i])
+	}
+	return mensagens
+}
+
+// EnvioEmail envia uma mensagem simples por email (ex.: SMTPEmailService)
+type EnvioEmail interface {
+	EnviarMensagem(email, assunto, texto string) error
+}
+
+// CanalEmail entrega as mensagens pelo EmailService
+type CanalEmail struct {
+	envio EnvioEmail
+}
+
+// NewCanalEmail cria o canal de email
+func NewCanalEmail(envio EnvioEmail) *CanalEmail {
+	return &CanalEmail{envio: envio}
+}
+
+// Canal implementa CanalEntrega
+func (c *CanalEmail) Canal() models.CanalNotificacao {
+	return models.CanalEmail
+}
+
+// Entregar envia a mensagem para o email do destinatário
+func (c *CanalEmail) Entregar(mensagem models.Mensagem) error {
+	if mensagem.Destinatario.Email == "" {
+		return ErrSemContato
+	}
+	return c.envio.EnviarMensagem(mensagem.Destinatario.Email, mensagem.Titulo, mensagem.Texto)
+}
+
+// ProvedorSMS envia mensagens de texto (ex.: um gateway de SMS)
+type ProvedorSMS interface {
+	EnviarSMS(telefone, texto string) error
+}
+
+// CanalSMS entrega as mensagens por SMS
+type CanalSMS struct {
+	provedor ProvedorSMS
+}
+
+// NewCanalSMS cria o canal de SMS
+func NewCanalSMS(provedor ProvedorSMS) *CanalSMS {
+	return &CanalSMS{provedor: provedor}
+}
+
+// Canal implementa CanalEntrega
+func (c *CanalSMS) Canal() models.CanalNotificacao {
+	return models.CanalSMS
+}
+
+// Entregar envia título e texto para o telefone do destinatário
+func (c *CanalSMS) Entregar(mensagem models.Mensagem) error {
+	if mensagem.Destinatario.Telefone == "" {
+		return ErrSemContato
+	}
+	return c.provedor.EnviarSMS(mensagem.Destinatario.Telefone, mensagem.Titulo+": "+mensagem.Texto)
+}
+
+// ProvedorPush envia notificações push para os celulares (ex.: FCM ou APNs)
+type ProvedorPush interface {
+	EnviarPush(token, titulo, texto string, dados map[string]string) error
+}
+
+// CanalPush entrega as mensagens por notificação push
+type CanalPush struct {
+	provedor ProvedorPush
+}
+
+// NewCanalPush cria o canal de push
+func NewCanalPush(provedor ProvedorPush) *CanalPush {
+	return &CanalPush{provedor: provedor}
+}
+
+// Canal implementa CanalEntrega
+func (c *CanalPush) Canal() models.CanalNotificacao {
+	return models.CanalPush
+}
+
+// Entregar envia a mensagem para o aparelho do destinatário
+func (c *CanalPush) Entregar(mensagem models.Mensagem) error {
+	if mensagem.Destinatario.TokenPush == "" {
+		return ErrSemContato
+	}
+	return c.provedor.EnviarPush(mensagem.Destinatario.TokenPush, mensagem.Titulo, mensagem.Texto, mensagem.Dados)
+}
+
+// EnvioRegistrado é um envio guardado pelos provedores locais
+type EnvioRegistrado struct {
+	Para   string
+	Titulo string
+	Texto  string
+	Dados  map[string]string
+}
+
+// ProvedorLocal substitui os provedores de email, SMS e push em desenvolvimento e nos
+// testes: registra os envios em memória e os escreve no console
+type ProvedorLocal struct {
+	envios []EnvioRegistrado
+	mutex  sync.Mutex
+}
+
+// NewProvedorLocal cria o provedor local
+func NewProvedorLocal() *ProvedorLocal {
+	return &ProvedorLocal{}
+}
+
+// EnviarMensagem implementa EnvioEmail
+func (p *ProvedorLocal) EnviarMensagem(email, assunto, texto string) error {
+	fmt.Printf("[Email local] Para %s: %s - %s\n", email, assunto, texto)
+	p.registrar(EnvioRegistrado{Para: email, Titulo: assunto, Texto: texto})
+	return nil
+}
+
+// EnviarSMS implementa ProvedorSMS
+func (p *ProvedorLocal) EnviarSMS(telefone, texto string) error {
+	fmt.Printf("[SMS local] Para %s: %s\n", telefone, texto)
+	p.registrar(EnvioRegistrado{Para: telefone, Texto: texto})
+	return nil
+}
+
+// EnviarPush implementa ProvedorPush
+func (p *ProvedorLocal) EnviarPush(token, titulo, texto string, dados map[string]string) error {
+	fmt.Printf("[Push local] Para %s: %s - %s\n", token, titulo, texto)
+	p.registrar(EnvioRegistrado{Para: token, Titulo: titulo, Texto: texto, Dados: dados})
+	return nil
+}
+
+func (p *ProvedorLocal) registrar(envio EnvioRegistrado) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.envios = append(p.envios, envio)
+}
+
+// Envios retorna os envios registrados, na ordem em que foram feitos
+func (p *ProvedorLocal) Envios() []EnvioRegistrado {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]EnvioRegistrado(nil), p.envios...)
+}
@@ -7,7 +7,7 @@ import (
 
 func TestAvaliarCorrida_Sucesso(t *testing.T) {
 	corridas = []models.Corrida{
-		{ID: 10, MotoristaID: 999},
+		{ID: 10, MotoristaID: "999"},
 	}
 
 	err := AvaliarCorrida(10, 5)
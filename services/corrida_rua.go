@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Parâmetros do taxímetro das corridas de rua
+const (
+	DeslocamentoMinimoKm = 0.01  // deslocamentos menores são ruído do GPS e não entram no trajeto
+	VelocidadeMaximaKmH  = 200.0 // saltos acima desta velocidade são leituras erradas do GPS
+	passageiroDeRuaNome  = "Passageiro de rua"
+)
+
+// Erros das corridas de rua
+var (
+	ErrCorridaNaoEDeRua      = errors.New("não é uma corrida de rua")
+	ErrReciboIndisponivel    = errors.New("recibo disponível apenas após a finalização da corrida")
+	ErrEmailReciboInvalido   = errors.New("email inválido para envio do recibo")
+	ErrEnvioReciboIndefinido = errors.New("envio de recibo por email não configurado")
+)
+
+// EnvioRecibo entrega o recibo de uma corrida de rua no email informado pelo passageiro
+type EnvioRecibo interface {
+	EnviarRecibo(email string, recibo models.ReciboCorrida) error
+}
+
+// DefinirEnvioRecibo define quem envia os recibos das corridas de rua
+func (s *CorridaService) DefinirEnvioRecibo(envio EnvioRecibo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.envioRecibo = envio
+}
+
+// IniciarCorridaDeRua registra uma corrida pedida na rua: o motorista inicia a corrida na
+// posição atual, com passageiro anônimo, e o valor é calculado pelo taxímetro a partir do
+// trajeto enviado em AtualizarPosicao. A categoria é a do veículo do motorista.
+// Os ouvintes recebem EventoCorridaAceita, pois a corrida já começa com motorista.
+func (s *CorridaService) IniciarCorridaDeRua(motoristaID models.MotoristaID, lat, lng float64) (*models.Corrida, error) {
+	var eventos []EventoCorrida
+	defer s.publicarPendentes(&eventos)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.validador != nil {
+		if err := s.validador.ValidarEmbarque(lat, lng); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.motoristaEmCorridaAtiva(motoristaID) {
+		return nil, &ErroElegibilidade{
+			Codigo:   CodigoMotoristaOcupado,
+			Mensagem: "motorista já está em outra corrida",
+		}
+	}
+
+	// Verifica cadastro, CNH e disponibilidade e marca o motorista como ocupado
+	motorista, err := s.elegibilidade.ReservarMotorista(motoristaID)
+	if err != nil {
+		return nil, err
+	}
+
+	agora := s.agora()
+	categoria := motorista.CategoriaVeiculo.OuPadrao()
+	corrida := &models.Corrida{
+		ID:                    s.nextID,
+		MotoristaID:           motoristaID,
+		PassageiroNome:        passageiroDeRuaNome,
+		Categoria:             categoria,
+		MultiplicadorDinamico: 1,
+		Status:                models.StatusEmAndamento,
+		DataInicio:            agora,
+		OrigemLat:             lat,
+		OrigemLng:             lng,
+		MotoristaLat:          lat,
+		MotoristaLng:          lng,
+		DeRua:                 true,
+		Trajeto:               []models.PontoTrajeto{{Lat: lat, Lng: lng, Em: agora}},
+		Preco:                 TarifaDa(categoria).Calcular(0),
+		Versao:                1,
+	}
+	s.nextID++
+
+	s.corridas[corrida.ID] = corrida
+	eventos = append(eventos, EventoCorrida{Tipo: EventoCorridaAceita, Corrida: *corrida})
+	fmt.Printf("Corrida %d: Corrida de rua iniciada pelo motorista %s.\n", corrida.ID, motoristaID)
+
+	copia := *corrida
+	return &copia, nil
+}
+
+// registrarNoTaximetro acrescenta a posição ao trajeto e atualiza a distância e o valor.
+// Posições muito próximas da anterior (ruído) ou que exigiriam velocidade impossível
+// (salto do GPS) são descartadas. Deve ser chamada com o mutex travado.
+func registrarNoTaximetro(corrida *models.Corrida, lat, lng float64, em time.Time) {
+	if len(corrida.Trajeto) > 0 {
+		anterior := corrida.Trajeto[len(corrida.Trajeto)-1]
+		distancia := DistanciaKm(anterior.Lat, anterior.Lng, lat, lng)
+		if distancia < DeslocamentoMinimoKm {
+			return
+		}
+		horas := em.Sub(anterior.Em).Hours()
+		if horas > 0 && distancia/horas > VelocidadeMaximaKmH {
+			return
+		}
+		corrida.DistanciaPercorridaKm = arredondar(corrida.DistanciaPercorridaKm+distancia, 3)
+	}
+	corrida.Trajeto = append(corrida.Trajeto, models.PontoTrajeto{Lat: lat, Lng: lng, Em: em})
+	corrida.Preco = TarifaDa(corrida.Categoria).Calcular(corrida.DistanciaPercorridaKm)
+}
+
+// reciboDe monta o recibo de uma corrida de rua finalizada
+func reciboDe(corrida *models.Corrida) (*models.ReciboCorrida, error) {
+	if !corrida.DeRua {
+		return nil, fmt.Errorf("corrida %d %w", corrida.ID, ErrCorridaNaoEDeRua)
+	}
+	if corrida.Status != models.StatusFinalizada || corrida.DataFim == nil {
+		return nil, ErrReciboIndisponivel
+	}
+
+	tarifa := TarifaDa(corrida.Categoria)
+	return &models.ReciboCorrida{
+		CorridaID:   corrida.ID,
+		MotoristaID: corrida.MotoristaID,
+		Categoria:   corrida.Categoria,
+		Inicio:      corrida.DataInicio,
+		Fim:         *corrida.DataFim,
+		DuracaoMin:  int(math.Ceil(corrida.DataFim.Sub(corrida.DataInicio).Minutes())),
+		DistanciaKm: arredondar(corrida.DistanciaPercorridaKm, 2),
+		Bandeirada:  tarifa.Base,
+		PorKm:       tarifa.PorKm,
+		Valor:       corrida.Preco,
+	}, nil
+}
+
+// ReciboCorrida retorna o recibo de uma corrida de rua finalizada
+func (s *CorridaService) ReciboCorrida(corridaID int) (*models.ReciboCorrida, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	corrida, exists := s.corridas[corridaID]
+	if !exists {
+		return nil, fmt.Errorf("corrida com ID %d %w", corridaID, ErrCorridaNaoEncontrada)
+	}
+	return reciboDe(corrida)
+}
+
+// EnviarRecibo envia o recibo de uma corrida de rua finalizada para o email do passageiro
+func (s *CorridaService) EnviarRecibo(corridaID int, email string) (*models.ReciboCorrida, error) {
+	if !models.ValidarEmail(email) {
+		return nil, ErrEmailReciboInvalido
+	}
+
+	recibo, err := s.ReciboCorrida(corridaID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	envio := s.envioRecibo
+	s.mutex.RUnlock()
+	if envio == nil {
+		return nil, ErrEnvioReciboIndefinido
+	}
+	if err := envio.EnviarRecibo(email, *recibo); err != nil {
+		return nil, fmt.Errorf("erro ao enviar recibo: %w", err)
+	}
+	return recibo, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// envioReciboFake guarda os recibos enviados
+type envioReciboFake struct {
+	enviados map[string]models.ReciboCorrida
+	erro     error
+}
+
+func (e *envioReciboFake) EnviarRecibo(email string, recibo models.ReciboCorrida) error {
+	if e.erro != nil {
+		return e.erro
+	}
+	e.enviados[email] = recibo
+	return nil
+}
+
+func TestCorridaDeRua(t *testing.T) {
+	t.Run("Corrida começa em andamento com passageiro anônimo e bandeirada", func(t *testing.T) {
+		corrida, err := NewCorridaService(novaElegibilidadeFake()).IniciarCorridaDeRua("7", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+
+		assert.True(t, corrida.DeRua)
+		assert.Equal(t, models.StatusEmAndamento, corrida.Status)
+		assert.Equal(t, models.MotoristaID("7"), corrida.MotoristaID)
+		assert.Zero(t, corrida.PassageiroID)
+		assert.Equal(t, TarifaDa(models.CategoriaComum).Base, corrida.Preco)
+	})
+
+	t.Run("Motorista em outra corrida não inicia corrida de rua", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		_, err := corridas.IniciarCorridaDeRua("7", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+
+		_, err = corridas.IniciarCorridaDeRua("7", embarqueLat, embarqueLng)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoMotoristaOcupado, erroElegibilidade.Codigo)
+	})
+
+	t.Run("Taxímetro soma o trajeto e ignora ruído e saltos do GPS", func(t *testing.T) {
+		relogio := novoRelogioTeste(time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC))
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.agora = relogio.Agora
+		corrida, err := corridas.IniciarCorridaDeRua("7", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+		mover := func(intervalo time.Duration, lat, lng float64) {
+			relogio.Avancar(intervalo)
+			require.NoError(t, corridas.AtualizarPosicao(corrida.ID, lat, lng, 0))
+		}
+
+		mover(time.Minute, embarqueLat-0.009, embarqueLng)     // ~1 km para o sul
+		mover(5*time.Second, embarqueLat-0.00905, embarqueLng) // ~5 m: ruído
+		mover(5*time.Second, embarqueLat+0.5, embarqueLng)     // ~56 km em 5 s: salto
+		mover(time.Minute, embarqueLat-0.018, embarqueLng)     // mais ~1 km
+
+		atual, err := corridas.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 2.0, atual.DistanciaPercorridaKm, 0.01)
+		assert.Len(t, atual.Trajeto, 3)
+		assert.Equal(t, TarifaDa(models.CategoriaComum).Calcular(atual.DistanciaPercorridaKm), atual.Preco)
+	})
+
+	t.Run("Finalização gera o recibo e o envia por email", func(t *testing.T) {
+		relogio := novoRelogioTeste(time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC))
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.agora = relogio.Agora
+		corrida, err := corridas.IniciarCorridaDeRua("7", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+		envio := &envioReciboFake{enviados: map[string]models.ReciboCorrida{}}
+		corridas.DefinirEnvioRecibo(envio)
+
+		_, err = corridas.ReciboCorrida(corrida.ID)
+		assert.ErrorIs(t, err, ErrReciboIndisponivel)
+
+		relogio.Avancar(4 * time.Minute)
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, embarqueLat-0.027, embarqueLng, 0))
+		relogio.Avancar(30 * time.Second)
+		require.NoError(t, corridas.FinalizarCorrida(corrida.ID, 0))
+
+		atual, _ := corridas.GetCorridaPorID(corrida.ID)
+		assert.Equal(t, models.StatusFinalizada, atual.Status)
+
+		_, err = corridas.EnviarRecibo(corrida.ID, "passageiro@exemplo")
+		assert.ErrorIs(t, err, ErrEmailReciboInvalido)
+
+		recibo, err := corridas.EnviarRecibo(corrida.ID, "passageiro@exemplo.com")
+		require.NoError(t, err)
+		assert.Equal(t, *recibo, envio.enviados["passageiro@exemplo.com"])
+		assert.Equal(t, 5, recibo.DuracaoMin)
+		assert.InDelta(t, 3.0, recibo.DistanciaKm, 0.01)
+		assert.Equal(t, atual.Preco, recibo.Valor)
+
+		envio.erro = errors.New("smtp fora do ar")
+		_, err = corridas.EnviarRecibo(corrida.ID, "passageiro@exemplo.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("Corridas pedidas pelo aplicativo não têm recibo de taxímetro", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+
+		_, err = corridas.ReciboCorrida(corrida.ID)
+		assert.ErrorIs(t, err, ErrCorridaNaoEDeRua)
+	})
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// elegibilidadeFake aprova qualquer motorista e registra reservas e liberações.
+// Sem perfil definido, o motorista dirige um carro comum com CNH B.
+type elegibilidadeFake struct {
+	reservados map[models.MotoristaID]bool
+	bloqueados map[models.MotoristaID]error
+	perfis     map[models.MotoristaID]models.Motorista
+	erro       error
+	reservas   int // chamadas a ReservarMotorista
+}
+
+func novaElegibilidadeFake() *elegibilidadeFake {
+	return &elegibilidadeFake{
+		reservados: map[models.MotoristaID]bool{},
+		bloqueados: map[models.MotoristaID]error{},
+		perfis:     map[models.MotoristaID]models.Motorista{},
+	}
+}
+
+func (f *elegibilidadeFake) VerificarElegibilidade(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	if f.erro != nil {
+		return nil, f.erro
+	}
+	if err, bloqueado := f.bloqueados[motoristaID]; bloqueado {
+		return nil, err
+	}
+	if perfil, existe := f.perfis[motoristaID]; existe {
+		perfil.ID = motoristaID
+		perfil.Status = models.StatusAprovado
+		return &perfil, nil
+	}
+	return &models.Motorista{ID: motoristaID, Status: models.StatusAprovado, CategoriaCNH: models.CategoriaB}, nil
+}
+
+func (f *elegibilidadeFake) ReservarMotorista(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	motorista, err := f.VerificarElegibilidade(motoristaID)
+	if err != nil {
+		return nil, err
+	}
+	f.reservas++
+	f.reservados[motoristaID] = true
+	return motorista, nil
+}
+
+func (f *elegibilidadeFake) LiberarMotorista(motoristaID models.MotoristaID) error {
+	delete(f.reservados, motoristaID)
+	return nil
+}
+
+func TestCorridaServiceVersao(t *testing.T) {
+	service := NewCorridaService(novaElegibilidadeFake())
+
+	corrida, err := service.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, corrida.Versao)
+
+	t.Run("Alteração sem versão esperada incrementa a versão", func(t *testing.T) {
+		err := service.AtualizarPosicao(corrida.ID, -8.05, -34.9, 0)
+		require.NoError(t, err)
+
+		atual, err := service.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, atual.Versao)
+	})
+
+	t.Run("Versão esperada desatualizada é rejeitada", func(t *testing.T) {
+		err := service.AceitarCorrida(corrida.ID, "7", 1)
+		assert.ErrorIs(t, err, ErrVersaoConflito)
+
+		atual, err := service.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusProcurandoMotorista, atual.Status)
+	})
+
+	t.Run("Versão esperada atual é aceita", func(t *testing.T) {
+		err := service.AceitarCorrida(corrida.ID, "7", 2)
+		require.NoError(t, err)
+
+		atual, err := service.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusMotoristaEncontrado, atual.Status)
+		assert.Equal(t, 3, atual.Versao)
+	})
+}
+
+func TestCorridaServiceElegibilidade(t *testing.T) {
+	t.Run("Motorista inelegível não assume a corrida", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.erro = &ErroElegibilidade{Codigo: CodigoCNHVencida, Mensagem: "CNH vencida"}
+		service := NewCorridaService(elegibilidade)
+
+		corrida, err := service.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+
+		err = service.AceitarCorrida(corrida.ID, "7", 0)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoCNHVencida, erroElegibilidade.Codigo)
+		assert.Equal(t, models.StatusProcurandoMotorista, corrida.Status)
+	})
+
+	t.Run("Motorista em corrida ativa não aceita outra", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		service := NewCorridaService(elegibilidade)
+
+		primeira, _ := service.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		segunda, _ := service.CriarNovaCorrida(models.Corrida{PassageiroID: 2})
+
+		require.NoError(t, service.AceitarCorrida(primeira.ID, "7", 0))
+		assert.True(t, elegibilidade.reservados["7"])
+
+		err := service.AceitarCorrida(segunda.ID, "7", 0)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoMotoristaOcupado, erroElegibilidade.Codigo)
+	})
+
+	t.Run("Finalizar a corrida libera o motorista", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		service := NewCorridaService(elegibilidade)
+
+		corrida, _ := service.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, service.AceitarCorrida(corrida.ID, "7", 0))
+		require.NoError(t, service.FinalizarCorrida(corrida.ID, 0))
+
+		assert.False(t, elegibilidade.reservados["7"])
+	})
+
+	t.Run("Corrida encerrada não é cancelada nem finalizada de novo", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		service := NewCorridaService(elegibilidade)
+		encerradas := 0
+		service.RegistrarOuvinte(func(evento EventoCorrida) {
+			if evento.Tipo == EventoCorridaEncerrada {
+				encerradas++
+			}
+		})
+
+		primeira, _ := service.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, service.AceitarCorrida(primeira.ID, "7", 0))
+		require.NoError(t, service.FinalizarCorrida(primeira.ID, 0))
+		dataFim := *primeira.DataFim
+
+		// O motorista já está em outra corrida quando os pedidos repetidos chegam
+		segunda, _ := service.CriarNovaCorrida(models.Corrida{PassageiroID: 2})
+		require.NoError(t, service.AceitarCorrida(segunda.ID, "7", 0))
+
+		assert.ErrorIs(t, service.CancelarCorrida(primeira.ID, 0), ErrCorridaIndisponivel)
+		assert.ErrorIs(t, service.FinalizarCorrida(primeira.ID, 0), ErrCorridaIndisponivel)
+		assert.True(t, elegibilidade.reservados["7"])
+		assert.Equal(t, dataFim, *primeira.DataFim)
+		assert.Equal(t, 1, encerradas)
+
+		require.NoError(t, service.CancelarCorrida(segunda.ID, 0))
+		assert.ErrorIs(t, service.FinalizarCorrida(segunda.ID, 0), ErrCorridaIndisponivel)
+		assert.Equal(t, models.StatusCanceladaPeloUsuario, segunda.Status)
+	})
+}
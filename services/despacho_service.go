@@ -0,0 +1,807 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Parâmetros padrão do despacho por proximidade
+const (
+	RaioDespachoPadraoKm       = 2.0
+	MaxRodadasPadrao           = 3
+	PrazoBuscaPadrao           = 2 * time.Minute
+	IntervaloRetentativaPadrao = 10 * time.Second
+	VelocidadeMediaKmH         = 30.0
+	PesoAceitePadraoKm         = 1.0
+	passageiroNomePadrao       = "Passageiro"
+)
+
+// MotoristaProximo é um motorista disponível encontrado perto de um ponto
+type MotoristaProximo struct {
+	ID          models.MotoristaID
+	Lat         float64
+	Lng         float64
+	DistanciaKm float64
+}
+
+// LocalizadorMotoristas encontra motoristas disponíveis dentro de um raio
+type LocalizadorMotoristas interface {
+	BuscarProximos(lat, lng, raioKm float64) ([]MotoristaProximo, error)
+}
+
+// FilaEmbarque informa a fila por ordem de chegada do ponto de táxi ou aeroporto que contém
+// o embarque. Retorna false quando o embarque não está em um ponto com fila.
+type FilaEmbarque interface {
+	FilaDoEmbarque(lat, lng float64) ([]MotoristaProximo, bool)
+}
+
+// HistoricoOfertas guarda na corrida as ofertas feitas durante a busca
+type HistoricoOfertas interface {
+	RegistrarOferta(corridaID int, oferta models.OfertaCorrida) error
+	AtualizarOferta(corridaID int, notificacaoID uint, status models.NotificacaoStatus, em time.Time) error
+}
+
+// EncerradorCorridas encerra as corridas cuja busca por motorista terminou sem aceite (ex.: CorridaService)
+type EncerradorCorridas interface {
+	EncerrarSemMotorista(corridaID int) error
+}
+
+// ZonasEmbarque localiza as zonas ativas que contêm o embarque (ex.: ZonaService)
+type ZonasEmbarque interface {
+	Localizar(lat, lng float64) []models.Zona
+}
+
+// TaxasAceite informa a taxa de aceite recente do motorista (ex.: EstatisticasMotoristas).
+// Retorna false quando ainda não há ofertas suficientes para uma taxa confiável.
+type TaxasAceite interface {
+	TaxaAceite(motoristaID models.MotoristaID) (float64, bool)
+}
+
+// ConfigDespacho reúne o raio, os limites da busca por motorista e as estratégias de oferta.
+// A estratégia da zona do embarque tem precedência sobre a da cidade, que tem precedência
+// sobre a padrão.
+type ConfigDespacho struct {
+	RaioKm               float64
+	MaxRodadas           int           // rodadas com ofertas antes de desistir
+	PrazoBusca           time.Duration // tempo total de busca a partir da criação da corrida
+	IntervaloRetentativa time.Duration // espera antes de nova rodada quando ninguém pôde ser ofertado
+	PesoAceiteKm         float64       // distância somada, na classificação, a quem nunca aceita ofertas
+	EstrategiaPadrao     EstrategiaDespacho
+	EstrategiasPorCidade map[string]EstrategiaDespacho // chave: nome da cidade em minúsculas
+	EstrategiasPorZona   map[string]EstrategiaDespacho // chave: nome da zona em minúsculas
+}
+
+// ConfigDespachoFromEnv lê DISPATCH_RADIUS_KM, DISPATCH_MAX_ROUNDS, DISPATCH_DEADLINE,
+// DISPATCH_RETRY_INTERVAL, DISPATCH_ACCEPTANCE_WEIGHT_KM e as estratégias (DISPATCH_STRATEGY, DISPATCH_STRATEGY_BY_CITY,
+// DISPATCH_STRATEGY_BY_ZONE, DISPATCH_BROADCAST_SIZE e DISPATCH_BATCH_INTERVAL); valores ausentes ou inválidos usam o padrão
+func ConfigDespachoFromEnv() ConfigDespacho {
+	config := ConfigDespacho{
+		EstrategiasPorCidade: map[string]EstrategiaDespacho{},
+		EstrategiasPorZona:   map[string]EstrategiaDespacho{},
+	}
+	if raioKm, err := strconv.ParseFloat(os.Getenv("DISPATCH_RADIUS_KM"), 64); err == nil {
+		config.RaioKm = raioKm
+	}
+	if maxRodadas, err := strconv.Atoi(os.Getenv("DISPATCH_MAX_ROUNDS")); err == nil {
+		config.MaxRodadas = maxRodadas
+	}
+	if prazo, err := time.ParseDuration(os.Getenv("DISPATCH_DEADLINE")); err == nil {
+		config.PrazoBusca = prazo
+	}
+	if intervalo, err := time.ParseDuration(os.Getenv("DISPATCH_RETRY_INTERVAL")); err == nil {
+		config.IntervaloRetentativa = intervalo
+	}
+	if peso, err := strconv.ParseFloat(os.Getenv("DISPATCH_ACCEPTANCE_WEIGHT_KM"), 64); err == nil {
+		config.PesoAceiteKm = peso
+	}
+
+	tamanhoBroadcast, _ := strconv.Atoi(os.Getenv("DISPATCH_BROADCAST_SIZE"))
+	intervaloLote, _ := time.ParseDuration(os.Getenv("DISPATCH_BATCH_INTERVAL"))
+	if nome := os.Getenv("DISPATCH_STRATEGY"); nome != "" {
+		estrategia, err := NovaEstrategiaDespacho(nome, tamanhoBroadcast, intervaloLote)
+		if err != nil {
+			fmt.Printf("DISPATCH_STRATEGY ignorada: %v\n", err)
+		}
+		config.EstrategiaPadrao = estrategia
+	}
+	// Formato: "recife:sequencial,sao paulo:lote"
+	for _, item := range strings.Split(os.Getenv("DISPATCH_STRATEGY_BY_CITY"), ",") {
+		cidade, nome, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		estrategia, err := NovaEstrategiaDespacho(nome, tamanhoBroadcast, intervaloLote)
+		if err != nil {
+			fmt.Printf("DISPATCH_STRATEGY_BY_CITY: cidade %q ignorada: %v\n", cidade, err)
+			continue
+		}
+		config.EstrategiasPorCidade[normalizarCidade(cidade)] = estrategia
+	}
+	// Formato: "aeroporto dos guararapes:sequencial,boa viagem:lote"
+	for _, item := range strings.Split(os.Getenv("DISPATCH_STRATEGY_BY_ZONE"), ",") {
+		zona, nome, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		estrategia, err := NovaEstrategiaDespacho(nome, tamanhoBroadcast, intervaloLote)
+		if err != nil {
+			fmt.Printf("DISPATCH_STRATEGY_BY_ZONE: zona %q ignorada: %v\n", zona, err)
+			continue
+		}
+		config.EstrategiasPorZona[normalizarCidade(zona)] = estrategia
+	}
+	return config
+}
+
+// comPadroes preenche os campos não configurados
+func (c ConfigDespacho) comPadroes() ConfigDespacho {
+	if c.RaioKm <= 0 {
+		c.RaioKm = RaioDespachoPadraoKm
+	}
+	if c.MaxRodadas <= 0 {
+		c.MaxRodadas = MaxRodadasPadrao
+	}
+	if c.PrazoBusca <= 0 {
+		c.PrazoBusca = PrazoBuscaPadrao
+	}
+	if c.IntervaloRetentativa <= 0 {
+		c.IntervaloRetentativa = IntervaloRetentativaPadrao
+	}
+	if c.PesoAceiteKm <= 0 {
+		c.PesoAceiteKm = PesoAceitePadraoKm
+	}
+	if c.EstrategiaPadrao == nil {
+		c.EstrategiaPadrao = NewEstrategiaBroadcast(TamanhoBroadcastPadrao)
+	}
+	estrategias := make(map[string]EstrategiaDespacho, len(c.EstrategiasPorCidade))
+	for cidade, estrategia := range c.EstrategiasPorCidade {
+		estrategias[normalizarCidade(cidade)] = estrategia
+	}
+	c.EstrategiasPorCidade = estrategias
+	porZona := make(map[string]EstrategiaDespacho, len(c.EstrategiasPorZona))
+	for zona, estrategia := range c.EstrategiasPorZona {
+		porZona[normalizarCidade(zona)] = estrategia
+	}
+	c.EstrategiasPorZona = porZona
+	return c
+}
+
+// estrategiaDa retorna a estratégia da primeira zona do embarque com estratégia configurada,
+// senão a da cidade da corrida, senão a padrão
+func (c ConfigDespacho) estrategiaDa(corrida models.Corrida, zonas []models.Zona) EstrategiaDespacho {
+	for _, zona := range zonas {
+		if estrategia, existe := c.EstrategiasPorZona[normalizarCidade(zona.Nome)]; existe {
+			return estrategia
+		}
+	}
+	if estrategia, existe := c.EstrategiasPorCidade[normalizarCidade(corrida.Cidade)]; existe {
+		return estrategia
+	}
+	return c.EstrategiaPadrao
+}
+
+// menorIntervaloLote retorna o menor intervalo entre as estratégias em lote, ou zero se não houver
+func (c ConfigDespacho) menorIntervaloLote() time.Duration {
+	var menor time.Duration
+	estrategias := []EstrategiaDespacho{c.EstrategiaPadrao}
+	for _, estrategia := range c.EstrategiasPorCidade {
+		estrategias = append(estrategias, estrategia)
+	}
+	for _, estrategia := range c.EstrategiasPorZona {
+		estrategias = append(estrategias, estrategia)
+	}
+	for _, estrategia := range estrategias {
+		if intervalo := estrategia.IntervaloLote(); intervalo > 0 && (menor == 0 || intervalo < menor) {
+			menor = intervalo
+		}
+	}
+	return menor
+}
+
+// buscaCorrida é o estado da procura por motorista de uma corrida
+type buscaCorrida struct {
+	corrida        models.Corrida
+	estrategia     EstrategiaDespacho
+	inicio         time.Time
+	rodada         int
+	pendentes      map[uint]models.MotoristaID // ofertas da rodada atual ainda sem resposta
+	recusaram      map[models.MotoristaID]bool
+	ofertados      map[models.MotoristaID]bool
+	aguardandoLote bool        // rodada pendente até o próximo lote da estratégia
+	prazo          *time.Timer // encerra a busca ao fim do prazo total, mesmo com ofertas pendentes
+}
+
+// DespachoService oferta as novas corridas (NotificacaoCorrida) aos motoristas próximos.
+// A EstrategiaDespacho da zona ou da cidade decide quem recebe cada rodada. Quando todas as ofertas de
+// uma rodada são recusadas ou expiram, uma nova rodada é feita com os candidatos do momento,
+// sem os motoristas que recusaram, até a corrida ser aceita ou os limites de rodadas e de
+// prazo serem atingidos; aí as ofertas pendentes são retiradas e a corrida é encerrada sem motorista.
+type DespachoService struct {
+	localizador   LocalizadorMotoristas
+	elegibilidade ElegibilidadeService
+	historico     HistoricoOfertas
+	criarOferta   func(notificacao *models.NotificacaoCorrida) error
+	filas         FilaEmbarque
+	zonas         ZonasEmbarque
+	taxas         TaxasAceite
+	notificador   Notificador
+	encerrador    EncerradorCorridas
+	retirarOferta func(notificacaoID uint) error
+	avisos        []models.Mensagem // avisos de novas ofertas à espera do Unlock
+	retiradas     []uint            // ofertas pendentes de buscas encerradas, à espera do Unlock
+	semMotorista  []int             // corridas das buscas encerradas, à espera do Unlock
+	config        ConfigDespacho
+	buscas        map[int]*buscaCorrida
+	recentes      map[models.MotoristaID][]time.Time // ofertas do último minuto, para o limite por motorista
+	mutex         sync.Mutex
+	agora         func() time.Time
+}
+
+// NewDespachoService cria o serviço de despacho e, se alguma estratégia trabalha em lotes,
+// inicia o processamento periódico dos lotes
+func NewDespachoService(localizador LocalizadorMotoristas, elegibilidade ElegibilidadeService, historico HistoricoOfertas, config ConfigDespacho) *DespachoService {
+	despacho := &DespachoService{
+		localizador:   localizador,
+		elegibilidade: elegibilidade,
+		historico:     historico,
+		criarOferta:   CreateNotificacaoCorrida,
+		retirarOferta: cancelarOfertaDaBusca,
+		config:        config.comPadroes(),
+		buscas:        make(map[int]*buscaCorrida),
+		recentes:      make(map[models.MotoristaID][]time.Time),
+		agora:         time.Now,
+	}
+	if intervalo := despacho.config.menorIntervaloLote(); intervalo > 0 {
+		go despacho.MonitorarLotes(intervalo)
+	}
+	return despacho
+}
+
+// NewDespachoServiceFromEnv cria o serviço com a configuração das variáveis de ambiente
+func NewDespachoServiceFromEnv(localizador LocalizadorMotoristas, elegibilidade ElegibilidadeService, historico HistoricoOfertas) *DespachoService {
+	return NewDespachoService(localizador, elegibilidade, historico, ConfigDespachoFromEnv())
+}
+
+// DefinirFilas define as filas dos pontos de táxi. Embarques dentro de um ponto são
+// ofertados ao primeiro da fila em vez do motorista mais próximo.
+func (d *DespachoService) DefinirFilas(filas FilaEmbarque) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.filas = filas
+}
+
+// DefinirZonas define onde localizar as zonas do embarque, usadas na escolha da estratégia
+// configurada por zona
+func (d *DespachoService) DefinirZonas(zonas ZonasEmbarque) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.zonas = zonas
+}
+
+// DefinirTaxasAceite define de onde vêm as taxas de aceite usadas na classificação dos
+// candidatos: quem costuma deixar ofertas sem aceite cede a vez a quem está um pouco mais longe
+func (d *DespachoService) DefinirTaxasAceite(taxas TaxasAceite) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.taxas = taxas
+}
+
+// DefinirNotificador define o notificador que avisa os motoristas das novas ofertas
+func (d *DespachoService) DefinirNotificador(notificador Notificador) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.notificador = notificador
+}
+
+// DefinirEncerrador define quem encerra as corridas cuja busca terminou sem aceite, para que
+// o passageiro não fique esperando um motorista que não virá
+func (d *DespachoService) DefinirEncerrador(encerrador EncerradorCorridas) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.encerrador = encerrador
+}
+
+// concluirPendentes envia os avisos das ofertas criadas, retira as ofertas das buscas encerradas
+// e encerra as corridas que ficaram sem motorista, tudo acumulado com o mutex travado. É adiada
+// antes do Lock para rodar depois do Unlock: a entrega passa por provedores externos e a
+// retirada e o encerramento voltam ao despacho pelos ouvintes.
+func (d *DespachoService) concluirPendentes() {
+	d.mutex.Lock()
+	avisos, retiradas, semMotorista := d.avisos, d.retiradas, d.semMotorista
+	notificador, encerrador := d.notificador, d.encerrador
+	d.avisos, d.retiradas, d.semMotorista = nil, nil, nil
+	d.mutex.Unlock()
+
+	for _, aviso := range avisos {
+		if err := notificador.Notificar(aviso); err != nil {
+			fmt.Printf("Oferta %s: erro ao avisar o motorista %s: %v\n", aviso.Dados["notificacao_id"], aviso.Destinatario.ID, err)
+		}
+	}
+	for _, notificacaoID := range retiradas {
+		if err := d.retirarOferta(notificacaoID); err != nil {
+			fmt.Printf("Oferta %d: erro ao retirar a oferta da busca encerrada: %v\n", notificacaoID, err)
+		}
+	}
+	if encerrador == nil {
+		return
+	}
+	for _, corridaID := range semMotorista {
+		if err := encerrador.EncerrarSemMotorista(corridaID); err != nil {
+			fmt.Printf("Corrida %d: erro ao encerrar a corrida sem motorista: %v\n", corridaID, err)
+		}
+	}
+}
+
+// cancelarOfertaDaBusca cancela a oferta ainda pendente de uma busca encerrada
+func cancelarOfertaDaBusca(notificacaoID uint) error {
+	_, err := retirarOferta(notificacaoID, models.NotificacaoCancelada, models.AutorSistema, motivoBuscaEncerrada)
+	return err
+}
+
+// OuvirCorridas inicia a busca das corridas criadas e a encerra quando a corrida é
+// aceita ou termina. Deve ser registrado no CorridaService.
+func (d *DespachoService) OuvirCorridas(evento EventoCorrida) {
+	switch evento.Tipo {
+	case EventoCorridaCriada:
+		if _, err := d.Despachar(evento.Corrida); err != nil {
+			fmt.Printf("Corrida %d: erro ao despachar ofertas: %v\n", evento.Corrida.ID, err)
+		}
+	case EventoCorridaAceita, EventoCorridaEncerrada:
+		d.mutex.Lock()
+		d.removerBusca(evento.Corrida.ID)
+		d.mutex.Unlock()
+	}
+}
+
+// OuvirNotificacoes acompanha as respostas às ofertas e reoferta a corrida quando a rodada
+// termina sem aceite. Deve ser registrado com RegistrarOuvinteNotificacao.
+func (d *DespachoService) OuvirNotificacoes(notificacao models.NotificacaoCorrida) {
+	defer d.concluirPendentes()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	busca, existe := d.buscas[int(notificacao.CorridaID)]
+	if !existe {
+		// Busca já encerrada (ex.: corrida aceita): as ofertas canceladas ainda vão ao
+		// histórico. Ofertas de corridas desconhecidas são ignoradas.
+		_ = d.historico.AtualizarOferta(int(notificacao.CorridaID), notificacao.ID, notificacao.Status, d.agora())
+		return
+	}
+	if _, pendente := busca.pendentes[notificacao.ID]; !pendente {
+		return
+	}
+
+	delete(busca.pendentes, notificacao.ID)
+	d.atualizarHistorico(busca, notificacao.ID, notificacao.Status)
+
+	switch notificacao.Status {
+	case models.NotificacaoAceita:
+		d.removerBusca(busca.corrida.ID)
+		return
+	case models.NotificacaoRecusada:
+		busca.recusaram[notificacao.MotoristaID] = true
+	}
+
+	if len(busca.pendentes) == 0 {
+		if _, err := d.proximaRodada(busca); err != nil {
+			fmt.Printf("Corrida %d: erro ao reofertar: %v\n", busca.corrida.ID, err)
+		}
+	}
+}
+
+// Despachar inicia a busca por motorista e faz a primeira rodada de ofertas, retornando
+// as ofertas criadas. Corridas sem coordenadas de embarque não são despachadas.
+func (d *DespachoService) Despachar(corrida models.Corrida) ([]models.NotificacaoCorrida, error) {
+	if corrida.OrigemLat == 0 && corrida.OrigemLng == 0 {
+		return nil, nil
+	}
+
+	defer d.concluirPendentes()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	busca := &buscaCorrida{
+		corrida:    corrida,
+		estrategia: d.estrategiaDa(corrida),
+		inicio:     d.agora(),
+		pendentes:  make(map[uint]models.MotoristaID),
+		recusaram:  make(map[models.MotoristaID]bool),
+		ofertados:  make(map[models.MotoristaID]bool),
+	}
+	d.removerBusca(corrida.ID)
+	d.buscas[corrida.ID] = busca
+	busca.prazo = time.AfterFunc(d.config.PrazoBusca, func() {
+		d.vencerPrazo(corrida.ID)
+	})
+
+	return d.proximaRodada(busca)
+}
+
+// estrategiaDa escolhe a estratégia da corrida pela zona do embarque, pela cidade ou a padrão.
+// Deve ser chamada com o mutex travado.
+func (d *DespachoService) estrategiaDa(corrida models.Corrida) EstrategiaDespacho {
+	var zonas []models.Zona
+	if d.zonas != nil && len(d.config.EstrategiasPorZona) > 0 {
+		zonas = d.zonas.Localizar(corrida.OrigemLat, corrida.OrigemLng)
+	}
+	return d.config.estrategiaDa(corrida, zonas)
+}
+
+// proximaRodada oferta a corrida aos motoristas escolhidos pela estratégia ou encerra a
+// busca se os limites foram atingidos. Embarques em pontos de táxi com motoristas elegíveis
+// na fila vão ao primeiro da fila, qualquer que seja a estratégia. Estratégias em lote deixam a rodada para o próximo
+// lote; sem candidatos, uma nova tentativa é agendada. Deve ser chamada com o mutex travado.
+func (d *DespachoService) proximaRodada(busca *buscaCorrida) ([]models.NotificacaoCorrida, error) {
+	if d.limiteAtingido(busca) {
+		d.encerrarBusca(busca)
+		return nil, nil
+	}
+
+	escolhidos, naFila := d.primeiroDaFila(busca)
+	if !naFila {
+		if busca.estrategia.IntervaloLote() > 0 {
+			busca.aguardandoLote = true
+			return nil, nil
+		}
+
+		candidatos, err := d.candidatos(busca, nil)
+		if err != nil {
+			return nil, err
+		}
+		escolhidos = busca.estrategia.Distribuir([]PedidoDespacho{d.pedido(busca, candidatos)})[busca.corrida.ID]
+	}
+
+	// Só contam as rodadas que ofertaram: sem candidatos, as novas tentativas seguem até o prazo
+	if len(escolhidos) > 0 {
+		busca.rodada++
+	}
+	ofertas, err := d.ofertar(busca, escolhidos)
+	if err != nil {
+		return ofertas, err
+	}
+
+	if len(busca.pendentes) == 0 {
+		corridaID := busca.corrida.ID
+		time.AfterFunc(d.config.IntervaloRetentativa, func() {
+			d.retentar(corridaID)
+		})
+	}
+	return ofertas, nil
+}
+
+// limiteAtingido indica se a busca já usou todas as rodadas ou passou do prazo
+func (d *DespachoService) limiteAtingido(busca *buscaCorrida) bool {
+	return busca.rodada >= d.config.MaxRodadas || d.agora().Sub(busca.inicio) >= d.config.PrazoBusca
+}
+
+// encerrarBusca desiste da corrida: as ofertas ainda pendentes são retiradas e a corrida é
+// encerrada sem motorista, o que avisa o passageiro. Deve ser chamada com o mutex travado.
+func (d *DespachoService) encerrarBusca(busca *buscaCorrida) {
+	d.removerBusca(busca.corrida.ID)
+	for notificacaoID := range busca.pendentes {
+		d.retiradas = append(d.retiradas, notificacaoID)
+	}
+	d.semMotorista = append(d.semMotorista, busca.corrida.ID)
+	fmt.Printf("Corrida %d: busca por motorista encerrada após %d rodada(s) sem aceite.\n", busca.corrida.ID, busca.rodada)
+}
+
+// removerBusca esquece a busca e desarma o prazo. Deve ser chamada com o mutex travado.
+func (d *DespachoService) removerBusca(corridaID int) {
+	if busca, existe := d.buscas[corridaID]; existe && busca.prazo != nil {
+		busca.prazo.Stop()
+	}
+	delete(d.buscas, corridaID)
+}
+
+// vencerPrazo encerra a busca que chegou ao prazo total sem aceite
+func (d *DespachoService) vencerPrazo(corridaID int) {
+	defer d.concluirPendentes()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if busca, existe := d.buscas[corridaID]; existe {
+		d.encerrarBusca(busca)
+	}
+}
+
+// retentar faz uma nova rodada para uma busca que ficou sem ofertas pendentes
+func (d *DespachoService) retentar(corridaID int) {
+	defer d.concluirPendentes()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	busca, existe := d.buscas[corridaID]
+	if !existe || len(busca.pendentes) > 0 {
+		return
+	}
+	if _, err := d.proximaRodada(busca); err != nil {
+		fmt.Printf("Corrida %d: erro ao reofertar: %v\n", corridaID, err)
+	}
+}
+
+// MonitorarLotes é um processo em background que distribui as corridas das estratégias em lote
+func (d *DespachoService) MonitorarLotes(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.processarLotes()
+	}
+}
+
+// processarLotes distribui de uma vez as buscas que aguardam lote, agrupadas por estratégia.
+// Motoristas com oferta pendente de outra corrida ficam de fora para não receberem duas.
+func (d *DespachoService) processarLotes() {
+	defer d.concluirPendentes()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	ocupados := make(map[models.MotoristaID]bool)
+	for _, busca := range d.buscas {
+		for _, motoristaID := range busca.pendentes {
+			ocupados[motoristaID] = true
+		}
+	}
+
+	lotes := make(map[EstrategiaDespacho][]*buscaCorrida)
+	for _, busca := range d.buscas {
+		if !busca.aguardandoLote {
+			continue
+		}
+		if d.limiteAtingido(busca) {
+			d.encerrarBusca(busca)
+			continue
+		}
+		lotes[busca.estrategia] = append(lotes[busca.estrategia], busca)
+	}
+
+	for estrategia, buscas := range lotes {
+		var pedidos []PedidoDespacho
+		for _, busca := range buscas {
+			candidatos, err := d.candidatos(busca, ocupados)
+			if err != nil {
+				fmt.Printf("Corrida %d: erro ao buscar candidatos: %v\n", busca.corrida.ID, err)
+				continue
+			}
+			pedidos = append(pedidos, d.pedido(busca, candidatos))
+		}
+
+		escolhidos := estrategia.Distribuir(pedidos)
+		for _, busca := range buscas {
+			motoristas := escolhidos[busca.corrida.ID]
+			if len(motoristas) == 0 {
+				continue // continua aguardando o próximo lote
+			}
+			busca.aguardandoLote = false
+			busca.rodada++
+			if _, err := d.ofertar(busca, motoristas); err != nil {
+				fmt.Printf("Corrida %d: erro ao ofertar: %v\n", busca.corrida.ID, err)
+			}
+			for _, motorista := range motoristas {
+				ocupados[motorista.ID] = true
+			}
+		}
+	}
+}
+
+// candidatos retorna os motoristas que podem receber a oferta dentro do raio do embarque,
+// sem os excluídos, em ordem de classificação. Deve ser chamada com o mutex travado.
+func (d *DespachoService) candidatos(busca *buscaCorrida, excluidos map[models.MotoristaID]bool) ([]MotoristaProximo, error) {
+	corrida := busca.corrida
+
+	proximos, err := d.localizador.BuscarProximos(corrida.OrigemLat, corrida.OrigemLng, d.config.RaioKm)
+	if err != nil {
+		return nil, err
+	}
+	ordenarPorDistancia(proximos)
+
+	var candidatos []MotoristaProximo
+	for _, proximo := range proximos {
+		if !excluidos[proximo.ID] && d.podeReceber(busca, proximo.ID) {
+			candidatos = append(candidatos, proximo)
+		}
+	}
+	d.classificar(candidatos)
+	return candidatos, nil
+}
+
+// classificar ordena os candidatos pela distância acrescida de PesoAceiteKm proporcional à
+// parcela de ofertas recentes que o motorista não aceitou. Sem taxas definidas ou sem taxa
+// confiável do motorista, vale só a distância. Deve ser chamada com o mutex travado.
+func (d *DespachoService) classificar(candidatos []MotoristaProximo) {
+	if d.taxas == nil {
+		return
+	}
+	pontuacao := make(map[models.MotoristaID]float64, len(candidatos))
+	for _, candidato := range candidatos {
+		pontuacao[candidato.ID] = candidato.DistanciaKm
+		if taxa, confiavel := d.taxas.TaxaAceite(candidato.ID); confiavel {
+			pontuacao[candidato.ID] += d.config.PesoAceiteKm * (1 - taxa)
+		}
+	}
+	sort.SliceStable(candidatos, func(a, b int) bool {
+		return pontuacao[candidatos[a].ID] < pontuacao[candidatos[b].ID]
+	})
+}
+
+// podeReceber indica se o motorista pode receber oferta da corrida: não recusou, está
+// elegível para a categoria e as preferências do perfil permitem. Ocupados, suspensos e com
+// CNH vencida não recebem ofertas. Deve ser chamada com o mutex travado.
+func (d *DespachoService) podeReceber(busca *buscaCorrida, motoristaID models.MotoristaID) bool {
+	if busca.recusaram[motoristaID] {
+		return false
+	}
+	motorista, err := d.elegibilidade.VerificarElegibilidade(motoristaID)
+	return err == nil && VerificarCategoria(motorista, busca.corrida.Categoria) == nil && d.aceitaOfertas(motorista)
+}
+
+// aceitaOfertas aplica as preferências do motorista: nada de ofertas no horário de silêncio
+// nem acima do máximo por minuto. Deve ser chamada com o mutex travado.
+func (d *DespachoService) aceitaOfertas(motorista *models.Motorista) bool {
+	preferencias := motorista.PreferenciasNotificacao
+	if preferencias == nil {
+		return true
+	}
+	agora := d.agora()
+	if preferencias.Silencio != nil && preferencias.Silencio.Contem(agora) {
+		return false
+	}
+	return preferencias.MaxOfertasPorMinuto <= 0 || d.ofertasNoUltimoMinuto(motorista.ID, agora) < preferencias.MaxOfertasPorMinuto
+}
+
+// ofertasNoUltimoMinuto conta as ofertas recentes do motorista, descartando as mais antigas
+// que um minuto. Deve ser chamada com o mutex travado.
+func (d *DespachoService) ofertasNoUltimoMinuto(motoristaID models.MotoristaID, agora time.Time) int {
+	recentes := d.recentes[motoristaID]
+	for len(recentes) > 0 && agora.Sub(recentes[0]) >= time.Minute {
+		recentes = recentes[1:]
+	}
+	if len(recentes) == 0 {
+		delete(d.recentes, motoristaID)
+	} else {
+		d.recentes[motoristaID] = recentes
+	}
+	return len(recentes)
+}
+
+// primeiroDaFila escolhe, quando o embarque está em um ponto de táxi, o primeiro motorista
+// da fila que pode receber a oferta e ainda não a recebeu (se todos já receberam, recomeça
+// pelo primeiro). Retorna false se não há ponto no embarque ou ninguém elegível na fila,
+// caso em que vale a busca por proximidade. Deve ser chamada com o mutex travado.
+func (d *DespachoService) primeiroDaFila(busca *buscaCorrida) ([]MotoristaProximo, bool) {
+	if d.filas == nil {
+		return nil, false
+	}
+	fila, noPonto := d.filas.FilaDoEmbarque(busca.corrida.OrigemLat, busca.corrida.OrigemLng)
+	if !noPonto {
+		return nil, false
+	}
+
+	var elegiveis []MotoristaProximo
+	for _, motorista := range fila {
+		if d.podeReceber(busca, motorista.ID) {
+			elegiveis = append(elegiveis, motorista)
+		}
+	}
+	if len(elegiveis) == 0 {
+		return nil, false
+	}
+	for _, motorista := range elegiveis {
+		if !busca.ofertados[motorista.ID] {
+			return []MotoristaProximo{motorista}, true
+		}
+	}
+	return elegiveis[:1], true
+}
+
+// pedido monta o pedido de rodada entregue à estratégia
+func (d *DespachoService) pedido(busca *buscaCorrida, candidatos []MotoristaProximo) PedidoDespacho {
+	return PedidoDespacho{
+		CorridaID:   busca.corrida.ID,
+		Candidatos:  candidatos,
+		JaOfertados: busca.ofertados,
+	}
+}
+
+// ofertar cria uma oferta da corrida para cada motorista escolhido e a registra no histórico.
+// Deve ser chamada com o mutex travado.
+func (d *DespachoService) ofertar(busca *buscaCorrida, escolhidos []MotoristaProximo) ([]models.NotificacaoCorrida, error) {
+	corrida := busca.corrida
+
+	valor := estimarValor(corrida)
+	passageiro := corrida.PassageiroNome
+	if passageiro == "" {
+		passageiro = passageiroNomePadrao
+	}
+
+	var ofertas []models.NotificacaoCorrida
+	for _, escolhido := range escolhidos {
+		oferta := models.NotificacaoCorrida{
+			MotoristaID:    escolhido.ID,
+			CorridaID:      uint(corrida.ID),
+			PassageiroNome: passageiro,
+			Valor:          valor,
+			DistanciaKm:    arredondar(escolhido.DistanciaKm, 2),
+			TempoEstimado:  formatarETA(escolhido.DistanciaKm),
+			Origem:         corrida.Origem,
+			Destino:        corrida.Destino,
+			Cidade:         corrida.Cidade,
+			Categoria:      corrida.Categoria.OuPadrao(),
+		}
+		if err := d.criarOferta(&oferta); err != nil {
+			return ofertas, err
+		}
+		ofertas = append(ofertas, oferta)
+		busca.pendentes[oferta.ID] = oferta.MotoristaID
+		busca.ofertados[oferta.MotoristaID] = true
+		agora := d.agora()
+		d.ofertasNoUltimoMinuto(oferta.MotoristaID, agora) // descarta as antigas antes de registrar
+		d.recentes[oferta.MotoristaID] = append(d.recentes[oferta.MotoristaID], agora)
+		if d.notificador != nil {
+			d.avisos = append(d.avisos, MensagemNovaOferta(oferta))
+		}
+
+		err := d.historico.RegistrarOferta(corrida.ID, models.OfertaCorrida{
+			NotificacaoID: oferta.ID,
+			MotoristaID:   oferta.MotoristaID,
+			Rodada:        busca.rodada,
+			DistanciaKm:   oferta.DistanciaKm,
+			Status:        oferta.Status,
+			OfertadaEm:    oferta.CreatedAt,
+		})
+		if err != nil {
+			fmt.Printf("Corrida %d: erro ao registrar oferta %d: %v\n", corrida.ID, oferta.ID, err)
+		}
+	}
+
+	return ofertas, nil
+}
+
+// atualizarHistorico registra a resposta à oferta no histórico da corrida
+func (d *DespachoService) atualizarHistorico(busca *buscaCorrida, notificacaoID uint, status models.NotificacaoStatus) {
+	if err := d.historico.AtualizarOferta(busca.corrida.ID, notificacaoID, status, d.agora()); err != nil {
+		fmt.Printf("Corrida %d: erro ao atualizar oferta %d: %v\n", busca.corrida.ID, notificacaoID, err)
+	}
+}
+
+// estimarValor usa o preço da corrida, já com o acréscimo gravado na precificação, ou o
+// calcula pela tarifa da categoria (PrecoCorrida) para corridas ainda sem preço
+func estimarValor(corrida models.Corrida) float64 {
+	if corrida.Preco > 0 {
+		return corrida.Preco
+	}
+	return PrecoCorrida(corrida)
+}
+
+// formatarETA estima o tempo até o embarque na velocidade média urbana
+func formatarETA(distanciaKm float64) string {
+	return fmt.Sprintf("%d min", minutosDeViagem(distanciaKm))
+}
+
+// minutosDeViagem estima, em minutos inteiros (no mínimo 1), o tempo para percorrer a
+// distância na velocidade média urbana
+func minutosDeViagem(distanciaKm float64) int {
+	minutos := int(math.Ceil(distanciaKm / VelocidadeMediaKmH * 60))
+	if minutos < 1 {
+		minutos = 1
+	}
+	return minutos
+}
+
+func arredondar(valor float64, casas int) float64 {
+	fator := math.Pow(10, float64(casas))
+	return math.Round(valor*fator) / fator
+}
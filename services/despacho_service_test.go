@@ -0,0 +1,462 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// Ponto de embarque usado nos testes (Marco Zero, Recife)
+const (
+	embarqueLat = -8.0631
+	embarqueLng = -34.8711
+)
+
+// localizadorFake devolve os motoristas dentro do raio a partir de posições fixas
+type localizadorFake struct {
+	posicoes map[models.MotoristaID][2]float64
+	mutex    sync.Mutex
+}
+
+func (l *localizadorFake) BuscarProximos(lat, lng, raioKm float64) ([]MotoristaProximo, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var proximos []MotoristaProximo
+	for id, pos := range l.posicoes {
+		distancia := DistanciaKm(lat, lng, pos[0], pos[1])
+		if distancia <= raioKm {
+			proximos = append(proximos, MotoristaProximo{ID: id, Lat: pos[0], Lng: pos[1], DistanciaKm: distancia})
+		}
+	}
+	return proximos, nil
+}
+
+func (l *localizadorFake) posicionar(id models.MotoristaID, lat, lng float64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.posicoes[id] = [2]float64{lat, lng}
+}
+
+// historicoFake guarda o histórico de ofertas por corrida
+type historicoFake struct {
+	ofertas map[int][]models.OfertaCorrida
+}
+
+func (h *historicoFake) RegistrarOferta(corridaID int, oferta models.OfertaCorrida) error {
+	h.ofertas[corridaID] = append(h.ofertas[corridaID], oferta)
+	return nil
+}
+
+func (h *historicoFake) AtualizarOferta(corridaID int, notificacaoID uint, status models.NotificacaoStatus, em time.Time) error {
+	for i := range h.ofertas[corridaID] {
+		if h.ofertas[corridaID][i].NotificacaoID == notificacaoID {
+			h.ofertas[corridaID][i].Status = status
+			h.ofertas[corridaID][i].RespondidaEm = &em
+		}
+	}
+	return nil
+}
+
+// novoDespachoTeste cria um despacho que guarda as ofertas e o histórico em memória
+func novoDespachoTeste(localizador LocalizadorMotoristas, elegibilidade ElegibilidadeService, config ConfigDespacho) (*DespachoService, *[]models.NotificacaoCorrida, *historicoFake) {
+	criadas := []models.NotificacaoCorrida{}
+	historico := &historicoFake{ofertas: map[int][]models.OfertaCorrida{}}
+	despacho := NewDespachoService(localizador, elegibilidade, historico, config)
+	despacho.criarOferta = func(notificacao *models.NotificacaoCorrida) error {
+		notificacao.ID = uint(len(criadas) + 1)
+		notificacao.Status = models.NotificacaoPendente
+		notificacao.CreatedAt = time.Now()
+		criadas = append(criadas, *notificacao)
+		return nil
+	}
+	despacho.retirarOferta = func(notificacaoID uint) error { return nil }
+	return despacho, &criadas, historico
+}
+
+// responder simula a resposta (ou expiração) de uma oferta criada pelo despacho
+func responder(despacho *DespachoService, oferta models.NotificacaoCorrida, status models.NotificacaoStatus) {
+	oferta.Status = status
+	despacho.OuvirNotificacoes(oferta)
+}
+
+func TestDistanciaKm(t *testing.T) {
+	// Recife -> Olinda, aproximadamente 6,3 km em linha reta
+	distancia := DistanciaKm(-8.0631, -34.8711, -8.0089, -34.8553)
+	assert.InDelta(t, 6.3, distancia, 0.1)
+	assert.Zero(t, DistanciaKm(embarqueLat, embarqueLng, embarqueLat, embarqueLng))
+}
+
+func TestDespachoService(t *testing.T) {
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"perto":   {-8.0700, -34.8711}, // ~0,77 km
+		"medio":   {-8.0750, -34.8800}, // ~1,6 km
+		"ocupado": {-8.0640, -34.8711}, // ~0,1 km
+		"longe":   {-8.1200, -34.9000}, // ~7 km
+	}}
+
+	t.Run("Cria ofertas para motoristas elegíveis dentro do raio", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.bloqueados["ocupado"] = &ErroElegibilidade{Codigo: CodigoMotoristaOcupado}
+		despacho, criadas, _ := novoDespachoTeste(localizador, elegibilidade, ConfigDespacho{})
+
+		ofertas, err := despacho.Despachar(models.Corrida{
+			ID:             10,
+			PassageiroNome: "Maria Silva",
+			Origem:         "Rua A, 123",
+			Destino:        "Shopping Recife",
+			OrigemLat:      embarqueLat,
+			OrigemLng:      embarqueLng,
+			Preco:          12.50,
+		})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 2)
+		assert.Len(t, *criadas, 2)
+
+		// Ordenadas da mais próxima para a mais distante
+		assert.Equal(t, models.MotoristaID("perto"), ofertas[0].MotoristaID)
+		assert.Equal(t, models.MotoristaID("medio"), ofertas[1].MotoristaID)
+
+		oferta := ofertas[0]
+		assert.Equal(t, uint(10), oferta.CorridaID)
+		assert.Equal(t, "Maria Silva", oferta.PassageiroNome)
+		assert.Equal(t, 12.50, oferta.Valor)
+		assert.InDelta(t, 0.77, oferta.DistanciaKm, 0.05)
+		assert.Equal(t, "2 min", oferta.TempoEstimado)
+		assert.Equal(t, "Rua A, 123", oferta.Origem)
+	})
+
+	t.Run("Valor é estimado pela distância até o destino", func(t *testing.T) {
+		despacho, _, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{})
+
+		ofertas, err := despacho.Despachar(models.Corrida{
+			ID:         11,
+			OrigemLat:  embarqueLat,
+			OrigemLng:  embarqueLng,
+			DestinoLat: -8.0721,
+			DestinoLng: -34.8711, // ~1 km
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, ofertas)
+		comum := TarifasPorCategoria[models.CategoriaComum]
+		assert.InDelta(t, comum.Base+comum.PorKm*1.0, ofertas[0].Valor, 0.05)
+		assert.Equal(t, passageiroNomePadrao, ofertas[0].PassageiroNome)
+	})
+
+	t.Run("Motoristas ofertados recebem o aviso da nova oferta", func(t *testing.T) {
+		roteador, caixa, _ := novoNotificadorTeste()
+		despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{EstrategiaPadrao: NewEstrategiaSequencial()})
+		despacho.DefinirNotificador(roteador)
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 13, PassageiroNome: "Maria Silva", OrigemLat: embarqueLat, OrigemLng: embarqueLng, Preco: 12.50})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 1)
+
+		mensagens := caixa.Mensagens(models.Destinatario{Tipo: models.DestinatarioMotorista, ID: "ocupado"})
+		require.Len(t, mensagens, 1)
+		assert.Equal(t, models.EventoMensagemNovaOferta, mensagens[0].Evento)
+		assert.Equal(t, "13", mensagens[0].Dados["corrida_id"])
+		assert.Equal(t, "12.50", mensagens[0].Dados["valor"])
+		assert.Contains(t, mensagens[0].Texto, "Maria Silva")
+
+		// A reoferta após a recusa também avisa o próximo motorista
+		responder(despacho, ofertas[0], models.NotificacaoRecusada)
+		require.Len(t, *criadas, 2)
+		assert.Len(t, caixa.Mensagens(models.Destinatario{Tipo: models.DestinatarioMotorista, ID: string((*criadas)[1].MotoristaID)}), 1)
+	})
+
+	t.Run("Corrida sem coordenadas de embarque não é despachada", func(t *testing.T) {
+		despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{})
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 12})
+		require.NoError(t, err)
+		assert.Empty(t, ofertas)
+		assert.Empty(t, *criadas)
+	})
+
+	t.Run("Criar corrida dispara o despacho e registra o histórico", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		corridas := NewCorridaService(elegibilidade)
+		despacho, criadas, _ := novoDespachoTeste(localizador, elegibilidade, ConfigDespacho{})
+		despacho.historico = corridas
+		corridas.RegistrarOuvinte(despacho.OuvirCorridas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+
+		require.Len(t, *criadas, 3)
+		assert.Equal(t, uint(corrida.ID), (*criadas)[0].CorridaID)
+
+		atual, _ := corridas.GetCorridaPorID(corrida.ID)
+		require.Len(t, atual.Ofertas, 3)
+		assert.Equal(t, 1, atual.Ofertas[0].Rodada)
+		assert.Equal(t, models.NotificacaoPendente, atual.Ofertas[0].Status)
+
+		// O aceite da corrida encerra a busca
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "perto", 0))
+		assert.NotContains(t, despacho.buscas, corrida.ID)
+	})
+}
+
+func TestDespachoReoferta(t *testing.T) {
+	novoLocalizador := func() *localizadorFake {
+		return &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+			"perto": {-8.0700, -34.8711},
+			"medio": {-8.0750, -34.8800},
+		}}
+	}
+	corrida := models.Corrida{ID: 20, OrigemLat: embarqueLat, OrigemLng: embarqueLng}
+
+	t.Run("Nova rodada quando todos respondem, sem quem recusou", func(t *testing.T) {
+		despacho, criadas, historico := novoDespachoTeste(novoLocalizador(), novaElegibilidadeFake(), ConfigDespacho{})
+
+		primeira, err := despacho.Despachar(corrida)
+		require.NoError(t, err)
+		require.Len(t, primeira, 2)
+
+		responder(despacho, primeira[0], models.NotificacaoRecusada)
+		assert.Len(t, *criadas, 2, "ainda há oferta pendente na rodada")
+
+		responder(despacho, primeira[1], models.NotificacaoExpirada)
+		require.Len(t, *criadas, 3)
+		assert.Equal(t, models.MotoristaID("medio"), (*criadas)[2].MotoristaID)
+
+		ofertas := historico.ofertas[corrida.ID]
+		require.Len(t, ofertas, 3)
+		assert.Equal(t, models.NotificacaoRecusada, ofertas[0].Status)
+		assert.NotNil(t, ofertas[0].RespondidaEm)
+		assert.Equal(t, models.NotificacaoExpirada, ofertas[1].Status)
+		assert.Equal(t, 2, ofertas[2].Rodada)
+		assert.Equal(t, models.NotificacaoPendente, ofertas[2].Status)
+	})
+
+	t.Run("Busca termina após o máximo de rodadas", func(t *testing.T) {
+		despacho, criadas, _ := novoDespachoTeste(novoLocalizador(), novaElegibilidadeFake(), ConfigDespacho{MaxRodadas: 2})
+
+		primeira, _ := despacho.Despachar(corrida)
+		responder(despacho, primeira[0], models.NotificacaoExpirada)
+		responder(despacho, primeira[1], models.NotificacaoExpirada)
+		require.Len(t, *criadas, 4)
+
+		responder(despacho, (*criadas)[2], models.NotificacaoExpirada)
+		responder(despacho, (*criadas)[3], models.NotificacaoExpirada)
+
+		assert.Len(t, *criadas, 4)
+		assert.NotContains(t, despacho.buscas, corrida.ID)
+	})
+
+	t.Run("Busca termina após o prazo total", func(t *testing.T) {
+		despacho, criadas, _ := novoDespachoTeste(novoLocalizador(), novaElegibilidadeFake(), ConfigDespacho{PrazoBusca: time.Minute})
+		agora := time.Now()
+		despacho.agora = func() time.Time { return agora }
+
+		primeira, _ := despacho.Despachar(corrida)
+		agora = agora.Add(2 * time.Minute)
+		responder(despacho, primeira[0], models.NotificacaoExpirada)
+		responder(despacho, primeira[1], models.NotificacaoExpirada)
+
+		assert.Len(t, *criadas, 2)
+		assert.NotContains(t, despacho.buscas, corrida.ID)
+	})
+
+	t.Run("Oferta aceita encerra a busca", func(t *testing.T) {
+		despacho, criadas, historico := novoDespachoTeste(novoLocalizador(), novaElegibilidadeFake(), ConfigDespacho{})
+
+		primeira, _ := despacho.Despachar(corrida)
+		responder(despacho, primeira[0], models.NotificacaoAceita)
+		responder(despacho, primeira[1], models.NotificacaoExpirada)
+
+		assert.Len(t, *criadas, 2)
+		assert.Equal(t, models.NotificacaoAceita, historico.ofertas[corrida.ID][0].Status)
+		assert.NotContains(t, despacho.buscas, corrida.ID)
+	})
+
+	t.Run("Prazo total retira as ofertas pendentes e encerra a corrida", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		roteador, caixa, _ := novoNotificadorTeste()
+		corridas := NewCorridaService(elegibilidade)
+		corridas.RegistrarOuvinte(NewAvisosPassageiro(roteador).OuvirCorridas)
+		despacho, criadas, _ := novoDespachoTeste(novoLocalizador(), elegibilidade, ConfigDespacho{PrazoBusca: 20 * time.Millisecond})
+		despacho.historico = corridas
+		despacho.DefinirEncerrador(corridas)
+		var retiradas []uint
+		var mutex sync.Mutex
+		despacho.retirarOferta = func(notificacaoID uint) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			retiradas = append(retiradas, notificacaoID)
+			return nil
+		}
+		corridas.RegistrarOuvinte(despacho.OuvirCorridas)
+
+		nova, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 42, OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		require.Len(t, *criadas, 2)
+
+		// O passageiro é avisado depois que as ofertas são retiradas e a corrida encerrada
+		passageiro := models.Destinatario{Tipo: models.DestinatarioPassageiro, ID: "42"}
+		require.Eventually(t, func() bool {
+			return len(caixa.Mensagens(passageiro)) == 1
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, models.EventoMensagemCorridaCancelada, caixa.Mensagens(passageiro)[0].Evento)
+
+		atual, err := corridas.GetCorridaPorID(nova.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCanceladaSemMotorista, atual.Status)
+		mutex.Lock()
+		assert.ElementsMatch(t, []uint{(*criadas)[0].ID, (*criadas)[1].ID}, retiradas)
+		mutex.Unlock()
+	})
+
+	t.Run("Sem candidatos a rodada é repetida após o intervalo, sem gastar rodadas", func(t *testing.T) {
+		localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{}}
+		despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{MaxRodadas: 1, IntervaloRetentativa: 5 * time.Millisecond})
+
+		ofertas, err := despacho.Despachar(corrida)
+		require.NoError(t, err)
+		assert.Empty(t, ofertas)
+
+		// Várias tentativas sem candidatos não esgotam a única rodada
+		time.Sleep(30 * time.Millisecond)
+		localizador.posicionar("chegou", -8.0700, -34.8711)
+		assert.Eventually(t, func() bool {
+			despacho.mutex.Lock()
+			defer despacho.mutex.Unlock()
+			return len(*criadas) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestDespachoPorCategoria(t *testing.T) {
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"carro":      {-8.0640, -34.8711},
+		"moto":       {-8.0700, -34.8711},
+		"moto-sem-a": {-8.0650, -34.8711},
+	}}
+	elegibilidade := novaElegibilidadeFake()
+	elegibilidade.perfis["moto"] = models.Motorista{CategoriaVeiculo: models.CategoriaMoto, CategoriaCNH: models.CategoriaA}
+	elegibilidade.perfis["moto-sem-a"] = models.Motorista{CategoriaVeiculo: models.CategoriaMoto, CategoriaCNH: models.CategoriaB}
+
+	t.Run("Corrida de moto só é ofertada a motos com CNH A", func(t *testing.T) {
+		despacho, _, _ := novoDespachoTeste(localizador, elegibilidade, ConfigDespacho{})
+
+		ofertas, err := despacho.Despachar(models.Corrida{
+			ID:         40,
+			Categoria:  models.CategoriaMoto,
+			OrigemLat:  embarqueLat,
+			OrigemLng:  embarqueLng,
+			DestinoLat: -8.0721,
+			DestinoLng: -34.8711,
+		})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 1)
+		assert.Equal(t, models.MotoristaID("moto"), ofertas[0].MotoristaID)
+		assert.InDelta(t, TarifaDa(models.CategoriaMoto).Calcular(1.0), ofertas[0].Valor, 0.05)
+	})
+
+	t.Run("Corrida sem categoria é comum", func(t *testing.T) {
+		despacho, _, _ := novoDespachoTeste(localizador, elegibilidade, ConfigDespacho{})
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 41, OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 1)
+		assert.Equal(t, models.MotoristaID("carro"), ofertas[0].MotoristaID)
+	})
+
+	t.Run("Aceite direto por veículo de outra categoria é recusado", func(t *testing.T) {
+		corridas := NewCorridaService(elegibilidade)
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, Categoria: models.CategoriaMoto})
+		require.NoError(t, err)
+
+		err = corridas.AceitarCorrida(corrida.ID, "carro", 0)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoCategoriaIncompativel, erroElegibilidade.Codigo)
+		assert.False(t, elegibilidade.reservados["carro"])
+		assert.Zero(t, elegibilidade.reservas, "a categoria é conferida antes da reserva")
+
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "moto", 0))
+
+		_, err = corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, Categoria: "helicoptero"})
+		assert.ErrorIs(t, err, ErrCategoriaInvalida)
+	})
+}
+
+func TestDespachoPreferenciasMotorista(t *testing.T) {
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"dormindo": {-8.0640, -34.8711},
+		"limitado": {-8.0700, -34.8711},
+	}}
+	elegibilidade := novaElegibilidadeFake()
+	elegibilidade.perfis["dormindo"] = models.Motorista{CategoriaCNH: models.CategoriaB, PreferenciasNotificacao: &models.PreferenciasNotificacao{
+		Silencio: &models.HorarioSilencio{Inicio: "22:00", Fim: "06:00"},
+	}}
+	elegibilidade.perfis["limitado"] = models.Motorista{CategoriaCNH: models.CategoriaB, PreferenciasNotificacao: &models.PreferenciasNotificacao{
+		MaxOfertasPorMinuto: 2,
+	}}
+
+	despacho, _, _ := novoDespachoTeste(localizador, elegibilidade, ConfigDespacho{})
+	agora := time.Date(2025, 6, 1, 23, 0, 0, 0, time.Local)
+	despacho.agora = func() time.Time { return agora }
+
+	ofertados := func(corridaID int) []models.MotoristaID {
+		ofertas, err := despacho.Despachar(models.Corrida{ID: corridaID, OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		var motoristas []models.MotoristaID
+		for _, oferta := range ofertas {
+			motoristas = append(motoristas, oferta.MotoristaID)
+		}
+		return motoristas
+	}
+
+	// No silêncio só o limitado recebe, até o máximo por minuto
+	assert.Equal(t, []models.MotoristaID{"limitado"}, ofertados(1))
+	assert.Equal(t, []models.MotoristaID{"limitado"}, ofertados(2))
+	assert.Empty(t, ofertados(3))
+
+	// Um minuto depois o limite libera; às 6h o silêncio termina
+	agora = agora.Add(time.Minute)
+	assert.Equal(t, []models.MotoristaID{"limitado"}, ofertados(4))
+	agora = time.Date(2025, 6, 2, 6, 0, 0, 0, time.Local)
+	assert.ElementsMatch(t, []models.MotoristaID{"dormindo", "limitado"}, ofertados(5))
+}
+
+// taxasAceiteFake devolve taxas de aceite fixas; motoristas sem taxa não têm taxa confiável
+type taxasAceiteFake map[models.MotoristaID]float64
+
+func (t taxasAceiteFake) TaxaAceite(motoristaID models.MotoristaID) (float64, bool) {
+	taxa, existe := t[motoristaID]
+	return taxa, existe
+}
+
+func TestDespachoClassificacaoPorTaxaAceite(t *testing.T) {
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"recusa":  {-8.0640, -34.8711}, // ~0,1 km, nunca aceita
+		"aceita":  {-8.0700, -34.8711}, // ~0,77 km, sempre aceita
+		"novato":  {-8.0750, -34.8800}, // ~1,6 km, sem taxa confiável
+		"mediano": {-8.0680, -34.8711}, // ~0,55 km, aceita metade
+	}}
+	despacho, _, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{EstrategiaPadrao: NewEstrategiaSequencial()})
+
+	corrida := models.Corrida{ID: 1, OrigemLat: embarqueLat, OrigemLng: embarqueLng}
+	ofertas, err := despacho.Despachar(corrida)
+	require.NoError(t, err)
+	require.Len(t, ofertas, 1)
+	assert.Equal(t, models.MotoristaID("recusa"), ofertas[0].MotoristaID, "sem taxas vale a distância")
+
+	despacho.DefinirTaxasAceite(taxasAceiteFake{"recusa": 0, "aceita": 1, "mediano": 0.5})
+	busca := despacho.buscas[corrida.ID]
+	candidatos, err := despacho.candidatos(busca, nil)
+	require.NoError(t, err)
+	var ordem []models.MotoristaID
+	for _, candidato := range candidatos {
+		ordem = append(ordem, candidato.ID)
+	}
+	// Pontuações: aceita 0,77; mediano 0,55+0,5; recusa 0,1+1; novato 1,6
+	assert.Equal(t, []models.MotoristaID{"aceita", "mediano", "recusa", "novato"}, ordem)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+)
+
+// TimeoutHeartbeatPadrao é o tempo sem sinal após o qual o motorista fica offline
+const TimeoutHeartbeatPadrao = 90 * time.Second
+
+// ErrMotoristaOffline indica um heartbeat, ou um pedido para ficar offline, de um motorista
+// que nunca ficou online no registro
+var ErrMotoristaOffline = errors.New("motorista está offline; fique online primeiro")
+
+// OuvinteDisponibilidade recebe uma cópia do estado do motorista após cada mudança
+// (online, offline, heartbeat, expiração ou início e fim de corrida)
+type OuvinteDisponibilidade func(disponibilidade models.DisponibilidadeMotorista)
+
+// RegistroDisponibilidade guarda o status operacional e a posição atual dos motoristas.
+// Motoristas disponíveis que param de enviar heartbeats são marcados offline após o timeout.
+// Apenas os disponíveis ficam no índice espacial usado pelas buscas por proximidade.
+// Implementa LocalizadorMotoristas para o despacho de ofertas.
+type RegistroDisponibilidade struct {
+	motoristas    map[models.MotoristaID]*models.DisponibilidadeMotorista
+	indice        *IndiceEspacial
+	mutex         sync.RWMutex
+	elegibilidade ElegibilidadeService
+	timeout       time.Duration
+	agora         func() time.Time
+	ouvintes      []OuvinteDisponibilidade
+}
+
+// NewRegistroDisponibilidade cria o registro e inicia a verificação de heartbeats
+func NewRegistroDisponibilidade(elegibilidade ElegibilidadeService, timeout time.Duration) *RegistroDisponibilidade {
+	if timeout <= 0 {
+		timeout = TimeoutHeartbeatPadrao
+	}
+	registro := &RegistroDisponibilidade{
+		motoristas:    make(map[models.MotoristaID]*models.DisponibilidadeMotorista),
+		indice:        NewIndiceEspacial(TamanhoCelulaPadraoKm),
+		elegibilidade: elegibilidade,
+		timeout:       timeout,
+		agora:         time.Now,
+	}
+	go registro.MonitorarHeartbeats()
+	return registro
+}
+
+// NewRegistroDisponibilidadeFromEnv cria o registro lendo o timeout de DRIVER_HEARTBEAT_TIMEOUT
+func NewRegistroDisponibilidadeFromEnv(elegibilidade ElegibilidadeService) *RegistroDisponibilidade {
+	timeout, err := time.ParseDuration(os.Getenv("DRIVER_HEARTBEAT_TIMEOUT"))
+	if err != nil {
+		timeout = TimeoutHeartbeatPadrao
+	}
+	return NewRegistroDisponibilidade(elegibilidade, timeout)
+}
+
+// RegistrarOuvinte inscreve uma função para receber as mudanças de estado dos motoristas.
+// Os ouvintes são chamados depois que o mutex é liberado, na ordem de registro.
+func (r *RegistroDisponibilidade) RegistrarOuvinte(ouvinte OuvinteDisponibilidade) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ouvintes = append(r.ouvintes, ouvinte)
+}
+
+// publicarPendentes entrega aos ouvintes as mudanças acumuladas durante uma operação.
+// Deve ser adiada antes de travar o mutex, para rodar depois do Unlock adiado.
+func (r *RegistroDisponibilidade) publicarPendentes(mudancas *[]models.DisponibilidadeMotorista) {
+	if len(*mudancas) == 0 {
+		return
+	}
+
+	r.mutex.RLock()
+	ouvintes := append([]OuvinteDisponibilidade(nil), r.ouvintes...)
+	r.mutex.RUnlock()
+
+	for _, mudanca := range *mudancas {
+		for _, ouvinte := range ouvintes {
+			ouvinte(mudanca)
+		}
+	}
+}
+
+// FicarOnline coloca o motorista como disponível na posição informada.
+// Um motorista que já está em corrida volta como ocupado. Só motoristas cadastrados e
+// elegíveis entram no registro.
+func (r *RegistroDisponibilidade) FicarOnline(motoristaID models.MotoristaID, lat, lng float64) (*models.DisponibilidadeMotorista, error) {
+	status := models.StatusDisponivel
+	if _, err := r.elegibilidade.VerificarElegibilidade(motoristaID); err != nil {
+		var erroElegibilidade *ErroElegibilidade
+		if !errors.As(err, &erroElegibilidade) || erroElegibilidade.Codigo != CodigoMotoristaOcupado {
+			return nil, err
+		}
+		status = models.StatusOcupado
+	}
+
+	var mudancas []models.DisponibilidadeMotorista
+	defer r.publicarPendentes(&mudancas)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	disponibilidade := &models.DisponibilidadeMotorista{
+		MotoristaID: motoristaID,
+		Status:      status,
+		Latitude:    lat,
+		Longitude:   lng,
+		UltimoSinal: r.agora(),
+	}
+	r.motoristas[motoristaID] = disponibilidade
+	r.indexar(disponibilidade)
+
+	copia := *disponibilidade
+	mudancas = append(mudancas, copia)
+	return &copia, nil
+}
+
+// FicarOffline remove o motorista das buscas por proximidade. Motoristas fora do registro
+// (nunca ficaram online ou não existem) recebem ErrMotoristaOffline; para quem já está
+// offline nada muda e nenhuma mudança é publicada.
+func (r *RegistroDisponibilidade) FicarOffline(motoristaID models.MotoristaID) (*models.DisponibilidadeMotorista, error) {
+	var mudancas []models.DisponibilidadeMotorista
+	defer r.publicarPendentes(&mudancas)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	disponibilidade, existe := r.motoristas[motoristaID]
+	if !existe {
+		return nil, ErrMotoristaOffline
+	}
+	if disponibilidade.Status == models.StatusOffline {
+		copia := *disponibilidade
+		return &copia, nil
+	}
+	disponibilidade.Status = models.StatusOffline
+	r.indexar(disponibilidade)
+
+	copia := *disponibilidade
+	mudancas = append(mudancas, copia)
+	return &copia, nil
+}
+
+// Heartbeat atualiza a posição de um motorista online e renova o seu prazo
+func (r *RegistroDisponibilidade) Heartbeat(motoristaID models.MotoristaID, lat, lng float64) (*models.DisponibilidadeMotorista, error) {
+	var mudancas []models.DisponibilidadeMotorista
+	defer r.publicarPendentes(&mudancas)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	disponibilidade, existe := r.motoristas[motoristaID]
+	if !existe || disponibilidade.Status == models.StatusOffline {
+		return nil, ErrMotoristaOffline
+	}
+
+	disponibilidade.Latitude = lat
+	disponibilidade.Longitude = lng
+	disponibilidade.UltimoSinal = r.agora()
+	r.indexar(disponibilidade)
+
+	copia := *disponibilidade
+	mudancas = append(mudancas, copia)
+	return &copia, nil
+}
+
+// Consultar retorna o estado atual do motorista; desconhecidos são tratados como offline
+func (r *RegistroDisponibilidade) Consultar(motoristaID models.MotoristaID) models.DisponibilidadeMotorista {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if disponibilidade, existe := r.motoristas[motoristaID]; existe {
+		return *disponibilidade
+	}
+	return models.DisponibilidadeMotorista{MotoristaID: motoristaID, Status: models.StatusOffline}
+}
+
+// ExpirarInativos marca offline os motoristas disponíveis sem heartbeat dentro do timeout.
+// Motoristas ocupados não enviam heartbeats e por isso não expiram.
+func (r *RegistroDisponibilidade) ExpirarInativos() []models.MotoristaID {
+	var mudancas []models.DisponibilidadeMotorista
+	defer r.publicarPendentes(&mudancas)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	limite := r.agora().Add(-r.timeout)
+	var expirados []models.MotoristaID
+	for id, disponibilidade := range r.motoristas {
+		if disponibilidade.Status == models.StatusDisponivel && disponibilidade.UltimoSinal.Before(limite) {
+			disponibilidade.Status = models.StatusOffline
+			r.indexar(disponibilidade)
+			expirados = append(expirados, id)
+			mudancas = append(mudancas, *disponibilidade)
+		}
+	}
+	return expirados
+}
+
+// MonitorarHeartbeats é um processo em background que expira os motoristas sem sinal
+func (r *RegistroDisponibilidade) MonitorarHeartbeats() {
+	ticker := time.NewTicker(r.timeout / 3)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, id := range r.ExpirarInativos() {
+			fmt.Printf("Motorista %s: marcado como offline por falta de heartbeat.\n", id)
+		}
+	}
+}
+
+// BuscarProximos retorna os motoristas disponíveis dentro do raio, do mais próximo ao mais distante
+func (r *RegistroDisponibilidade) BuscarProximos(lat, lng, raioKm float64) ([]MotoristaProximo, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.indice.BuscarRaio(lat, lng, raioKm), nil
+}
+
+// BuscarMaisProximos retorna até k motoristas disponíveis dentro do raio
+func (r *RegistroDisponibilidade) BuscarMaisProximos(lat, lng float64, k int, raioKm float64) []MotoristaProximo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.indice.BuscarMaisProximos(lat, lng, k, raioKm)
+}
+
+// ContarDisponiveisPorZona conta os motoristas disponíveis em cada zona (oferta da tarifa dinâmica)
+func (r *RegistroDisponibilidade) ContarDisponiveisPorZona(zonaDe func(lat, lng float64) string) map[string]int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	contagem := make(map[string]int)
+	for _, disponibilidade := range r.motoristas {
+		if disponibilidade.Status == models.StatusDisponivel {
+			contagem[zonaDe(disponibilidade.Latitude, disponibilidade.Longitude)]++
+		}
+	}
+	return contagem
+}
+
+// indexar mantém no índice espacial apenas os motoristas disponíveis.
+// Deve ser chamada com o mutex travado.
+func (r *RegistroDisponibilidade) indexar(disponibilidade *models.DisponibilidadeMotorista) {
+	if disponibilidade.Status == models.StatusDisponivel {
+		r.indice.Atualizar(disponibilidade.MotoristaID, disponibilidade.Latitude, disponibilidade.Longitude)
+	} else {
+		r.indice.Remover(disponibilidade.MotoristaID)
+	}
+}
+
+// OuvirCorridas mantém o status em sincronia com as corridas. Deve ser registrado no CorridaService.
+func (r *RegistroDisponibilidade) OuvirCorridas(evento EventoCorrida) {
+	motoristaID := evento.Corrida.MotoristaID
+	if motoristaID == "" {
+		return
+	}
+
+	var mudancas []models.DisponibilidadeMotorista
+	defer r.publicarPendentes(&mudancas)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	disponibilidade, existe := r.motoristas[motoristaID]
+	switch evento.Tipo {
+	case EventoCorridaAceita:
+		if !existe {
+			disponibilidade = &models.DisponibilidadeMotorista{MotoristaID: motoristaID}
+			r.motoristas[motoristaID] = disponibilidade
+		}
+		disponibilidade.Status = models.StatusOcupado
+		r.indexar(disponibilidade)
+		mudancas = append(mudancas, *disponibilidade)
+	case EventoCorridaEncerrada:
+		// Ao fim da corrida o prazo recomeça, pois não houve heartbeats durante ela
+		if existe && disponibilidade.Status == models.StatusOcupado {
+			disponibilidade.Status = models.StatusDisponivel
+			disponibilidade.UltimoSinal = r.agora()
+			if CoordenadasValidas(evento.Corrida.MotoristaLat, evento.Corrida.MotoristaLng) {
+				disponibilidade.Latitude = evento.Corrida.MotoristaLat
+				disponibilidade.Longitude = evento.Corrida.MotoristaLng
+			}
+			r.indexar(disponibilidade)
+			mudancas = append(mudancas, *disponibilidade)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func TestRegistroDisponibilidade(t *testing.T) {
+	t.Run("Motorista online aparece nas buscas por proximidade", func(t *testing.T) {
+		registro := NewRegistroDisponibilidade(novaElegibilidadeFake(), time.Minute)
+
+		disponibilidade, err := registro.FicarOnline("1", -8.0700, -34.8711)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusDisponivel, disponibilidade.Status)
+
+		proximos, err := registro.BuscarProximos(embarqueLat, embarqueLng, RaioDespachoPadraoKm)
+		require.NoError(t, err)
+		require.Len(t, proximos, 1)
+		assert.Equal(t, models.MotoristaID("1"), proximos[0].ID)
+	})
+
+	t.Run("Motorista inelegível não fica online", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.bloqueados["2"] = &ErroElegibilidade{Codigo: CodigoMotoristaSuspenso}
+		registro := NewRegistroDisponibilidade(elegibilidade, time.Minute)
+
+		_, err := registro.FicarOnline("2", -8.0700, -34.8711)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, models.StatusOffline, registro.Consultar("2").Status)
+	})
+
+	t.Run("Motorista desconhecido não entra no registro", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.bloqueados["99"] = &ErroElegibilidade{Codigo: CodigoMotoristaNaoEncontrado}
+		registro := NewRegistroDisponibilidade(elegibilidade, time.Minute)
+		var mudancas []models.DisponibilidadeMotorista
+		registro.RegistrarOuvinte(func(disponibilidade models.DisponibilidadeMotorista) {
+			mudancas = append(mudancas, disponibilidade)
+		})
+
+		_, err := registro.FicarOnline("99", -8.0700, -34.8711)
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoMotoristaNaoEncontrado, erroElegibilidade.Codigo)
+
+		_, err = registro.FicarOffline("99")
+		assert.ErrorIs(t, err, ErrMotoristaOffline)
+		assert.Empty(t, registro.motoristas)
+		assert.Empty(t, mudancas)
+
+		// Ficar offline de novo não publica outra mudança
+		_, err = registro.FicarOnline("1", -8.0700, -34.8711)
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			disponibilidade, err := registro.FicarOffline("1")
+			require.NoError(t, err)
+			assert.Equal(t, models.StatusOffline, disponibilidade.Status)
+		}
+		assert.Len(t, mudancas, 2)
+	})
+
+	t.Run("Motorista em corrida volta como ocupado", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.bloqueados["3"] = &ErroElegibilidade{Codigo: CodigoMotoristaOcupado}
+		registro := NewRegistroDisponibilidade(elegibilidade, time.Minute)
+
+		disponibilidade, err := registro.FicarOnline("3", -8.0700, -34.8711)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusOcupado, disponibilidade.Status)
+
+		proximos, _ := registro.BuscarProximos(embarqueLat, embarqueLng, RaioDespachoPadraoKm)
+		assert.Empty(t, proximos)
+	})
+
+	t.Run("Heartbeat atualiza a posição e exige estar online", func(t *testing.T) {
+		registro := NewRegistroDisponibilidade(novaElegibilidadeFake(), time.Minute)
+
+		_, err := registro.Heartbeat("4", -8.0700, -34.8711)
+		assert.ErrorIs(t, err, ErrMotoristaOffline)
+
+		_, err = registro.FicarOnline("4", -8.0700, -34.8711)
+		require.NoError(t, err)
+		disponibilidade, err := registro.Heartbeat("4", -8.0650, -34.8720)
+		require.NoError(t, err)
+		assert.Equal(t, -8.0650, disponibilidade.Latitude)
+
+		_, err = registro.FicarOffline("4")
+		require.NoError(t, err)
+		_, err = registro.Heartbeat("4", -8.0650, -34.8720)
+		assert.ErrorIs(t, err, ErrMotoristaOffline)
+	})
+
+	t.Run("Sem heartbeat dentro do timeout o motorista fica offline", func(t *testing.T) {
+		relogio := novoRelogioTeste(time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC))
+		registro := NewRegistroDisponibilidade(novaElegibilidadeFake(), time.Minute)
+		registro.agora = relogio.Agora
+		registro.FicarOnline("5", -8.0700, -34.8711)
+		registro.FicarOnline("6", -8.0700, -34.8711)
+
+		relogio.Avancar(40 * time.Second)
+		registro.Heartbeat("6", -8.0700, -34.8711)
+
+		relogio.Avancar(30 * time.Second)
+		expirados := registro.ExpirarInativos()
+
+		assert.Equal(t, []models.MotoristaID{"5"}, expirados)
+		assert.Equal(t, models.StatusOffline, registro.Consultar("5").Status)
+		assert.Equal(t, models.StatusDisponivel, registro.Consultar("6").Status)
+	})
+
+	t.Run("Corridas aceitas e encerradas alteram o status", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		relogio := novoRelogioTeste(time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC))
+		registro := NewRegistroDisponibilidade(elegibilidade, time.Minute)
+		registro.agora = relogio.Agora
+		corridas := NewCorridaService(elegibilidade)
+		corridas.RegistrarOuvinte(registro.OuvirCorridas)
+
+		registro.FicarOnline("7", -8.0700, -34.8711)
+		corrida, _ := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "7", 0))
+		assert.Equal(t, models.StatusOcupado, registro.Consultar("7").Status)
+
+		// Ocupado não expira mesmo sem heartbeats
+		relogio.Avancar(10 * time.Minute)
+		assert.Empty(t, registro.ExpirarInativos())
+
+		require.NoError(t, corridas.AtualizarPosicao(corrida.ID, -8.0500, -34.8800, 0))
+		require.NoError(t, corridas.FinalizarCorrida(corrida.ID, 0))
+
+		disponibilidade := registro.Consultar("7")
+		assert.Equal(t, models.StatusDisponivel, disponibilidade.Status)
+		assert.Equal(t, -8.0500, disponibilidade.Latitude)
+		assert.Equal(t, relogio.Agora(), disponibilidade.UltimoSinal)
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// Códigos retornados quando um motorista não pode aceitar uma corrida
+const (
+	CodigoMotoristaNaoEncontrado = "motorista_nao_encontrado"
+	CodigoMotoristaNaoAprovado   = "motorista_nao_aprovado"
+	CodigoMotoristaSuspenso      = "motorista_suspenso"
+	CodigoCNHVencida             = "cnh_vencida"
+	CodigoMotoristaOcupado       = "motorista_ocupado"
+	CodigoCategoriaIncompativel  = "categoria_incompativel"
+)
+
+// tentativasReserva limita as releituras quando o cadastro muda durante a reserva
+const tentativasReserva = 3
+
+// ErroElegibilidade descreve o motivo pelo qual o motorista não pode aceitar a corrida
+type ErroElegibilidade struct {
+	Codigo   string
+	Mensagem string
+}
+
+func (e *ErroElegibilidade) Error() string {
+	return e.Mensagem
+}
+
+// ElegibilidadeService verifica se um motorista pode assumir corridas e controla
+// seu status operacional enquanto estiver em uma corrida
+type ElegibilidadeService interface {
+	VerificarElegibilidade(motoristaID models.MotoristaID) (*models.Motorista, error)
+	ReservarMotorista(motoristaID models.MotoristaID) (*models.Motorista, error)
+	LiberarMotorista(motoristaID models.MotoristaID) error
+}
+
+// ElegibilidadeServiceImpl implementa ElegibilidadeService usando o MotoristaRepository
+type ElegibilidadeServiceImpl struct {
+	motoristaRepo repositories.MotoristaRepository
+}
+
+// NewElegibilidadeService cria uma nova instância do serviço
+func NewElegibilidadeService(motoristaRepo repositories.MotoristaRepository) ElegibilidadeService {
+	return &ElegibilidadeServiceImpl{
+		motoristaRepo: motoristaRepo,
+	}
+}
+
+// VerificarElegibilidade confere cadastro, CNH e disponibilidade do motorista
+func (s *ElegibilidadeServiceImpl) VerificarElegibilidade(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
+	if err != nil {
+		return nil, &ErroElegibilidade{
+			Codigo:   CodigoMotoristaNaoEncontrado,
+			Mensagem: "motorista não encontrado",
+		}
+	}
+
+	if err := validarElegibilidade(motorista); err != nil {
+		return nil, err
+	}
+
+	return motorista, nil
+}
+
+// ReservarMotorista verifica a elegibilidade e marca o motorista como ocupado
+func (s *ElegibilidadeServiceImpl) ReservarMotorista(motoristaID models.MotoristaID) (*models.Motorista, error) {
+	for tentativa := 0; tentativa < tentativasReserva; tentativa++ {
+		motorista, err := s.VerificarElegibilidade(motoristaID)
+		if err != nil {
+			return nil, err
+		}
+
+		motorista.StatusOperacional = models.StatusOcupado
+		err = s.motoristaRepo.Atualizar(motorista)
+		if err == nil {
+			return motorista, nil
+		}
+		if !errors.Is(err, repositories.ErrVersaoConflito) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrVersaoConflito
+}
+
+// LiberarMotorista devolve o motorista ao status disponível ao fim da corrida
+func (s *ElegibilidadeServiceImpl) LiberarMotorista(motoristaID models.MotoristaID) error {
+	for tentativa := 0; tentativa < tentativasReserva; tentativa++ {
+		motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
+		if err != nil {
+			return errors.New("motorista não encontrado")
+		}
+
+		if motorista.StatusOperacional != models.StatusOcupado {
+			return nil
+		}
+
+		motorista.StatusOperacional = models.StatusDisponivel
+		err = s.motoristaRepo.Atualizar(motorista)
+		if err == nil || !errors.Is(err, repositories.ErrVersaoConflito) {
+			return err
+		}
+	}
+
+	return ErrVersaoConflito
+}
+
+// validarElegibilidade aplica as regras de elegibilidade na ordem de gravidade
+func validarElegibilidade(motorista *models.Motorista) error {
+	if motorista.Status == models.StatusSuspenso {
+		return &ErroElegibilidade{
+			Codigo:   CodigoMotoristaSuspenso,
+			Mensagem: "motorista suspenso não pode aceitar corridas",
+		}
+	}
+
+	if motorista.Status != models.StatusAprovado && motorista.Status != models.StatusAtivo {
+		return &ErroElegibilidade{
+			Codigo:   CodigoMotoristaNaoAprovado,
+			Mensagem: "motorista ainda não foi aprovado para aceitar corridas",
+		}
+	}
+
+	if err := models.ValidarValidadeCNH(motorista.ValidadeCNH); err != nil {
+		return &ErroElegibilidade{
+			Codigo:   CodigoCNHVencida,
+			Mensagem: err.Error(),
+		}
+	}
+
+	if motorista.StatusOperacional == models.StatusOcupado {
+		return &ErroElegibilidade{
+			Codigo:   CodigoMotoristaOcupado,
+			Mensagem: "motorista já está em outra corrida",
+		}
+	}
+
+	return nil
+}
+
+// VerificarCategoria confere se o veículo e a CNH do motorista atendem a categoria da corrida
+func VerificarCategoria(motorista *models.Motorista, categoria models.CategoriaVeiculo) error {
+	if motorista.AtendeCategoria(categoria) {
+		return nil
+	}
+	return &ErroElegibilidade{
+		Codigo:   CodigoCategoriaIncompativel,
+		Mensagem: fmt.Sprintf("veículo ou CNH do motorista não atende a categoria %s", categoria.OuPadrao()),
+	}
+}
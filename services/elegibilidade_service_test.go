@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+func TestElegibilidadeService(t *testing.T) {
+	motoristaValido := func() *models.Motorista {
+		return &models.Motorista{
+			ID:          "m-1",
+			Status:      models.StatusAprovado,
+			ValidadeCNH: time.Now().AddDate(1, 0, 0),
+			Versao:      1,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		ajustar        func(m *models.Motorista)
+		codigoEsperado string
+	}{
+		{"Motorista aguardando aprovação", func(m *models.Motorista) { m.Status = models.StatusDocumentosAnalise }, CodigoMotoristaNaoAprovado},
+		{"Motorista suspenso", func(m *models.Motorista) { m.Status = models.StatusSuspenso }, CodigoMotoristaSuspenso},
+		{"CNH vencida", func(m *models.Motorista) { m.ValidadeCNH = time.Now().AddDate(0, 0, -2) }, CodigoCNHVencida},
+		{"Motorista ocupado", func(m *models.Motorista) { m.StatusOperacional = models.StatusOcupado }, CodigoMotoristaOcupado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMotoristaRepository)
+			motorista := motoristaValido()
+			tt.ajustar(motorista)
+			mockRepo.On("BuscarPorID", models.MotoristaID("m-1")).Return(motorista, nil)
+
+			service := NewElegibilidadeService(mockRepo)
+			_, err := service.ReservarMotorista("m-1")
+
+			var erroElegibilidade *ErroElegibilidade
+			require.ErrorAs(t, err, &erroElegibilidade)
+			assert.Equal(t, tt.codigoEsperado, erroElegibilidade.Codigo)
+			mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+		})
+	}
+
+	t.Run("Motorista inexistente", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockRepo.On("BuscarPorID", models.MotoristaID("m-9")).Return(nil, errors.New("motorista não encontrado"))
+
+		service := NewElegibilidadeService(mockRepo)
+		_, err := service.VerificarElegibilidade("m-9")
+
+		var erroElegibilidade *ErroElegibilidade
+		require.ErrorAs(t, err, &erroElegibilidade)
+		assert.Equal(t, CodigoMotoristaNaoEncontrado, erroElegibilidade.Codigo)
+	})
+
+	t.Run("Reserva marca o motorista como ocupado", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockRepo.On("BuscarPorID", models.MotoristaID("m-1")).Return(motoristaValido(), nil)
+		mockRepo.On("Atualizar", mock.MatchedBy(func(m *models.Motorista) bool {
+			return m.StatusOperacional == models.StatusOcupado
+		})).Return(nil)
+
+		service := NewElegibilidadeService(mockRepo)
+		motorista, err := service.ReservarMotorista("m-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusOcupado, motorista.StatusOperacional)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reserva relê o cadastro após conflito de versão", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockRepo.On("BuscarPorID", models.MotoristaID("m-1")).Return(motoristaValido(), nil).Once()
+		mockRepo.On("BuscarPorID", models.MotoristaID("m-1")).Return(motoristaValido(), nil).Once()
+		mockRepo.On("Atualizar", mock.Anything).Return(repositories.ErrVersaoConflito).Once()
+		mockRepo.On("Atualizar", mock.Anything).Return(nil).Once()
+
+		service := NewElegibilidadeService(mockRepo)
+		_, err := service.ReservarMotorista("m-1")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Liberar devolve o motorista ao status disponível", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		motorista := motoristaValido()
+		motorista.StatusOperacional = models.StatusOcupado
+		mockRepo.On("BuscarPorID", models.MotoristaID("m-1")).Return(motorista, nil)
+		mockRepo.On("Atualizar", mock.Anything).Return(nil)
+
+		service := NewElegibilidadeService(mockRepo)
+		err := service.LiberarMotorista("m-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusDisponivel, motorista.StatusOperacional)
+	})
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
 	"strconv"
+
+	"taxi-service/models"
 )
 
 // EmailService define a interface para envio de emails
@@ -212,6 +215,45 @@ func (s *SMTPEmailService) EnviarEmailRejeicao(email, nome, motivo string) error
 	return s.enviarEmail(email, subject, body)
 }
 
+// EnviarRecibo envia o recibo de uma corrida de rua para o email informado pelo passageiro
+func (s *SMTPEmailService) EnviarRecibo(email string, recibo models.ReciboCorrida) error {
+	subject := fmt.Sprintf("Recibo da corrida %d - Taxi Service", recibo.CorridaID)
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Recibo da Corrida</h2>
+			<p>Obrigado por viajar com o Taxi Service!</p>
+			<p><strong>Corrida:</strong> %d</p>
+			<p><strong>Início:</strong> %s<br><strong>Fim:</strong> %s (%d min)</p>
+			<p><strong>Distância:</strong> %.2f km</p>
+			<p><strong>Bandeirada:</strong> R$ %.2f<br><strong>Por km:</strong> R$ %.2f</p>
+			<p><strong>Total:</strong> R$ %.2f</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, recibo.CorridaID, recibo.Inicio.Format("02/01/2006 15:04"), recibo.Fim.Format("02/01/2006 15:04"),
+		recibo.DuracaoMin, recibo.DistanciaKm, recibo.Bandeirada, recibo.PorKm, recibo.Valor)
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarMensagem envia uma mensagem do notificador, com o texto escapado para HTML
+func (s *SMTPEmailService) EnviarMensagem(email, assunto, texto string) error {
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>%s</h2>
+			<p>%s</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, html.EscapeString(assunto), html.EscapeString(texto))
+
+	return s.enviarEmail(email, assunto+" - Taxi Service", body)
+}
+
 // getEnvOrDefault obtém variável de ambiente ou retorna valor padrão
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// Janelas predefinidas das estatísticas de aceite
+const (
+	JanelaEstatisticas7Dias  = "7d"
+	JanelaEstatisticas30Dias = "30d"
+)
+
+// Parâmetros da taxa de aceite usada pelo despacho na classificação dos candidatos
+const (
+	JanelaClassificacaoPadrao  = 30 * 24 * time.Hour
+	MinimoOfertasClassificacao = 5           // abaixo disso a taxa não é confiável e não pesa
+	ValidadeTaxaAceite         = time.Minute // taxas recalculadas no máximo uma vez por minuto
+)
+
+// ErrPeriodoEstatisticasInvalido indica uma janela desconhecida ou um período vazio
+var ErrPeriodoEstatisticasInvalido = errors.New("período das estatísticas inválido")
+
+// EstatisticasAceite resume como o motorista respondeu às ofertas criadas no período. As taxas
+// consideram as ofertas que dependiam dele (aceitas, recusadas e expiradas); canceladas, retiradas
+// porque outro motorista aceitou, e pendentes ficam de fora. O tempo médio de resposta vai da
+// criação da oferta ao aceite ou à recusa feitos pelo próprio motorista.
+type EstatisticasAceite struct {
+	MotoristaID                models.MotoristaID `json:"motorista_id"`
+	De                         time.Time          `json:"de"`
+	Ate                        time.Time          `json:"ate"`
+	Ofertas                    int                `json:"ofertas"`
+	Aceitas                    int                `json:"aceitas"`
+	Recusadas                  int                `json:"recusadas"`
+	Expiradas                  int                `json:"expiradas"`
+	Canceladas                 int                `json:"canceladas"`
+	Pendentes                  int                `json:"pendentes"`
+	TaxaAceite                 float64            `json:"taxa_aceite"`
+	TaxaRecusa                 float64            `json:"taxa_recusa"`
+	TaxaExpiracao              float64            `json:"taxa_expiracao"`
+	TempoMedioRespostaSegundos float64            `json:"tempo_medio_resposta_segundos"`
+}
+
+// taxaCalculada guarda a taxa de aceite do motorista usada na classificação
+type taxaCalculada struct {
+	taxa      float64
+	confiavel bool
+	em        time.Time
+}
+
+// EstatisticasMotoristas calcula as estatísticas de aceite dos motoristas a partir das
+// notificações e fornece ao despacho (TaxasAceite) a taxa de aceite dos últimos 30 dias
+type EstatisticasMotoristas struct {
+	repo  repositories.NotificacaoRepository
+	taxas map[models.MotoristaID]taxaCalculada
+	agora func() time.Time
+	mutex sync.Mutex
+}
+
+// NewEstatisticasMotoristas cria o serviço sobre o repositório de notificações
+func NewEstatisticasMotoristas(repo repositories.NotificacaoRepository) *EstatisticasMotoristas {
+	return &EstatisticasMotoristas{
+		repo:  repo,
+		taxas: make(map[models.MotoristaID]taxaCalculada),
+		agora: time.Now,
+	}
+}
+
+// duracaoJanela converte as janelas predefinidas (7d, 30d)
+func duracaoJanela(janela string) (time.Duration, error) {
+	switch janela {
+	case JanelaEstatisticas7Dias:
+		return 7 * 24 * time.Hour, nil
+	case JanelaEstatisticas30Dias:
+		return 30 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("%w: janela %q (use %s ou %s)", ErrPeriodoEstatisticasInvalido, janela, JanelaEstatisticas7Dias, JanelaEstatisticas30Dias)
+}
+
+// CalcularJanela retorna as estatísticas das ofertas criadas na janela até agora
+func (e *EstatisticasMotoristas) CalcularJanela(motoristaID models.MotoristaID, janela string) (EstatisticasAceite, error) {
+	duracao, err := duracaoJanela(janela)
+	if err != nil {
+		return EstatisticasAceite{}, err
+	}
+	agora := e.agora()
+	return e.Calcular(motoristaID, agora.Add(-duracao), agora)
+}
+
+// Calcular retorna as estatísticas das ofertas criadas a partir de de e antes de ate
+func (e *EstatisticasMotoristas) Calcular(motoristaID models.MotoristaID, de, ate time.Time) (EstatisticasAceite, error) {
+	if !ate.After(de) {
+		return EstatisticasAceite{}, fmt.Errorf("%w: o fim deve ser posterior ao início", ErrPeriodoEstatisticasInvalido)
+	}
+	notificacoes, err := e.repo.Listar(repositories.FiltroNotificacoes{
+		MotoristaID: motoristaID,
+		CriadaDe:    de,
+		CriadaAte:   ate,
+	})
+	if err != nil {
+		return EstatisticasAceite{}, err
+	}
+
+	estatisticas := EstatisticasAceite{MotoristaID: motoristaID, De: de, Ate: ate}
+	var tempoTotal time.Duration
+	respostas := 0
+	for _, notificacao := range notificacoes {
+		switch notificacao.Status {
+		case models.NotificacaoAceita:
+			estatisticas.Aceitas++
+		case models.NotificacaoRecusada:
+			estatisticas.Recusadas++
+		case models.NotificacaoExpirada:
+			estatisticas.Expiradas++
+		case models.NotificacaoCancelada:
+			estatisticas.Canceladas++
+		case models.NotificacaoPendente:
+			estatisticas.Pendentes++
+		}
+		if tempo, ok := tempoDeResposta(notificacao); ok {
+			tempoTotal += tempo
+			respostas++
+		}
+	}
+
+	estatisticas.Ofertas = estatisticas.Aceitas + estatisticas.Recusadas + estatisticas.Expiradas
+	if estatisticas.Ofertas > 0 {
+		total := float64(estatisticas.Ofertas)
+		estatisticas.TaxaAceite = arredondar(float64(estatisticas.Aceitas)/total, 4)
+		estatisticas.TaxaRecusa = arredondar(float64(estatisticas.Recusadas)/total, 4)
+		estatisticas.TaxaExpiracao = arredondar(float64(estatisticas.Expiradas)/total, 4)
+	}
+	if respostas > 0 {
+		estatisticas.TempoMedioRespostaSegundos = arredondar(tempoTotal.Seconds()/float64(respostas), 1)
+	}
+	return estatisticas, nil
+}
+
+// tempoDeResposta retorna quanto o motorista levou para aceitar ou recusar a oferta. Mudanças
+// feitas pelo suporte ou pelo sistema não contam. Notificações gravadas antes do histórico de
+// mudanças usam a data da última atualização.
+func tempoDeResposta(notificacao models.NotificacaoCorrida) (time.Duration, bool) {
+	if notificacao.Status != models.NotificacaoAceita && notificacao.Status != models.NotificacaoRecusada {
+		return 0, false
+	}
+	respondidaEm := notificacao.UpdatedAt
+	if len(notificacao.Historico) > 0 {
+		mudanca := notificacao.Historico[len(notificacao.Historico)-1]
+		if mudanca.Para != notificacao.Status || mudanca.Por != autorMotorista(notificacao.MotoristaID) {
+			return 0, false
+		}
+		respondidaEm = mudanca.Em
+	}
+	if respondidaEm.Before(notificacao.CreatedAt) {
+		return 0, false
+	}
+	return respondidaEm.Sub(notificacao.CreatedAt), true
+}
+
+// TaxaAceite implementa TaxasAceite com a taxa dos últimos 30 dias. Retorna false enquanto o
+// motorista tiver menos de MinimoOfertasClassificacao ofertas no período.
+func (e *EstatisticasMotoristas) TaxaAceite(motoristaID models.MotoristaID) (float64, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	agora := e.agora()
+	if calculada, existe := e.taxas[motoristaID]; existe && agora.Sub(calculada.em) < ValidadeTaxaAceite {
+		return calculada.taxa, calculada.confiavel
+	}
+
+	estatisticas, err := e.Calcular(motoristaID, agora.Add(-JanelaClassificacaoPadrao), agora)
+	if err != nil {
+		return 0, false
+	}
+	calculada := taxaCalculada{
+		taxa:      estatisticas.TaxaAceite,
+		confiavel: estatisticas.Ofertas >= MinimoOfertasClassificacao,
+		em:        agora,
+	}
+	e.taxas[motoristaID] = calculada
+	return calculada.taxa, calculada.confiavel
+}
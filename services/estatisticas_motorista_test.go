@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+func TestEstatisticasMotoristas(t *testing.T) {
+	agora := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	// ofertar grava uma oferta do motorista criada há dias dias e respondida em segundos
+	ofertar := func(t *testing.T, repo repositories.NotificacaoRepository, motoristaID models.MotoristaID, dias int, status models.NotificacaoStatus, segundos int, por string) {
+		t.Helper()
+		criada := agora.AddDate(0, 0, -dias)
+		oferta := &models.NotificacaoCorrida{MotoristaID: motoristaID, Status: models.NotificacaoPendente, CreatedAt: criada}
+		require.NoError(t, repo.Criar(oferta))
+		if status != models.NotificacaoPendente {
+			mudanca := models.MudancaStatusNotificacao{Para: status, Por: por, Motivo: "teste", Em: criada.Add(time.Duration(segundos) * time.Second)}
+			_, err := repo.TrocarStatus(oferta.ID, somentePendente, mudanca)
+			require.NoError(t, err)
+		}
+	}
+
+	novas := func(t *testing.T) (*EstatisticasMotoristas, *repositories.MemoriaNotificacaoRepository) {
+		repo := repositories.NewMemoriaNotificacaoRepository(nil)
+		estatisticas := NewEstatisticasMotoristas(repo)
+		estatisticas.agora = func() time.Time { return agora }
+		return estatisticas, repo
+	}
+
+	t.Run("Taxas e tempo médio de resposta nas janelas de 7 e 30 dias", func(t *testing.T) {
+		estatisticas, repo := novas(t)
+		ofertar(t, repo, "1", 1, models.NotificacaoAceita, 10, autorMotorista("1"))
+		ofertar(t, repo, "1", 2, models.NotificacaoRecusada, 20, autorMotorista("1"))
+		ofertar(t, repo, "1", 3, models.NotificacaoExpirada, 30, models.AutorSistema)
+		ofertar(t, repo, "1", 3, models.NotificacaoAceita, 90, "suporte:ana") // aceite do suporte não conta no tempo
+		ofertar(t, repo, "1", 4, models.NotificacaoCancelada, 5, models.AutorSistema)
+		ofertar(t, repo, "1", 20, models.NotificacaoExpirada, 30, models.AutorSistema)
+		ofertar(t, repo, "2", 1, models.NotificacaoAceita, 5, autorMotorista("2"))
+
+		semana, err := estatisticas.CalcularJanela("1", JanelaEstatisticas7Dias)
+		require.NoError(t, err)
+		assert.Equal(t, 4, semana.Ofertas)
+		assert.Equal(t, 2, semana.Aceitas)
+		assert.Equal(t, 1, semana.Canceladas)
+		assert.Equal(t, 0.5, semana.TaxaAceite)
+		assert.Equal(t, 0.25, semana.TaxaRecusa)
+		assert.Equal(t, 0.25, semana.TaxaExpiracao)
+		assert.Equal(t, 15.0, semana.TempoMedioRespostaSegundos)
+
+		mes, err := estatisticas.CalcularJanela("1", JanelaEstatisticas30Dias)
+		require.NoError(t, err)
+		assert.Equal(t, 5, mes.Ofertas)
+		assert.Equal(t, 0.4, mes.TaxaExpiracao)
+
+		periodo, err := estatisticas.Calcular("1", agora.AddDate(0, 0, -21), agora.AddDate(0, 0, -10))
+		require.NoError(t, err)
+		assert.Equal(t, 1, periodo.Expiradas)
+		assert.Equal(t, 1.0, periodo.TaxaExpiracao)
+	})
+
+	t.Run("Período inválido", func(t *testing.T) {
+		estatisticas, _ := novas(t)
+		_, err := estatisticas.CalcularJanela("1", "90d")
+		assert.ErrorIs(t, err, ErrPeriodoEstatisticasInvalido)
+		_, err = estatisticas.Calcular("1", agora, agora.Add(-time.Hour))
+		assert.ErrorIs(t, err, ErrPeriodoEstatisticasInvalido)
+	})
+
+	t.Run("Taxa para o despacho só é confiável a partir do mínimo de ofertas", func(t *testing.T) {
+		estatisticas, repo := novas(t)
+		for i := 0; i < MinimoOfertasClassificacao-1; i++ {
+			ofertar(t, repo, "1", 1, models.NotificacaoRecusada, 5, autorMotorista("1"))
+		}
+		_, confiavel := estatisticas.TaxaAceite("1")
+		assert.False(t, confiavel)
+
+		// A taxa fica guardada pela validade; depois é recalculada
+		ofertar(t, repo, "1", 1, models.NotificacaoAceita, 5, autorMotorista("1"))
+		_, confiavel = estatisticas.TaxaAceite("1")
+		assert.False(t, confiavel)
+
+		agora = agora.Add(ValidadeTaxaAceite)
+		taxa, confiavel := estatisticas.TaxaAceite("1")
+		assert.True(t, confiavel)
+		assert.Equal(t, 0.2, taxa)
+	})
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Nomes aceitos na configuração das estratégias de despacho
+const (
+	EstrategiaSequencial = "sequencial"
+	EstrategiaBroadcast  = "broadcast"
+	EstrategiaLote       = "lote"
+
+	TamanhoBroadcastPadrao = 5
+	IntervaloLotePadrao    = 5 * time.Second
+)
+
+// PedidoDespacho é uma corrida pronta para nova rodada de ofertas
+type PedidoDespacho struct {
+	CorridaID int
+	// Candidatos elegíveis que não recusaram, do mais próximo ao mais distante (com as taxas de
+	// aceite definidas no despacho, a distância é ponderada pela taxa)
+	Candidatos []MotoristaProximo
+	// Motoristas que já receberam oferta desta corrida em rodadas anteriores
+	JaOfertados map[models.MotoristaID]bool
+}
+
+// EstrategiaDespacho (dispatch strategy) decide quais motoristas recebem a oferta de cada
+// corrida em uma rodada. Estratégias com IntervaloLote maior que zero acumulam os pedidos e
+// os distribuem juntos periodicamente; as demais recebem um pedido por vez.
+type EstrategiaDespacho interface {
+	Nome() string
+	IntervaloLote() time.Duration
+	Distribuir(pedidos []PedidoDespacho) map[int][]MotoristaProximo
+}
+
+// estrategiaSequencial oferta a corrida a um motorista por vez, seguindo a ordem de proximidade
+type estrategiaSequencial struct{}
+
+// NewEstrategiaSequencial cria a estratégia de um motorista por rodada
+func NewEstrategiaSequencial() EstrategiaDespacho {
+	return estrategiaSequencial{}
+}
+
+func (estrategiaSequencial) Nome() string                 { return EstrategiaSequencial }
+func (estrategiaSequencial) IntervaloLote() time.Duration { return 0 }
+
+// Distribuir escolhe o candidato mais próximo que ainda não recebeu oferta. Se todos já
+// receberam (e deixaram expirar), recomeça pelo mais próximo.
+func (estrategiaSequencial) Distribuir(pedidos []PedidoDespacho) map[int][]MotoristaProximo {
+	escolhidos := make(map[int][]MotoristaProximo)
+	for _, pedido := range pedidos {
+		if len(pedido.Candidatos) == 0 {
+			continue
+		}
+		escolhido := pedido.Candidatos[0]
+		for _, candidato := range pedido.Candidatos {
+			if !pedido.JaOfertados[candidato.ID] {
+				escolhido = candidato
+				break
+			}
+		}
+		escolhidos[pedido.CorridaID] = []MotoristaProximo{escolhido}
+	}
+	return escolhidos
+}
+
+// estrategiaBroadcast oferta a corrida aos N motoristas mais próximos; o primeiro a aceitar leva
+type estrategiaBroadcast struct {
+	tamanho int
+}
+
+// NewEstrategiaBroadcast cria a estratégia que oferta aos N mais próximos ao mesmo tempo
+func NewEstrategiaBroadcast(tamanho int) EstrategiaDespacho {
+	if tamanho <= 0 {
+		tamanho = TamanhoBroadcastPadrao
+	}
+	return estrategiaBroadcast{tamanho: tamanho}
+}
+
+func (e estrategiaBroadcast) Nome() string                 { return EstrategiaBroadcast }
+func (e estrategiaBroadcast) IntervaloLote() time.Duration { return 0 }
+
+func (e estrategiaBroadcast) Distribuir(pedidos []PedidoDespacho) map[int][]MotoristaProximo {
+	escolhidos := make(map[int][]MotoristaProximo)
+	for _, pedido := range pedidos {
+		candidatos := pedido.Candidatos
+		if len(candidatos) > e.tamanho {
+			candidatos = candidatos[:e.tamanho]
+		}
+		if len(candidatos) > 0 {
+			escolhidos[pedido.CorridaID] = candidatos
+		}
+	}
+	return escolhidos
+}
+
+// estrategiaLote acumula as corridas e, a cada intervalo, atribui no máximo um motorista por
+// corrida minimizando a soma das distâncias até os embarques
+type estrategiaLote struct {
+	intervalo time.Duration
+}
+
+// NewEstrategiaLote cria a estratégia de emparelhamento global em lotes
+func NewEstrategiaLote(intervalo time.Duration) EstrategiaDespacho {
+	if intervalo <= 0 {
+		intervalo = IntervaloLotePadrao
+	}
+	return estrategiaLote{intervalo: intervalo}
+}
+
+func (e estrategiaLote) Nome() string                 { return EstrategiaLote }
+func (e estrategiaLote) IntervaloLote() time.Duration { return e.intervalo }
+
+func (e estrategiaLote) Distribuir(pedidos []PedidoDespacho) map[int][]MotoristaProximo {
+	// Cada motorista candidato em qualquer pedido vira uma coluna da matriz de custos
+	colunas := make(map[models.MotoristaID]int)
+	var motoristas []models.MotoristaID
+	for _, pedido := range pedidos {
+		for _, candidato := range pedido.Candidatos {
+			if _, existe := colunas[candidato.ID]; !existe {
+				colunas[candidato.ID] = len(motoristas)
+				motoristas = append(motoristas, candidato.ID)
+			}
+		}
+	}
+
+	custos := make([][]float64, len(pedidos))
+	for i, pedido := range pedidos {
+		custos[i] = make([]float64, len(motoristas))
+		for j := range custos[i] {
+			custos[i][j] = custoInviavel
+		}
+		for _, candidato := range pedido.Candidatos {
+			custos[i][colunas[candidato.ID]] = candidato.DistanciaKm
+		}
+	}
+
+	escolhidos := make(map[int][]MotoristaProximo)
+	for i, j := range atribuirMenorCusto(custos) {
+		if j < 0 {
+			continue
+		}
+		for _, candidato := range pedidos[i].Candidatos {
+			if candidato.ID == motoristas[j] {
+				escolhidos[pedidos[i].CorridaID] = []MotoristaProximo{candidato}
+				break
+			}
+		}
+	}
+	return escolhidos
+}
+
+// custoInviavel marca pares corrida/motorista que não podem ser atribuídos
+const custoInviavel = 1e9
+
+// atribuirMenorCusto resolve o problema de atribuição (algoritmo húngaro) para uma matriz
+// linhas x colunas e retorna, para cada linha, a coluna atribuída ou -1 quando nenhuma
+// coluna viável sobrou para ela
+func atribuirMenorCusto(custos [][]float64) []int {
+	linhas := len(custos)
+	if linhas == 0 {
+		return nil
+	}
+	colunas := len(custos[0])
+
+	// Matriz quadrada: linhas ou colunas fictícias com custo inviável
+	n := linhas
+	if colunas > n {
+		n = colunas
+	}
+	custo := func(i, j int) float64 {
+		if i < linhas && j < colunas {
+			return custos[i][j]
+		}
+		return custoInviavel
+	}
+
+	// Potenciais u (linhas) e v (colunas), índices a partir de 1; p[j] é a linha da coluna j
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1)
+	caminho := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minimos := make([]float64, n+1)
+		usados := make([]bool, n+1)
+		for j := range minimos {
+			minimos[j] = math.Inf(1)
+		}
+
+		for p[j0] != 0 {
+			usados[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if usados[j] {
+					continue
+				}
+				atual := custo(i0-1, j-1) - u[i0] - v[j]
+				if atual < minimos[j] {
+					minimos[j] = atual
+					caminho[j] = j0
+				}
+				if minimos[j] < delta {
+					delta = minimos[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if usados[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minimos[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+
+		for j0 != 0 {
+			j1 := caminho[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	atribuicao := make([]int, linhas)
+	for i := range atribuicao {
+		atribuicao[i] = -1
+	}
+	for j := 1; j <= n; j++ {
+		i := p[j] - 1
+		if i < linhas && j-1 < colunas && custos[i][j-1] < custoInviavel {
+			atribuicao[i] = j - 1
+		}
+	}
+	return atribuicao
+}
+
+// NovaEstrategiaDespacho cria uma estratégia pelo nome usado na configuração
+func NovaEstrategiaDespacho(nome string, tamanhoBroadcast int, intervaloLote time.Duration) (EstrategiaDespacho, error) {
+	switch strings.ToLower(strings.TrimSpace(nome)) {
+	case EstrategiaSequencial:
+		return NewEstrategiaSequencial(), nil
+	case EstrategiaBroadcast:
+		return NewEstrategiaBroadcast(tamanhoBroadcast), nil
+	case EstrategiaLote:
+		return NewEstrategiaLote(intervaloLote), nil
+	}
+	return nil, fmt.Errorf("estratégia de despacho desconhecida: %q", nome)
+}
+
+// normalizarCidade padroniza o nome da cidade (ou da zona) usado como chave da configuração
+func normalizarCidade(cidade string) string {
+	return strings.ToLower(strings.TrimSpace(cidade))
+}
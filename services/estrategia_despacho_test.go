@@ -0,0 +1,210 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func candidatosTeste(distancias map[models.MotoristaID]float64) []MotoristaProximo {
+	var candidatos []MotoristaProximo
+	for id, distancia := range distancias {
+		candidatos = append(candidatos, MotoristaProximo{ID: id, DistanciaKm: distancia})
+	}
+	ordenarPorDistancia(candidatos)
+	return candidatos
+}
+
+func idsDe(motoristas []MotoristaProximo) []models.MotoristaID {
+	var ids []models.MotoristaID
+	for _, motorista := range motoristas {
+		ids = append(ids, motorista.ID)
+	}
+	return ids
+}
+
+func TestEstrategiasDespacho(t *testing.T) {
+	candidatos := candidatosTeste(map[models.MotoristaID]float64{"a": 0.5, "b": 1.0, "c": 1.5})
+
+	t.Run("Sequencial oferta ao mais próximo ainda não ofertado", func(t *testing.T) {
+		estrategia := NewEstrategiaSequencial()
+
+		escolhidos := estrategia.Distribuir([]PedidoDespacho{{CorridaID: 1, Candidatos: candidatos}})
+		assert.Equal(t, []models.MotoristaID{"a"}, idsDe(escolhidos[1]))
+
+		escolhidos = estrategia.Distribuir([]PedidoDespacho{{
+			CorridaID:   1,
+			Candidatos:  candidatos,
+			JaOfertados: map[models.MotoristaID]bool{"a": true},
+		}})
+		assert.Equal(t, []models.MotoristaID{"b"}, idsDe(escolhidos[1]))
+
+		// Todos já receberam: recomeça pelo mais próximo
+		escolhidos = estrategia.Distribuir([]PedidoDespacho{{
+			CorridaID:   1,
+			Candidatos:  candidatos,
+			JaOfertados: map[models.MotoristaID]bool{"a": true, "b": true, "c": true},
+		}})
+		assert.Equal(t, []models.MotoristaID{"a"}, idsDe(escolhidos[1]))
+	})
+
+	t.Run("Broadcast oferta aos N mais próximos", func(t *testing.T) {
+		escolhidos := NewEstrategiaBroadcast(2).Distribuir([]PedidoDespacho{{CorridaID: 1, Candidatos: candidatos}})
+		assert.Equal(t, []models.MotoristaID{"a", "b"}, idsDe(escolhidos[1]))
+
+		escolhidos = NewEstrategiaBroadcast(2).Distribuir([]PedidoDespacho{{CorridaID: 2}})
+		assert.NotContains(t, escolhidos, 2)
+	})
+
+	t.Run("Lote minimiza a distância total, não a de cada corrida", func(t *testing.T) {
+		// O guloso daria "x" à corrida 1 (0,5 km) e "y" à corrida 2 (5 km): 5,5 km.
+		// O ótimo dá "y" à corrida 1 e "x" à corrida 2: 1 + 1,2 = 2,2 km.
+		pedidos := []PedidoDespacho{
+			{CorridaID: 1, Candidatos: candidatosTeste(map[models.MotoristaID]float64{"x": 0.5, "y": 1.0})},
+			{CorridaID: 2, Candidatos: candidatosTeste(map[models.MotoristaID]float64{"x": 1.2, "y": 5.0})},
+		}
+
+		escolhidos := NewEstrategiaLote(time.Second).Distribuir(pedidos)
+		assert.Equal(t, []models.MotoristaID{"y"}, idsDe(escolhidos[1]))
+		assert.Equal(t, []models.MotoristaID{"x"}, idsDe(escolhidos[2]))
+	})
+
+	t.Run("Lote com mais corridas que motoristas deixa corridas sem oferta", func(t *testing.T) {
+		pedidos := []PedidoDespacho{
+			{CorridaID: 1, Candidatos: candidatosTeste(map[models.MotoristaID]float64{"x": 2.0})},
+			{CorridaID: 2, Candidatos: candidatosTeste(map[models.MotoristaID]float64{"x": 1.0})},
+			{CorridaID: 3},
+		}
+
+		escolhidos := NewEstrategiaLote(time.Second).Distribuir(pedidos)
+		assert.Len(t, escolhidos, 1)
+		assert.Equal(t, []models.MotoristaID{"x"}, idsDe(escolhidos[2]))
+	})
+
+	t.Run("Estratégia desconhecida na configuração", func(t *testing.T) {
+		_, err := NovaEstrategiaDespacho("leilao", 0, 0)
+		assert.Error(t, err)
+
+		estrategia, err := NovaEstrategiaDespacho(" Lote ", 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, IntervaloLotePadrao, estrategia.IntervaloLote())
+	})
+}
+
+func TestAtribuirMenorCusto(t *testing.T) {
+	custos := [][]float64{
+		{4, 1, 3},
+		{2, 0, 5},
+		{3, 2, 2},
+	}
+	// Ótimo: 0->1, 1->0, 2->2 com custo 1+2+2 = 5
+	assert.Equal(t, []int{1, 0, 2}, atribuirMenorCusto(custos))
+
+	assert.Equal(t, []int{-1}, atribuirMenorCusto([][]float64{{custoInviavel}}))
+	assert.Nil(t, atribuirMenorCusto(nil))
+}
+
+// zonasFake coloca qualquer embarque nas mesmas zonas
+type zonasFake []models.Zona
+
+func (z zonasFake) Localizar(lat, lng float64) []models.Zona {
+	return z
+}
+
+func TestDespachoPorCidade(t *testing.T) {
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"perto": {-8.0700, -34.8711},
+		"medio": {-8.0750, -34.8800},
+	}}
+	config := ConfigDespacho{
+		EstrategiaPadrao: NewEstrategiaBroadcast(5),
+		EstrategiasPorCidade: map[string]EstrategiaDespacho{
+			"Olinda": NewEstrategiaSequencial(),
+			"recife": NewEstrategiaLote(time.Hour), // processado manualmente no teste
+		},
+		EstrategiasPorZona: map[string]EstrategiaDespacho{
+			"Aeroporto": NewEstrategiaSequencial(),
+		},
+	}
+
+	t.Run("Cidade sem configuração usa a estratégia padrão", func(t *testing.T) {
+		despacho, _, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), config)
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 30, Cidade: "Paulista", OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		assert.Len(t, ofertas, 2)
+	})
+
+	t.Run("Sequencial oferta um motorista por rodada", func(t *testing.T) {
+		despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), config)
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 31, Cidade: " OLINDA", OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 1)
+		assert.Equal(t, models.MotoristaID("perto"), ofertas[0].MotoristaID)
+
+		responder(despacho, ofertas[0], models.NotificacaoExpirada)
+		require.Len(t, *criadas, 2)
+		assert.Equal(t, models.MotoristaID("medio"), (*criadas)[1].MotoristaID)
+	})
+
+	t.Run("Estratégia da zona do embarque vale antes da cidade", func(t *testing.T) {
+		despacho, _, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), config)
+		despacho.DefinirZonas(zonasFake{{Nome: "Centro"}, {Nome: "aeroporto "}})
+
+		ofertas, err := despacho.Despachar(models.Corrida{ID: 35, Cidade: "Recife", OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		require.Len(t, ofertas, 1, "sequencial da zona em vez do lote de Recife")
+
+		// Embarque fora das zonas configuradas volta à estratégia da cidade
+		despacho.DefinirZonas(zonasFake{{Nome: "Centro"}})
+		ofertas, err = despacho.Despachar(models.Corrida{ID: 36, Cidade: "Recife", OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+		require.NoError(t, err)
+		assert.Empty(t, ofertas, "aguarda o lote")
+	})
+
+	t.Run("Lote aguarda o processamento e não oferta o mesmo motorista a duas corridas", func(t *testing.T) {
+		despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), config)
+
+		for _, id := range []int{32, 33, 34} {
+			ofertas, err := despacho.Despachar(models.Corrida{ID: id, Cidade: "Recife", OrigemLat: embarqueLat, OrigemLng: embarqueLng})
+			require.NoError(t, err)
+			assert.Empty(t, ofertas)
+		}
+
+		despacho.processarLotes()
+		require.Len(t, *criadas, 2)
+		assert.NotEqual(t, (*criadas)[0].MotoristaID, (*criadas)[1].MotoristaID)
+
+		// A terceira corrida continua aguardando; os motoristas estão com ofertas pendentes
+		despacho.processarLotes()
+		assert.Len(t, *criadas, 2)
+
+		// Quando uma oferta expira, o motorista volta a estar livre para o próximo lote
+		responder(despacho, (*criadas)[0], models.NotificacaoExpirada)
+		despacho.processarLotes()
+		assert.Len(t, *criadas, 3)
+	})
+}
+
+func TestConfigDespachoFromEnv(t *testing.T) {
+	t.Setenv("DISPATCH_STRATEGY", "sequencial")
+	t.Setenv("DISPATCH_STRATEGY_BY_CITY", "Recife:lote, São Paulo:broadcast,invalida:leilao")
+	t.Setenv("DISPATCH_STRATEGY_BY_ZONE", "Aeroporto dos Guararapes:broadcast")
+	t.Setenv("DISPATCH_BROADCAST_SIZE", "3")
+	t.Setenv("DISPATCH_BATCH_INTERVAL", "2s")
+
+	config := ConfigDespachoFromEnv().comPadroes()
+
+	assert.Equal(t, EstrategiaSequencial, config.EstrategiaPadrao.Nome())
+	assert.Equal(t, EstrategiaLote, config.estrategiaDa(models.Corrida{Cidade: "recife"}, nil).Nome())
+	assert.Equal(t, EstrategiaBroadcast, config.estrategiaDa(models.Corrida{Cidade: "são paulo"}, nil).Nome())
+	assert.Equal(t, EstrategiaSequencial, config.estrategiaDa(models.Corrida{Cidade: "invalida"}, nil).Nome())
+	aeroporto := []models.Zona{{Nome: "Aeroporto dos Guararapes"}}
+	assert.Equal(t, EstrategiaBroadcast, config.estrategiaDa(models.Corrida{Cidade: "recife"}, aeroporto).Nome())
+	assert.Equal(t, 2*time.Second, config.menorIntervaloLote())
+}
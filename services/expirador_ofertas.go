@@ -0,0 +1,148 @@
+package services
+
+import (
+	"container/heap"
+	"os"
+	"sync"
+	"time"
+)
+
+// PrecisaoExpiracaoPadrao é a tolerância padrão entre o prazo de uma oferta e sua expiração
+const PrecisaoExpiracaoPadrao = time.Second
+
+// prazoOferta é o prazo de uma oferta aguardando expiração
+type prazoOferta struct {
+	notificacaoID uint
+	expiraEm      time.Time
+}
+
+// prazosOfertas é um min-heap de prazos, do mais próximo ao mais distante
+type prazosOfertas []prazoOferta
+
+func (p prazosOfertas) Len() int           { return len(p) }
+func (p prazosOfertas) Less(i, j int) bool { return p[i].expiraEm.Before(p[j].expiraEm) }
+func (p prazosOfertas) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p *prazosOfertas) Push(x any)        { *p = append(*p, x.(prazoOferta)) }
+func (p *prazosOfertas) Pop() any {
+	antigo := *p
+	ultimo := antigo[len(antigo)-1]
+	*p = antigo[:len(antigo)-1]
+	return ultimo
+}
+
+// ExpiradorOfertas dispara a expiração das ofertas quando o prazo vence, com um único
+// processo em background para todas as ofertas. Os prazos ficam em um min-heap e o processo
+// dorme até o próximo deles, arredondado para cima na precisão configurada: ofertas que
+// vencem dentro da mesma janela são expiradas juntas, no máximo `precisao` depois do prazo.
+// Prazos de ofertas respondidas antes de vencer não são removidos; a função de expiração
+// deve ignorá-los.
+type ExpiradorOfertas struct {
+	prazos   prazosOfertas
+	precisao time.Duration
+	expirar  func(notificacaoID uint)
+	mutex    sync.Mutex
+	acordar  chan struct{}
+	parar    chan struct{}
+	pararUma sync.Once
+	agora    func() time.Time
+}
+
+// NewExpiradorOfertas cria o expirador e inicia o processo em background
+func NewExpiradorOfertas(precisao time.Duration, expirar func(notificacaoID uint)) *ExpiradorOfertas {
+	if precisao <= 0 {
+		precisao = PrecisaoExpiracaoPadrao
+	}
+	expirador := &ExpiradorOfertas{
+		precisao: precisao,
+		expirar:  expirar,
+		acordar:  make(chan struct{}, 1),
+		parar:    make(chan struct{}),
+		agora:    time.Now,
+	}
+	go expirador.MonitorarPrazos()
+	return expirador
+}
+
+// PrecisaoExpiracaoFromEnv lê a precisão da expiração de OFFER_EXPIRY_PRECISION (ex.: 500ms)
+func PrecisaoExpiracaoFromEnv() time.Duration {
+	precisao, err := time.ParseDuration(os.Getenv("OFFER_EXPIRY_PRECISION"))
+	if err != nil || precisao <= 0 {
+		return PrecisaoExpiracaoPadrao
+	}
+	return precisao
+}
+
+// Agendar registra o prazo de uma oferta
+func (e *ExpiradorOfertas) Agendar(notificacaoID uint, expiraEm time.Time) {
+	e.mutex.Lock()
+	heap.Push(&e.prazos, prazoOferta{notificacaoID: notificacaoID, expiraEm: expiraEm})
+	e.mutex.Unlock()
+
+	// Acorda o processo para recalcular a espera caso este seja o prazo mais próximo
+	select {
+	case e.acordar <- struct{}{}:
+	default:
+	}
+}
+
+// Pendentes retorna quantos prazos ainda aguardam
+func (e *ExpiradorOfertas) Pendentes() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return len(e.prazos)
+}
+
+// Parar encerra o processo em background; prazos ainda pendentes não são disparados
+func (e *ExpiradorOfertas) Parar() {
+	e.pararUma.Do(func() { close(e.parar) })
+}
+
+// retirarVencidas remove do heap os prazos vencidos até o instante informado e retorna
+// a espera até o próximo prazo, arredondado na precisão (negativa se não há prazos).
+// Deve ser chamada com o mutex travado.
+func (e *ExpiradorOfertas) retirarVencidas(agora time.Time) ([]uint, time.Duration) {
+	var vencidas []uint
+	for len(e.prazos) > 0 && !e.prazos[0].expiraEm.After(agora) {
+		vencidas = append(vencidas, heap.Pop(&e.prazos).(prazoOferta).notificacaoID)
+	}
+	if len(e.prazos) == 0 {
+		return vencidas, -1
+	}
+
+	acordarEm := e.prazos[0].expiraEm.Truncate(e.precisao)
+	if acordarEm.Before(e.prazos[0].expiraEm) {
+		acordarEm = acordarEm.Add(e.precisao)
+	}
+	return vencidas, acordarEm.Sub(agora)
+}
+
+// MonitorarPrazos é o processo em background que expira as ofertas vencidas
+func (e *ExpiradorOfertas) MonitorarPrazos() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		e.mutex.Lock()
+		vencidas, espera := e.retirarVencidas(e.agora())
+		e.mutex.Unlock()
+
+		// A expiração roda fora do mutex: os ouvintes podem criar novas ofertas e agendá-las
+		for _, id := range vencidas {
+			e.expirar(id)
+		}
+
+		if espera < 0 {
+			timer.Stop()
+		} else {
+			timer.Reset(espera)
+		}
+
+		select {
+		case <-e.parar:
+			return
+		case <-e.acordar:
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+func TestExpiradorOfertas(t *testing.T) {
+	t.Run("Expira cada oferta em ordem, dentro da precisão", func(t *testing.T) {
+		const precisao = 20 * time.Millisecond
+		var mutex sync.Mutex
+		disparos := map[uint]time.Time{}
+		var ordem []uint
+		expirador := NewExpiradorOfertas(precisao, func(id uint) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			disparos[id] = time.Now()
+			ordem = append(ordem, id)
+		})
+		defer expirador.Parar()
+
+		inicio := time.Now()
+		prazos := map[uint]time.Time{
+			1: inicio.Add(90 * time.Millisecond),
+			2: inicio.Add(30 * time.Millisecond),
+			3: inicio.Add(60 * time.Millisecond),
+		}
+		for _, id := range []uint{1, 2, 3} {
+			expirador.Agendar(id, prazos[id])
+		}
+
+		require.Eventually(t, func() bool { return expirador.Pendentes() == 0 }, time.Second, 5*time.Millisecond)
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal(t, []uint{2, 3, 1}, ordem)
+		for id, prazo := range prazos {
+			assert.False(t, disparos[id].Before(prazo), "oferta %d expirou antes do prazo", id)
+			// Folga para o agendamento do sistema operacional
+			assert.Less(t, disparos[id].Sub(prazo), precisao+50*time.Millisecond, "oferta %d expirou tarde demais", id)
+		}
+	})
+
+	t.Run("Ofertas pendentes gravadas voltam a expirar após reinício", func(t *testing.T) {
+		usarDiretorioTemporario(t, nil)
+		agora := time.Now()
+		persistencia := repositories.NewArquivoNotificacoes("./data/notificacao_corrida.json")
+		require.NoError(t, persistencia.Salvar([]models.NotificacaoCorrida{
+			{ID: 1, MotoristaID: "1", CorridaID: 1, Status: models.NotificacaoPendente, ExpiraEm: agora.Add(-time.Minute)},
+			{ID: 2, MotoristaID: "2", CorridaID: 2, Status: models.NotificacaoPendente, ExpiraEm: agora.Add(50 * time.Millisecond)},
+			{ID: 3, MotoristaID: "3", CorridaID: 3, Status: models.NotificacaoPendente, ExpiraEm: agora.Add(time.Hour)},
+			{ID: 4, MotoristaID: "4", CorridaID: 4, Status: models.NotificacaoAceita, ExpiraEm: agora.Add(-time.Minute)},
+		}))
+
+		var mutex sync.Mutex
+		var expiradas []uint
+		RegistrarOuvinteNotificacao(func(notificacao models.NotificacaoCorrida) {
+			if notificacao.Status == models.NotificacaoExpirada {
+				mutex.Lock()
+				expiradas = append(expiradas, notificacao.ID)
+				mutex.Unlock()
+			}
+		})
+		require.NoError(t, IniciarExpiracaoOfertas(10*time.Millisecond))
+
+		require.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(expiradas) == 2
+		}, time.Second, 5*time.Millisecond)
+		assert.ElementsMatch(t, []uint{1, 2}, expiradas)
+
+		for id, status := range map[uint]models.NotificacaoStatus{
+			3: models.NotificacaoPendente,
+			4: models.NotificacaoAceita,
+		} {
+			gravada, err := GetNotificacaoCorrida(id)
+			require.NoError(t, err)
+			assert.Equal(t, status, gravada.Status)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+)
+
+// ErrForaDaFila indica que o motorista não está na fila de nenhum ponto de táxi
+var ErrForaDaFila = errors.New("motorista não está na fila de nenhum ponto de táxi ou aeroporto")
+
+// LocalizadorZonas retorna as zonas ativas que contêm uma posição
+type LocalizadorZonas interface {
+	Localizar(lat, lng float64) []models.Zona
+}
+
+// entradaFila é um motorista aguardando na fila de um ponto
+type entradaFila struct {
+	motoristaID models.MotoristaID
+	lat         float64
+	lng         float64
+	entrouEm    time.Time
+}
+
+// filaPonto é a fila de um ponto de táxi ou aeroporto, do primeiro a chegar ao último
+type filaPonto struct {
+	zonaNome string
+	entradas []entradaFila
+}
+
+// FilaPontoService mantém as filas por ordem de chegada dos pontos de táxi e aeroportos.
+// Um motorista disponível que entra na zona do ponto vai para o fim da fila; ao sair da
+// zona, ficar offline ou ocupado (ex.: ao aceitar uma corrida) ele deixa a fila.
+// Implementa FilaEmbarque para o despacho.
+type FilaPontoService struct {
+	zonas      LocalizadorZonas
+	filas      map[string]*filaPonto         // chave: ID da zona
+	motoristas map[models.MotoristaID]string // zona da fila em que cada motorista está
+	mutex      sync.Mutex
+	agora      func() time.Time
+}
+
+// NewFilaPontoService cria o serviço de filas
+func NewFilaPontoService(zonas LocalizadorZonas) *FilaPontoService {
+	return &FilaPontoService{
+		zonas:      zonas,
+		filas:      make(map[string]*filaPonto),
+		motoristas: make(map[models.MotoristaID]string),
+		agora:      time.Now,
+	}
+}
+
+// zonaComFila retorna a primeira zona de ponto de táxi ou aeroporto que contém a posição
+func (f *FilaPontoService) zonaComFila(lat, lng float64) (models.Zona, bool) {
+	for _, zona := range f.zonas.Localizar(lat, lng) {
+		if zona.Tipo.ComFila() {
+			return zona, true
+		}
+	}
+	return models.Zona{}, false
+}
+
+// OuvirDisponibilidade coloca e tira os motoristas das filas conforme o status e a posição.
+// Deve ser registrado no RegistroDisponibilidade.
+func (f *FilaPontoService) OuvirDisponibilidade(disponibilidade models.DisponibilidadeMotorista) {
+	var zona models.Zona
+	dentro := false
+	if disponibilidade.Status == models.StatusDisponivel {
+		zona, dentro = f.zonaComFila(disponibilidade.Latitude, disponibilidade.Longitude)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	motoristaID := disponibilidade.MotoristaID
+	atual, naFila := f.motoristas[motoristaID]
+	if naFila && (!dentro || atual != zona.ID) {
+		f.remover(atual, motoristaID)
+		naFila = false
+	}
+	if !dentro {
+		return
+	}
+
+	fila, existe := f.filas[zona.ID]
+	if !existe {
+		fila = &filaPonto{}
+		f.filas[zona.ID] = fila
+	}
+	fila.zonaNome = zona.Nome
+
+	if naFila {
+		// Continua na mesma fila: só a posição é atualizada
+		for i := range fila.entradas {
+			if fila.entradas[i].motoristaID == motoristaID {
+				fila.entradas[i].lat = disponibilidade.Latitude
+				fila.entradas[i].lng = disponibilidade.Longitude
+			}
+		}
+		return
+	}
+
+	fila.entradas = append(fila.entradas, entradaFila{
+		motoristaID: motoristaID,
+		lat:         disponibilidade.Latitude,
+		lng:         disponibilidade.Longitude,
+		entrouEm:    f.agora(),
+	})
+	f.motoristas[motoristaID] = zona.ID
+}
+
+// remover tira o motorista da fila da zona. Deve ser chamada com o mutex travado.
+func (f *FilaPontoService) remover(zonaID string, motoristaID models.MotoristaID) {
+	delete(f.motoristas, motoristaID)
+	fila, existe := f.filas[zonaID]
+	if !existe {
+		return
+	}
+	for i, entrada := range fila.entradas {
+		if entrada.motoristaID == motoristaID {
+			fila.entradas = append(fila.entradas[:i], fila.entradas[i+1:]...)
+			break
+		}
+	}
+	if len(fila.entradas) == 0 {
+		delete(f.filas, zonaID)
+	}
+}
+
+// PosicaoDe retorna a posição do motorista na fila em que ele está
+func (f *FilaPontoService) PosicaoDe(motoristaID models.MotoristaID) (models.PosicaoFila, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	zonaID, naFila := f.motoristas[motoristaID]
+	if !naFila {
+		return models.PosicaoFila{}, ErrForaDaFila
+	}
+	fila := f.filas[zonaID]
+	for i, entrada := range fila.entradas {
+		if entrada.motoristaID == motoristaID {
+			return models.PosicaoFila{
+				MotoristaID: motoristaID,
+				ZonaID:      zonaID,
+				ZonaNome:    fila.zonaNome,
+				Posicao:     i + 1,
+				Tamanho:     len(fila.entradas),
+				EntrouEm:    entrada.entrouEm,
+			}, nil
+		}
+	}
+	return models.PosicaoFila{}, ErrForaDaFila
+}
+
+// FilaDoEmbarque retorna a fila do ponto de táxi que contém o embarque, por ordem de chegada,
+// com a distância de cada motorista até o embarque. Retorna false se o embarque não está em
+// um ponto de táxi ou aeroporto.
+func (f *FilaPontoService) FilaDoEmbarque(lat, lng float64) ([]MotoristaProximo, bool) {
+	zona, dentro := f.zonaComFila(lat, lng)
+	if !dentro {
+		return nil, false
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var fila []MotoristaProximo
+	if existente, existe := f.filas[zona.ID]; existe {
+		for _, entrada := range existente.entradas {
+			fila = append(fila, MotoristaProximo{
+				ID:          entrada.motoristaID,
+				Lat:         entrada.lat,
+				Lng:         entrada.lng,
+				DistanciaKm: DistanciaKm(lat, lng, entrada.lat, entrada.lng),
+			})
+		}
+	}
+	return fila, true
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// Aeroporto dos Guararapes (Recife), usado como ponto com fila nos testes
+const (
+	aeroportoLat = -8.1265
+	aeroportoLng = -34.9230
+)
+
+// novasFilasTeste cria o aeroporto como zona com fila e liga as filas ao registro
+func novasFilasTeste(t *testing.T) (*FilaPontoService, *RegistroDisponibilidade, models.Zona) {
+	t.Helper()
+	zonas := novoZonaServiceTeste(t)
+	aeroporto, err := zonas.CriarZona(zonaRetangular("Aeroporto", models.ZonaAeroporto, -8.135, -34.935, -8.120, -34.910))
+	require.NoError(t, err)
+
+	filas := NewFilaPontoService(zonas)
+	registro := NewRegistroDisponibilidade(novaElegibilidadeFake(), time.Minute)
+	registro.RegistrarOuvinte(filas.OuvirDisponibilidade)
+	return filas, registro, *aeroporto
+}
+
+func posicaoNaFila(t *testing.T, filas *FilaPontoService, motoristaID models.MotoristaID) int {
+	t.Helper()
+	posicao, err := filas.PosicaoDe(motoristaID)
+	if err != nil {
+		return 0
+	}
+	return posicao.Posicao
+}
+
+func TestFilaPontoService(t *testing.T) {
+	t.Run("Motoristas entram na fila por ordem de chegada", func(t *testing.T) {
+		filas, registro, aeroporto := novasFilasTeste(t)
+
+		_, err := registro.FicarOnline("1", aeroportoLat, aeroportoLng)
+		require.NoError(t, err)
+		_, err = registro.FicarOnline("2", embarqueLat, embarqueLng) // fora do aeroporto
+		require.NoError(t, err)
+		_, err = registro.Heartbeat("2", aeroportoLat+0.001, aeroportoLng)
+		require.NoError(t, err)
+		_, err = registro.FicarOnline("3", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+
+		posicao, err := filas.PosicaoDe("2")
+		require.NoError(t, err)
+		assert.Equal(t, 2, posicao.Posicao)
+		assert.Equal(t, 2, posicao.Tamanho)
+		assert.Equal(t, aeroporto.ID, posicao.ZonaID)
+		assert.Equal(t, "Aeroporto", posicao.ZonaNome)
+
+		_, err = filas.PosicaoDe("3")
+		assert.ErrorIs(t, err, ErrForaDaFila)
+
+		// Heartbeats dentro do ponto não mudam a ordem
+		_, err = registro.Heartbeat("1", aeroportoLat-0.001, aeroportoLng)
+		require.NoError(t, err)
+		assert.Equal(t, 1, posicaoNaFila(t, filas, "1"))
+	})
+
+	t.Run("Sair da zona, ficar offline ou ocupado tira da fila", func(t *testing.T) {
+		filas, registro, _ := novasFilasTeste(t)
+		for _, id := range []models.MotoristaID{"1", "2", "3"} {
+			_, err := registro.FicarOnline(id, aeroportoLat, aeroportoLng)
+			require.NoError(t, err)
+		}
+
+		_, err := registro.Heartbeat("1", embarqueLat, embarqueLng)
+		require.NoError(t, err)
+		assert.Equal(t, 0, posicaoNaFila(t, filas, "1"))
+		assert.Equal(t, 1, posicaoNaFila(t, filas, "2"))
+
+		registro.OuvirCorridas(EventoCorrida{Tipo: EventoCorridaAceita, Corrida: models.Corrida{ID: 1, MotoristaID: "2"}})
+		assert.Equal(t, 0, posicaoNaFila(t, filas, "2"))
+
+		_, err = registro.FicarOffline("3")
+		require.NoError(t, err)
+		assert.Equal(t, 0, posicaoNaFila(t, filas, "3"))
+
+		// Ao voltar para o ponto, o motorista entra no fim da fila
+		_, err = registro.FicarOnline("3", aeroportoLat, aeroportoLng)
+		require.NoError(t, err)
+		_, err = registro.Heartbeat("1", aeroportoLat, aeroportoLng)
+		require.NoError(t, err)
+		assert.Equal(t, 1, posicaoNaFila(t, filas, "3"))
+		assert.Equal(t, 2, posicaoNaFila(t, filas, "1"))
+	})
+}
+
+func TestDespachoPelaFila(t *testing.T) {
+	filas, registro, _ := novasFilasTeste(t)
+	for _, id := range []models.MotoristaID{"primeiro", "segundo"} {
+		_, err := registro.FicarOnline(id, aeroportoLat-0.005, aeroportoLng)
+		require.NoError(t, err)
+	}
+
+	// "colado" está mais perto do embarque, mas fora da fila
+	localizador := &localizadorFake{posicoes: map[models.MotoristaID][2]float64{
+		"primeiro": {aeroportoLat - 0.005, aeroportoLng},
+		"segundo":  {aeroportoLat - 0.005, aeroportoLng},
+		"colado":   {aeroportoLat, aeroportoLng + 0.0005},
+	}}
+	despacho, criadas, _ := novoDespachoTeste(localizador, novaElegibilidadeFake(), ConfigDespacho{})
+	despacho.DefinirFilas(filas)
+
+	ofertas, err := despacho.Despachar(models.Corrida{ID: 1, OrigemLat: aeroportoLat, OrigemLng: aeroportoLng})
+	require.NoError(t, err)
+	require.Len(t, ofertas, 1)
+	assert.Equal(t, models.MotoristaID("primeiro"), ofertas[0].MotoristaID)
+
+	// Recusa passa a corrida ao próximo da fila
+	responder(despacho, ofertas[0], models.NotificacaoRecusada)
+	require.Len(t, *criadas, 2)
+	assert.Equal(t, models.MotoristaID("segundo"), (*criadas)[1].MotoristaID)
+
+	// Com a fila esgotada, vale a busca por proximidade
+	responder(despacho, (*criadas)[1], models.NotificacaoRecusada)
+	require.Len(t, *criadas, 3)
+	assert.Equal(t, models.MotoristaID("colado"), (*criadas)[2].MotoristaID)
+
+	// Embarques fora do ponto seguem a estratégia da cidade, a partir do mais próximo
+	ofertas, err = despacho.Despachar(models.Corrida{ID: 2, OrigemLat: aeroportoLat + 0.01, OrigemLng: aeroportoLng})
+	require.NoError(t, err)
+	require.NotEmpty(t, ofertas)
+	assert.Equal(t, models.MotoristaID("colado"), ofertas[0].MotoristaID)
+}
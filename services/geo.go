@@ -0,0 +1,30 @@
+package services
+
+import "math"
+
+// raioTerraKm é o raio médio da Terra usado no cálculo de distâncias
+const raioTerraKm = 6371.0
+
+// DistanciaKm calcula a distância em linha reta entre dois pontos pela fórmula de haversine
+func DistanciaKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := grausParaRadianos(lat2 - lat1)
+	dLng := grausParaRadianos(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(grausParaRadianos(lat1))*math.Cos(grausParaRadianos(lat2))*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * raioTerraKm * math.Asin(math.Sqrt(a))
+}
+
+func grausParaRadianos(graus float64) float64 {
+	return graus * math.Pi / 180
+}
+
+// CoordenadasValidas verifica se latitude e longitude estão dentro dos limites e não são nulas
+func CoordenadasValidas(lat, lng float64) bool {
+	if lat == 0 && lng == 0 {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"taxi-service/models"
+)
+
+// Parâmetros do índice espacial
+const (
+	TamanhoCelulaPadraoKm = 1.0
+	kmPorGrauLatitude     = 111.32
+	cosLatitudeMinimo     = 0.01 // evita células degeneradas perto dos polos
+)
+
+// celulaIndice identifica uma célula da grade em latitude/longitude
+type celulaIndice struct {
+	lat int
+	lng int
+}
+
+// pontoIndice é a posição de um motorista e a célula onde ele está guardado
+type pontoIndice struct {
+	lat    float64
+	lng    float64
+	celula celulaIndice
+}
+
+// IndiceEspacial é uma grade uniforme de células (equivalente a um geohash de precisão fixa)
+// que responde buscas por raio e pelos k mais próximos sem percorrer todos os motoristas.
+// Não é seguro para uso concorrente; quem o utiliza deve protegê-lo com seu próprio mutex.
+// Posições que cruzam o antimeridiano (longitude ±180) não são tratadas.
+type IndiceEspacial struct {
+	tamanhoCelulaGraus float64
+	celulas            map[celulaIndice]map[models.MotoristaID]struct{}
+	pontos             map[models.MotoristaID]pontoIndice
+}
+
+// NewIndiceEspacial cria um índice com células do tamanho informado (em km de latitude)
+func NewIndiceEspacial(tamanhoCelulaKm float64) *IndiceEspacial {
+	if tamanhoCelulaKm <= 0 {
+		tamanhoCelulaKm = TamanhoCelulaPadraoKm
+	}
+	return &IndiceEspacial{
+		tamanhoCelulaGraus: tamanhoCelulaKm / kmPorGrauLatitude,
+		celulas:            make(map[celulaIndice]map[models.MotoristaID]struct{}),
+		pontos:             make(map[models.MotoristaID]pontoIndice),
+	}
+}
+
+// Tamanho retorna quantos motoristas estão indexados
+func (i *IndiceEspacial) Tamanho() int {
+	return len(i.pontos)
+}
+
+// Atualizar insere ou move o motorista para a posição informada
+func (i *IndiceEspacial) Atualizar(id models.MotoristaID, lat, lng float64) {
+	celula := i.celulaDe(lat, lng)
+
+	if anterior, existe := i.pontos[id]; existe && anterior.celula != celula {
+		i.removerDaCelula(id, anterior.celula)
+	}
+
+	membros, existe := i.celulas[celula]
+	if !existe {
+		membros = make(map[models.MotoristaID]struct{})
+		i.celulas[celula] = membros
+	}
+	membros[id] = struct{}{}
+	i.pontos[id] = pontoIndice{lat: lat, lng: lng, celula: celula}
+}
+
+// Remover retira o motorista do índice
+func (i *IndiceEspacial) Remover(id models.MotoristaID) {
+	ponto, existe := i.pontos[id]
+	if !existe {
+		return
+	}
+	i.removerDaCelula(id, ponto.celula)
+	delete(i.pontos, id)
+}
+
+// BuscarRaio retorna os motoristas a até raioKm do ponto, do mais próximo ao mais distante
+func (i *IndiceEspacial) BuscarRaio(lat, lng, raioKm float64) []MotoristaProximo {
+	deltaLat := raioKm / kmPorGrauLatitude
+	deltaLng := raioKm / (kmPorGrauLatitude * cosLatitude(lat))
+
+	minimo := i.celulaDe(lat-deltaLat, lng-deltaLng)
+	maximo := i.celulaDe(lat+deltaLat, lng+deltaLng)
+
+	var encontrados []MotoristaProximo
+	for cLat := minimo.lat; cLat <= maximo.lat; cLat++ {
+		for cLng := minimo.lng; cLng <= maximo.lng; cLng++ {
+			encontrados = i.coletarCelula(encontrados, celulaIndice{lat: cLat, lng: cLng}, lat, lng, raioKm)
+		}
+	}
+
+	ordenarPorDistancia(encontrados)
+	return encontrados
+}
+
+// BuscarMaisProximos retorna até k motoristas a até raioKm do ponto, do mais próximo ao
+// mais distante. A busca percorre anéis de células em volta do ponto e para assim que
+// nenhuma célula ainda não visitada pode conter alguém mais próximo que o k-ésimo encontrado.
+func (i *IndiceEspacial) BuscarMaisProximos(lat, lng float64, k int, raioKm float64) []MotoristaProximo {
+	if k <= 0 || len(i.pontos) == 0 {
+		return nil
+	}
+
+	centro := i.celulaDe(lat, lng)
+	// Menor dimensão da célula em km: a longitude encolhe com o cosseno da latitude
+	celulaKm := i.tamanhoCelulaGraus * kmPorGrauLatitude * cosLatitude(lat)
+	anelMaximo := int(math.Ceil(raioKm/celulaKm)) + 1
+
+	var encontrados []MotoristaProximo
+	for anel := 0; anel <= anelMaximo; anel++ {
+		for cLat := centro.lat - anel; cLat <= centro.lat+anel; cLat++ {
+			for cLng := centro.lng - anel; cLng <= centro.lng+anel; cLng++ {
+				// Apenas a borda do anel; o interior já foi visitado
+				if cLat != centro.lat-anel && cLat != centro.lat+anel && cLng != centro.lng-anel && cLng != centro.lng+anel {
+					continue
+				}
+				encontrados = i.coletarCelula(encontrados, celulaIndice{lat: cLat, lng: cLng}, lat, lng, raioKm)
+			}
+		}
+
+		if len(encontrados) >= k {
+			ordenarPorDistancia(encontrados)
+			// Qualquer ponto fora dos anéis visitados está a pelo menos anel*celulaKm
+			if encontrados[k-1].DistanciaKm <= float64(anel)*celulaKm {
+				break
+			}
+		}
+	}
+
+	ordenarPorDistancia(encontrados)
+	if len(encontrados) > k {
+		encontrados = encontrados[:k]
+	}
+	return encontrados
+}
+
+// coletarCelula adiciona os motoristas da célula que estão dentro do raio
+func (i *IndiceEspacial) coletarCelula(encontrados []MotoristaProximo, celula celulaIndice, lat, lng, raioKm float64) []MotoristaProximo {
+	for id := range i.celulas[celula] {
+		ponto := i.pontos[id]
+		distancia := DistanciaKm(lat, lng, ponto.lat, ponto.lng)
+		if distancia <= raioKm {
+			encontrados = append(encontrados, MotoristaProximo{
+				ID:          id,
+				Lat:         ponto.lat,
+				Lng:         ponto.lng,
+				DistanciaKm: distancia,
+			})
+		}
+	}
+	return encontrados
+}
+
+func (i *IndiceEspacial) removerDaCelula(id models.MotoristaID, celula celulaIndice) {
+	membros := i.celulas[celula]
+	delete(membros, id)
+	if len(membros) == 0 {
+		delete(i.celulas, celula)
+	}
+}
+
+func (i *IndiceEspacial) celulaDe(lat, lng float64) celulaIndice {
+	return celulaIndice{
+		lat: int(math.Floor(lat / i.tamanhoCelulaGraus)),
+		lng: int(math.Floor(lng / i.tamanhoCelulaGraus)),
+	}
+}
+
+func cosLatitude(lat float64) float64 {
+	return math.Max(math.Cos(grausParaRadianos(lat)), cosLatitudeMinimo)
+}
+
+// ordenarPorDistancia ordena do mais próximo ao mais distante, com desempate pelo ID
+func ordenarPorDistancia(motoristas []MotoristaProximo) {
+	sort.Slice(motoristas, func(a, b int) bool {
+		if motoristas[a].DistanciaKm != motoristas[b].DistanciaKm {
+			return motoristas[a].DistanciaKm < motoristas[b].DistanciaKm
+		}
+		return motoristas[a].ID < motoristas[b].ID
+	})
+}
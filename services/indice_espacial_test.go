@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// Região metropolitana do Recife, aproximadamente 45 x 45 km
+const (
+	regiaoLatMin = -8.30
+	regiaoLatMax = -7.90
+	regiaoLngMin = -35.10
+	regiaoLngMax = -34.80
+)
+
+// frotaAleatoria gera posições reproduzíveis espalhadas pela região
+func frotaAleatoria(quantidade int) map[models.MotoristaID][2]float64 {
+	gerador := rand.New(rand.NewSource(42))
+	frota := make(map[models.MotoristaID][2]float64, quantidade)
+	for i := 0; i < quantidade; i++ {
+		frota[models.MotoristaID(fmt.Sprintf("m%d", i))] = [2]float64{
+			regiaoLatMin + gerador.Float64()*(regiaoLatMax-regiaoLatMin),
+			regiaoLngMin + gerador.Float64()*(regiaoLngMax-regiaoLngMin),
+		}
+	}
+	return frota
+}
+
+func indiceComFrota(frota map[models.MotoristaID][2]float64) *IndiceEspacial {
+	indice := NewIndiceEspacial(TamanhoCelulaPadraoKm)
+	for id, pos := range frota {
+		indice.Atualizar(id, pos[0], pos[1])
+	}
+	return indice
+}
+
+// varreduraLinear é a referência: percorre todos os motoristas
+func varreduraLinear(frota map[models.MotoristaID][2]float64, lat, lng, raioKm float64) []MotoristaProximo {
+	var encontrados []MotoristaProximo
+	for id, pos := range frota {
+		distancia := DistanciaKm(lat, lng, pos[0], pos[1])
+		if distancia <= raioKm {
+			encontrados = append(encontrados, MotoristaProximo{ID: id, Lat: pos[0], Lng: pos[1], DistanciaKm: distancia})
+		}
+	}
+	ordenarPorDistancia(encontrados)
+	return encontrados
+}
+
+func TestIndiceEspacial(t *testing.T) {
+	frota := frotaAleatoria(5000)
+	indice := indiceComFrota(frota)
+	require.Equal(t, 5000, indice.Tamanho())
+
+	t.Run("Busca por raio igual à varredura linear", func(t *testing.T) {
+		for _, raio := range []float64{0.5, 2, 5} {
+			esperado := varreduraLinear(frota, embarqueLat, embarqueLng, raio)
+			assert.Equal(t, esperado, indice.BuscarRaio(embarqueLat, embarqueLng, raio), "raio %.1f km", raio)
+		}
+	})
+
+	t.Run("k mais próximos iguais aos primeiros da varredura linear", func(t *testing.T) {
+		for _, k := range []int{1, 5, 50} {
+			esperado := varreduraLinear(frota, embarqueLat, embarqueLng, 10)[:k]
+			assert.Equal(t, esperado, indice.BuscarMaisProximos(embarqueLat, embarqueLng, k, 10), "k=%d", k)
+		}
+	})
+
+	t.Run("k mais próximos respeita o raio máximo", func(t *testing.T) {
+		esperado := varreduraLinear(frota, embarqueLat, embarqueLng, 0.3)
+		assert.Equal(t, esperado, indice.BuscarMaisProximos(embarqueLat, embarqueLng, 1000, 0.3))
+	})
+
+	t.Run("Mover e remover motoristas", func(t *testing.T) {
+		indice := NewIndiceEspacial(TamanhoCelulaPadraoKm)
+		indice.Atualizar("a", embarqueLat, embarqueLng)
+		indice.Atualizar("a", -8.20, -35.00) // ~17 km de distância
+
+		assert.Empty(t, indice.BuscarRaio(embarqueLat, embarqueLng, 2))
+		assert.Len(t, indice.BuscarRaio(-8.20, -35.00, 2), 1)
+
+		indice.Remover("a")
+		assert.Zero(t, indice.Tamanho())
+		assert.Empty(t, indice.BuscarRaio(-8.20, -35.00, 2))
+		assert.Empty(t, indice.celulas)
+	})
+}
+
+func BenchmarkIndiceEspacialBuscarRaio(b *testing.B) {
+	for _, quantidade := range []int{10000, 50000} {
+		indice := indiceComFrota(frotaAleatoria(quantidade))
+		b.Run(fmt.Sprintf("%d_motoristas", quantidade), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				indice.BuscarRaio(embarqueLat, embarqueLng, RaioDespachoPadraoKm)
+			}
+		})
+	}
+}
+
+func BenchmarkIndiceEspacialBuscarMaisProximos(b *testing.B) {
+	for _, quantidade := range []int{10000, 50000} {
+		indice := indiceComFrota(frotaAleatoria(quantidade))
+		b.Run(fmt.Sprintf("%d_motoristas", quantidade), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				indice.BuscarMaisProximos(embarqueLat, embarqueLng, 10, RaioDespachoPadraoKm)
+			}
+		})
+	}
+}
+
+func BenchmarkIndiceEspacialAtualizar(b *testing.B) {
+	frota := frotaAleatoria(50000)
+	indice := indiceComFrota(frota)
+	ids := make([]models.MotoristaID, 0, len(frota))
+	for id := range frota {
+		ids = append(ids, id)
+	}
+	gerador := rand.New(rand.NewSource(7))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		id := ids[n%len(ids)]
+		pos := frota[id]
+		indice.Atualizar(id, pos[0]+(gerador.Float64()-0.5)*0.002, pos[1]+(gerador.Float64()-0.5)*0.002)
+	}
+}
+
+// BenchmarkVarreduraLinear serve de comparação com o índice
+func BenchmarkVarreduraLinear(b *testing.B) {
+	frota := frotaAleatoria(50000)
+	for n := 0; n < b.N; n++ {
+		varreduraLinear(frota, embarqueLat, embarqueLng, RaioDespachoPadraoKm)
+	}
+}
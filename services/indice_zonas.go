@@ -0,0 +1,152 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"taxi-service/models"
+)
+
+// Parâmetros do índice de zonas
+const (
+	tamanhoCelulaZonaGraus = 0.05  // ~5,5 km de latitude
+	maxCelulasPorPoligono  = 40000 // polígonos maiores ficam na lista verificada sempre
+)
+
+// caixaLimite é o retângulo envolvente de um polígono em graus
+type caixaLimite struct {
+	minLat, minLng, maxLat, maxLng float64
+}
+
+func (c caixaLimite) contem(lat, lng float64) bool {
+	return lat >= c.minLat && lat <= c.maxLat && lng >= c.minLng && lng <= c.maxLng
+}
+
+// poligonoIndexado guarda um polígono, sua caixa envolvente e a posição da zona dona
+type poligonoIndexado struct {
+	zona     int
+	poligono models.Poligono
+	caixa    caixaLimite
+}
+
+// indiceZonas localiza as zonas que contêm um ponto. Cada polígono é registrado nas células
+// da grade que sua caixa envolvente cobre; uma consulta verifica apenas os polígonos da célula
+// do ponto, primeiro pela caixa e depois pelo algoritmo de raio (ray casting).
+// É imutável depois de construído: o ZonaService troca o índice inteiro a cada alteração.
+type indiceZonas struct {
+	zonas     []models.Zona
+	celulas   map[celulaIndice][]int
+	poligonos []poligonoIndexado
+	grandes   []int
+}
+
+// novoIndiceZonas indexa as zonas informadas, na ordem recebida
+func novoIndiceZonas(zonas []models.Zona) *indiceZonas {
+	indice := &indiceZonas{
+		zonas:   zonas,
+		celulas: make(map[celulaIndice][]int),
+	}
+	for i, zona := range zonas {
+		for _, poligono := range zona.Geometria.Poligonos {
+			if len(poligono) == 0 || len(poligono[0]) == 0 {
+				continue
+			}
+			indice.adicionar(poligonoIndexado{zona: i, poligono: poligono, caixa: caixaDe(poligono[0])})
+		}
+	}
+	return indice
+}
+
+func (i *indiceZonas) adicionar(p poligonoIndexado) {
+	posicao := len(i.poligonos)
+	i.poligonos = append(i.poligonos, p)
+
+	minimo := celulaZonaDe(p.caixa.minLat, p.caixa.minLng)
+	maximo := celulaZonaDe(p.caixa.maxLat, p.caixa.maxLng)
+	if (maximo.lat-minimo.lat+1)*(maximo.lng-minimo.lng+1) > maxCelulasPorPoligono {
+		i.grandes = append(i.grandes, posicao)
+		return
+	}
+	for lat := minimo.lat; lat <= maximo.lat; lat++ {
+		for lng := minimo.lng; lng <= maximo.lng; lng++ {
+			celula := celulaIndice{lat: lat, lng: lng}
+			i.celulas[celula] = append(i.celulas[celula], posicao)
+		}
+	}
+}
+
+// localizar retorna as posições das zonas que contêm o ponto, na ordem de indexação e sem repetição
+func (i *indiceZonas) localizar(lat, lng float64) []int {
+	var encontradas []int
+	vistas := make(map[int]bool)
+	verificar := func(posicoes []int) {
+		for _, posicao := range posicoes {
+			p := i.poligonos[posicao]
+			if vistas[p.zona] || !p.caixa.contem(lat, lng) || !poligonoContem(p.poligono, lat, lng) {
+				continue
+			}
+			vistas[p.zona] = true
+			encontradas = append(encontradas, p.zona)
+		}
+	}
+	verificar(i.celulas[celulaZonaDe(lat, lng)])
+	verificar(i.grandes)
+
+	sort.Ints(encontradas)
+	return encontradas
+}
+
+// possuiTipo indica se alguma zona indexada é do tipo informado
+func (i *indiceZonas) possuiTipo(tipo models.TipoZona) bool {
+	for _, zona := range i.zonas {
+		if zona.Tipo == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+func celulaZonaDe(lat, lng float64) celulaIndice {
+	return celulaIndice{
+		lat: int(math.Floor(lat / tamanhoCelulaZonaGraus)),
+		lng: int(math.Floor(lng / tamanhoCelulaZonaGraus)),
+	}
+}
+
+func caixaDe(anel []models.Coordenada) caixaLimite {
+	caixa := caixaLimite{minLat: math.Inf(1), minLng: math.Inf(1), maxLat: math.Inf(-1), maxLng: math.Inf(-1)}
+	for _, ponto := range anel {
+		caixa.minLng = math.Min(caixa.minLng, ponto[0])
+		caixa.maxLng = math.Max(caixa.maxLng, ponto[0])
+		caixa.minLat = math.Min(caixa.minLat, ponto[1])
+		caixa.maxLat = math.Max(caixa.maxLat, ponto[1])
+	}
+	return caixa
+}
+
+// poligonoContem indica se o ponto está dentro do anel externo e fora de todos os buracos
+func poligonoContem(poligono models.Poligono, lat, lng float64) bool {
+	if !anelContem(poligono[0], lat, lng) {
+		return false
+	}
+	for _, buraco := range poligono[1:] {
+		if anelContem(buraco, lat, lng) {
+			return false
+		}
+	}
+	return true
+}
+
+// anelContem aplica o algoritmo de raio: um ponto está dentro se uma semirreta partindo dele
+// cruza as arestas do anel um número ímpar de vezes
+func anelContem(anel []models.Coordenada, lat, lng float64) bool {
+	dentro := false
+	for i, j := 0, len(anel)-1; i < len(anel); j, i = i, i+1 {
+		xi, yi := anel[i][0], anel[i][1]
+		xj, yj := anel[j][0], anel[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			dentro = !dentro
+		}
+	}
+	return dentro
+}
@@ -23,6 +23,7 @@ type CadastroMotoristaRequest struct {
 	ValidadeCNH      string `json:"validade_cnh" validate:"required"`
 	PlacaVeiculo     string `json:"placa_veiculo" validate:"required"`
 	ModeloVeiculo    string `json:"modelo_veiculo" validate:"required,min=3,max=100"`
+	CategoriaVeiculo string `json:"categoria_veiculo"` // comum, executivo, van ou moto (padrão: comum)
 	Telefone         string `json:"telefone" validate:"required"`
 	Email            string `json:"email" validate:"required,email"`
 	Senha            string `json:"senha" validate:"required,min=8"`
@@ -37,15 +38,19 @@ type UploadDocumentoRequest struct {
 	Tamanho        int64  `json:"tamanho" validate:"required"`
 }
 
-// MotoristaService define a interface para serviços de motorista
+// ErrVersaoConflito é retornado quando a versão esperada (If-Match) não é a atual
+var ErrVersaoConflito = repositories.ErrVersaoConflito
+
+// MotoristaService define a interface para serviços de motorista.
+// Nas operações de alteração, versaoEsperada igual a 0 dispensa a verificação de versão.
 type MotoristaService interface {
 	CadastrarMotorista(request CadastroMotoristaRequest) (*models.Motorista, error)
 	ValidarDadosCadastro(request CadastroMotoristaRequest) error
-	UploadDocumento(motoristaID string, request UploadDocumentoRequest) error
-	ValidarDocumentos(motoristaID string) error
-	AprovarMotorista(motoristaID string) error
-	RejeitarMotorista(motoristaID string, motivo string) error
-	BuscarMotorista(id string) (*models.Motorista, error)
+	UploadDocumento(motoristaID models.MotoristaID, request UploadDocumentoRequest, versaoEsperada int) error
+	ValidarDocumentos(motoristaID models.MotoristaID, versaoEsperada int) error
+	AprovarMotorista(motoristaID models.MotoristaID, versaoEsperada int) error
+	RejeitarMotorista(motoristaID models.MotoristaID, motivo string, versaoEsperada int) error
+	BuscarMotorista(id models.MotoristaID) (*models.Motorista, error)
 	VerificarForcaSenha(senha string) (string, error)
 }
 
@@ -111,22 +116,23 @@ func (s *MotoristaServiceImpl) CadastrarMotorista(request CadastroMotoristaReque
 
 	// Criar motorista
 	motorista := &models.Motorista{
-		ID:             uuid.New().String(),
-		Nome:           request.Nome,
-		DataNascimento: dataNascimento,
-		CPF:            limparString(request.CPF),
-		CNH:            limparString(request.CNH),
-		CategoriaCNH:   models.CategoriaCNH(request.CategoriaCNH),
-		ValidadeCNH:    validadeCNH,
-		PlacaVeiculo:   strings.ToUpper(strings.TrimSpace(request.PlacaVeiculo)),
-		ModeloVeiculo:  request.ModeloVeiculo,
-		Telefone:       limparString(request.Telefone),
-		Email:          strings.ToLower(strings.TrimSpace(request.Email)),
-		Senha:          request.Senha, // Em produção, seria hasheada
-		Status:         models.StatusAguardandoAprovacao,
-		CriadoEm:       time.Now(),
-		AtualizadoEm:   time.Now(),
-		Documentos:     []models.Documento{},
+		ID:               models.MotoristaID(uuid.New().String()),
+		Nome:             request.Nome,
+		DataNascimento:   dataNascimento,
+		CPF:              limparString(request.CPF),
+		CNH:              limparString(request.CNH),
+		CategoriaCNH:     models.CategoriaCNH(request.CategoriaCNH),
+		ValidadeCNH:      validadeCNH,
+		PlacaVeiculo:     strings.ToUpper(strings.TrimSpace(request.PlacaVeiculo)),
+		ModeloVeiculo:    request.ModeloVeiculo,
+		CategoriaVeiculo: models.CategoriaVeiculo(request.CategoriaVeiculo).OuPadrao(),
+		Telefone:         limparString(request.Telefone),
+		Email:            strings.ToLower(strings.TrimSpace(request.Email)),
+		Senha:            request.Senha, // Em produção, seria hasheada
+		Status:           models.StatusAguardandoAprovacao,
+		CriadoEm:         time.Now(),
+		AtualizadoEm:     time.Now(),
+		Documentos:       []models.Documento{},
 	}
 
 	// Salvar no repositório
@@ -189,6 +195,14 @@ func (s *MotoristaServiceImpl) ValidarDadosCadastro(request CadastroMotoristaReq
 		return errors.New("formato de placa inválido")
 	}
 
+	categoria := models.CategoriaVeiculo(request.CategoriaVeiculo).OuPadrao()
+	if !categoria.Valida() {
+		return errors.New("categoria de veículo inválida. Use comum, executivo, van ou moto")
+	}
+	if !categoria.HabilitadaPor(models.CategoriaCNH(request.CategoriaCNH)) {
+		return fmt.Errorf("categoria da CNH não habilita a conduzir veículos da categoria %s", categoria)
+	}
+
 	// Validar força da senha
 	if _, err := models.ValidarForcaSenha(request.Senha); err != nil {
 		return err
@@ -198,7 +212,7 @@ func (s *MotoristaServiceImpl) ValidarDadosCadastro(request CadastroMotoristaReq
 }
 
 // UploadDocumento adiciona um documento ao motorista
-func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request UploadDocumentoRequest) error {
+func (s *MotoristaServiceImpl) UploadDocumento(motoristaID models.MotoristaID, request UploadDocumentoRequest, versaoEsperada int) error {
 	// validar formato e tamanho antes de qualquer acesso ao repo
 	if err := models.ValidarDocumento(request.Formato, request.Tamanho); err != nil {
 		return err
@@ -210,12 +224,17 @@ func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request Uploa
 		return errors.New("motorista não encontrado")
 	}
 
+	if err := verificarVersao(motorista.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
 	// Verificar se já existe documento do mesmo tipo
 	for i, doc := range motorista.Documentos {
 		if doc.TipoDocumento == request.TipoDocumento {
 			// Substituir documento existente
 			motorista.Documentos[i] = models.Documento{
 				ID:             uuid.New().String(),
+				MotoristaID:    motorista.ID,
 				TipoDocumento:  request.TipoDocumento,
 				CaminhoArquivo: request.CaminhoArquivo,
 				Formato:        strings.ToUpper(request.Formato),
@@ -232,6 +251,7 @@ func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request Uploa
 	// Adicionar novo documento
 	documento := models.Documento{
 		ID:             uuid.New().String(),
+		MotoristaID:    motorista.ID,
 		TipoDocumento:  request.TipoDocumento,
 		CaminhoArquivo: request.CaminhoArquivo,
 		Formato:        strings.ToUpper(request.Formato),
@@ -281,12 +301,16 @@ func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request Uploa
 }
 
 // ValidarDocumentos executa validação automática de documentos
-func (s *MotoristaServiceImpl) ValidarDocumentos(motoristaID string) error {
+func (s *MotoristaServiceImpl) ValidarDocumentos(motoristaID models.MotoristaID, versaoEsperada int) error {
 	motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
 	if err != nil {
 		return errors.New("motorista não encontrado")
 	}
 
+	if err := verificarVersao(motorista.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
 	// Simular validação automática (aprovação automática para testes)
 	todosAprovados := true
 	for i := range motorista.Documentos {
@@ -311,12 +335,16 @@ func (s *MotoristaServiceImpl) ValidarDocumentos(motoristaID string) error {
 }
 
 // AprovarMotorista aprova manualmente um motorista
-func (s *MotoristaServiceImpl) AprovarMotorista(motoristaID string) error {
+func (s *MotoristaServiceImpl) AprovarMotorista(motoristaID models.MotoristaID, versaoEsperada int) error {
 	motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
 	if err != nil {
 		return errors.New("motorista não encontrado")
 	}
 
+	if err := verificarVersao(motorista.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
 	motorista.Status = models.StatusAprovado
 	motorista.AtualizadoEm = time.Now()
 
@@ -328,12 +356,16 @@ func (s *MotoristaServiceImpl) AprovarMotorista(motoristaID string) error {
 }
 
 // RejeitarMotorista rejeita um motorista com motivo
-func (s *MotoristaServiceImpl) RejeitarMotorista(motoristaID string, motivo string) error {
+func (s *MotoristaServiceImpl) RejeitarMotorista(motoristaID models.MotoristaID, motivo string, versaoEsperada int) error {
 	motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
 	if err != nil {
 		return errors.New("motorista não encontrado")
 	}
 
+	if err := verificarVersao(motorista.Versao, versaoEsperada); err != nil {
+		return err
+	}
+
 	motorista.Status = models.StatusRejeitado
 	motorista.AtualizadoEm = time.Now()
 
@@ -345,7 +377,7 @@ func (s *MotoristaServiceImpl) RejeitarMotorista(motoristaID string, motivo stri
 }
 
 // BuscarMotorista busca um motorista por ID
-func (s *MotoristaServiceImpl) BuscarMotorista(id string) (*models.Motorista, error) {
+func (s *MotoristaServiceImpl) BuscarMotorista(id models.MotoristaID) (*models.Motorista, error) {
 	return s.motoristaRepo.BuscarPorID(id)
 }
 
@@ -354,6 +386,14 @@ func (s *MotoristaServiceImpl) VerificarForcaSenha(senha string) (string, error)
 	return models.ValidarForcaSenha(senha)
 }
 
+// verificarVersao compara a versão atual com a esperada pelo cliente (0 dispensa a verificação)
+func verificarVersao(atual, esperada int) error {
+	if esperada != 0 && esperada != atual {
+		return ErrVersaoConflito
+	}
+	return nil
+}
+
 // limparString remove caracteres especiais de strings como CPF, CNH e telefone
 func limparString(s string) string {
 	return regexp.MustCompile(`\D`).ReplaceAllString(s, "")
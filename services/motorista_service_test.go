@@ -23,7 +23,7 @@ func (m *MockMotoristaRepository) Criar(motorista *models.Motorista) error {
 	return args.Error(0)
 }
 
-func (m *MockMotoristaRepository) BuscarPorID(id string) (*models.Motorista, error) {
+func (m *MockMotoristaRepository) BuscarPorID(id models.MotoristaID) (*models.Motorista, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -60,7 +60,7 @@ func (m *MockMotoristaRepository) Atualizar(motorista *models.Motorista) error {
 	return args.Error(0)
 }
 
-func (m *MockMotoristaRepository) Deletar(id string) error {
+func (m *MockMotoristaRepository) Deletar(id models.MotoristaID) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -214,6 +214,21 @@ func TestCadastrarMotorista(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("CNH Category Does Not Allow Vehicle Category", func(t *testing.T) {
+		service := NewMotoristaService(new(MockMotoristaRepository), new(MockEmailService))
+		request := createValidRequest()
+		request.CategoriaVeiculo = "moto"
+
+		_, err := service.CadastrarMotorista(request)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "não habilita")
+
+		request.CategoriaVeiculo = "helicoptero"
+		_, err = service.CadastrarMotorista(request)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "categoria de veículo inválida")
+	})
+
 	// Add more test cases as needed
 }
 
@@ -224,7 +239,7 @@ func TestUploadDocumento(t *testing.T) {
 	service := NewMotoristaService(mockRepo, mockEmail)
 
 	// Create a test driver
-	testDriverID := uuid.New().String()
+	testDriverID := models.MotoristaID(uuid.New().String())
 	testDriver := &models.Motorista{
 		ID:         testDriverID,
 		Nome:       "Test Driver",
@@ -247,7 +262,7 @@ func TestUploadDocumento(t *testing.T) {
 		mockRepo.On("Atualizar", mock.AnythingOfType("*models.Motorista")).Return(nil)
 
 		// Execute method under test
-		err := service.UploadDocumento(testDriverID, validUploadRequest)
+		err := service.UploadDocumento(testDriverID, validUploadRequest, 0)
 
 		// Assertions
 		require.NoError(t, err)
@@ -286,7 +301,7 @@ func TestUploadDocumento(t *testing.T) {
 		mockRepo.On("Atualizar", mock.AnythingOfType("*models.Motorista")).Return(nil).Once()
 
 		// Execute first upload
-		err := service.UploadDocumento(testDriverID, crlvRequest)
+		err := service.UploadDocumento(testDriverID, crlvRequest, 0)
 		require.NoError(t, err)
 
 		// Setup mock expectations for selfie upload
@@ -324,7 +339,7 @@ func TestUploadDocumento(t *testing.T) {
 		mockEmail.On("EnviarEmailRecebimentoDocumentos", "test@driver.com", "Test Driver").Return(nil)
 
 		// Execute second upload
-		err = service.UploadDocumento(testDriverID, selfieRequest)
+		err = service.UploadDocumento(testDriverID, selfieRequest, 0)
 		require.NoError(t, err)
 
 		// Final driver state with status change
@@ -384,7 +399,7 @@ func TestUploadDocumento(t *testing.T) {
 			Tamanho:        6 * 1024 * 1024, // 6MB - too large
 		}
 
-		err := service.UploadDocumento(testDriverID, largeFileRequest)
+		err := service.UploadDocumento(testDriverID, largeFileRequest, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "arquivo muito grande")
 	})
@@ -404,7 +419,7 @@ func TestUploadDocumento(t *testing.T) {
 
 		// No need to mock repository calls since validation should fail first
 
-		err := service.UploadDocumento(testDriverID, invalidFormatRequest)
+		err := service.UploadDocumento(testDriverID, invalidFormatRequest, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "formato não suportado")
 	})
@@ -477,7 +492,7 @@ func TestUploadDocumento(t *testing.T) {
 		mockEmail.On("EnviarEmailAprovacao", "test@driver.com", "Test Driver").Return(nil)
 
 		// Execute validation
-		err := service.ValidarDocumentos(testDriverID)
+		err := service.ValidarDocumentos(testDriverID, 0)
 		require.NoError(t, err)
 
 		// Setup for status check
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// usarDiretorioTemporario faz as notificações serem gravadas em um diretório descartável
+// e usa o CorridaService informado como atribuidor durante o teste
+func usarDiretorioTemporario(t *testing.T, atribuidor AtribuidorCorrida) {
+	t.Helper()
+	original, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	DefinirRepositorioNotificacoes(repositories.NewJSONNotificacaoRepository())
+	RegistrarAtribuidorCorrida(atribuidor)
+
+	t.Cleanup(func() {
+		RegistrarAtribuidorCorrida(nil)
+		DefinirRepositorioNotificacoes(nil)
+		_ = os.Chdir(original)
+	})
+}
+
+// ofertarParaTodos cria uma oferta pendente da corrida para cada motorista
+func ofertarParaTodos(t *testing.T, corridaID int, motoristas ...models.MotoristaID) []models.NotificacaoCorrida {
+	t.Helper()
+	var ofertas []models.NotificacaoCorrida
+	for _, motorista := range motoristas {
+		oferta := models.NotificacaoCorrida{MotoristaID: motorista, CorridaID: uint(corridaID)}
+		require.NoError(t, CreateNotificacaoCorrida(&oferta))
+		ofertas = append(ofertas, oferta)
+	}
+	return ofertas
+}
+
+func TestAceitarNotificacaoCorrida(t *testing.T) {
+	t.Run("Aceite atribui a corrida e cancela as demais ofertas", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		usarDiretorioTemporario(t, corridas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+		ofertas := ofertarParaTodos(t, corrida.ID, "1", "2", "3", "4")
+		outra := ofertarParaTodos(t, corrida.ID+1000, "5")[0]
+
+		erros := make([]error, len(ofertas))
+		var wg sync.WaitGroup
+		for i, oferta := range ofertas {
+			wg.Add(1)
+			go func(i int, oferta models.NotificacaoCorrida) {
+				defer wg.Done()
+				erros[i] = AceitarNotificacaoCorrida(oferta.ID, oferta.MotoristaID)
+			}(i, oferta)
+		}
+		wg.Wait()
+
+		vencedor := -1
+		for i, err := range erros {
+			if err == nil {
+				require.Equal(t, -1, vencedor, "apenas um motorista pode levar a corrida")
+				vencedor = i
+				continue
+			}
+			assert.ErrorIs(t, err, ErrCorridaJaAceita)
+		}
+		require.NotEqual(t, -1, vencedor)
+
+		atual, err := corridas.GetCorridaPorID(corrida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusMotoristaEncontrado, atual.Status)
+		assert.Equal(t, ofertas[vencedor].MotoristaID, atual.MotoristaID)
+
+		for i, oferta := range ofertas {
+			gravada, err := GetNotificacaoCorrida(oferta.ID)
+			require.NoError(t, err)
+			if i == vencedor {
+				assert.Equal(t, models.NotificacaoAceita, gravada.Status)
+			} else {
+				assert.Equal(t, models.NotificacaoCancelada, gravada.Status)
+			}
+		}
+
+		// Ofertas de outras corridas não são afetadas
+		gravada, err := GetNotificacaoCorrida(outra.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacaoPendente, gravada.Status)
+	})
+
+	t.Run("Corrida aceita por outro caminho retira a oferta com 409", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		usarDiretorioTemporario(t, corridas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+		oferta := ofertarParaTodos(t, corrida.ID, "1")[0]
+		require.NoError(t, corridas.AceitarCorrida(corrida.ID, "9", 0))
+
+		err = AceitarNotificacaoCorrida(oferta.ID, oferta.MotoristaID)
+		assert.ErrorIs(t, err, ErrCorridaJaAceita)
+
+		gravada, _ := GetNotificacaoCorrida(oferta.ID)
+		assert.Equal(t, models.NotificacaoCancelada, gravada.Status)
+	})
+
+	t.Run("Motorista inelegível não leva a corrida e a oferta continua pendente", func(t *testing.T) {
+		elegibilidade := novaElegibilidadeFake()
+		elegibilidade.bloqueados["1"] = &ErroElegibilidade{Codigo: CodigoMotoristaOcupado, Mensagem: "ocupado"}
+		corridas := NewCorridaService(elegibilidade)
+		usarDiretorioTemporario(t, corridas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+		ofertas := ofertarParaTodos(t, corrida.ID, "1", "2")
+
+		var erroElegibilidade *ErroElegibilidade
+		assert.ErrorAs(t, AceitarNotificacaoCorrida(ofertas[0].ID, "1"), &erroElegibilidade)
+
+		gravada, _ := GetNotificacaoCorrida(ofertas[0].ID)
+		assert.Equal(t, models.NotificacaoPendente, gravada.Status)
+
+		// A reserva foi liberada: o próximo motorista ainda pode aceitar
+		require.NoError(t, AceitarNotificacaoCorrida(ofertas[1].ID, "2"))
+		atual, _ := corridas.GetCorridaPorID(corrida.ID)
+		assert.Equal(t, models.MotoristaID("2"), atual.MotoristaID)
+	})
+
+	t.Run("Oferta avulsa sem corrida cadastrada é aceita só na notificação", func(t *testing.T) {
+		usarDiretorioTemporario(t, NewCorridaService(novaElegibilidadeFake()))
+
+		oferta := ofertarParaTodos(t, 777, "1")[0]
+		require.NoError(t, AceitarNotificacaoCorrida(oferta.ID, oferta.MotoristaID))
+
+		gravada, _ := GetNotificacaoCorrida(oferta.ID)
+		assert.Equal(t, models.NotificacaoAceita, gravada.Status)
+	})
+}
+
+func TestNotificacoesConcorrentes(t *testing.T) {
+	t.Run("Criações simultâneas recebem IDs distintos", func(t *testing.T) {
+		usarDiretorioTemporario(t, nil)
+
+		const total = 50
+		ids := make(chan uint, total)
+		var wg sync.WaitGroup
+		for i := 0; i < total; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				oferta := models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 1}
+				if assert.NoError(t, CreateNotificacaoCorrida(&oferta)) {
+					ids <- oferta.ID
+				}
+			}()
+		}
+		wg.Wait()
+		close(ids)
+
+		vistos := map[uint]bool{}
+		for id := range ids {
+			assert.False(t, vistos[id], "ID %d repetido", id)
+			vistos[id] = true
+		}
+		todas, err := ListNotificacoesCorrida()
+		require.NoError(t, err)
+		assert.Len(t, todas, total)
+	})
+
+	t.Run("Aceite e recusa simultâneos da mesma oferta: só um vale", func(t *testing.T) {
+		usarDiretorioTemporario(t, nil)
+		oferta := ofertarParaTodos(t, 1, "1")[0]
+
+		var errAceite, errRecusa error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); errAceite = AceitarNotificacaoCorrida(oferta.ID, "1") }()
+		go func() { defer wg.Done(); errRecusa = RecusarNotificacaoCorrida(oferta.ID, "1") }()
+		wg.Wait()
+
+		gravada, err := GetNotificacaoCorrida(oferta.ID)
+		require.NoError(t, err)
+		if errAceite == nil {
+			assert.ErrorIs(t, errRecusa, ErrNotificacaoJaProcessada)
+			assert.Equal(t, models.NotificacaoAceita, gravada.Status)
+		} else {
+			assert.NoError(t, errRecusa)
+			assert.ErrorIs(t, errAceite, ErrNotificacaoJaProcessada)
+			assert.Equal(t, models.NotificacaoRecusada, gravada.Status)
+		}
+	})
+}
+
+func TestAlterarStatusNotificacao(t *testing.T) {
+	t.Run("Suporte cancela oferta presa e o status final não muda mais", func(t *testing.T) {
+		usarDiretorioTemporario(t, NewCorridaService(novaElegibilidadeFake()))
+		oferta := ofertarParaTodos(t, 777, "1")[0]
+
+		_, err := AlterarStatusNotificacao(oferta.ID, "voando", "suporte:ana", "teste")
+		assert.ErrorIs(t, err, ErrStatusNotificacaoInvalido)
+		_, err = AlterarStatusNotificacao(oferta.ID, models.NotificacaoCancelada, "suporte:ana", " ")
+		assert.ErrorIs(t, err, ErrAutoriaMudancaObrigatoria)
+
+		cancelada, err := AlterarStatusNotificacao(oferta.ID, models.NotificacaoCancelada, "suporte:ana", "oferta presa após queda do app")
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacaoCancelada, cancelada.Status)
+		require.Len(t, cancelada.Historico, 1)
+		assert.Equal(t, models.MudancaStatusNotificacao{
+			De:     models.NotificacaoPendente,
+			Para:   models.NotificacaoCancelada,
+			Por:    "suporte:ana",
+			Motivo: "oferta presa após queda do app",
+			Em:     cancelada.UpdatedAt,
+		}, cancelada.Historico[0])
+
+		for _, status := range []models.NotificacaoStatus{models.NotificacaoPendente, models.NotificacaoAceita, models.NotificacaoExpirada} {
+			atual, err := AlterarStatusNotificacao(oferta.ID, status, "suporte:ana", "reabrir")
+			assert.ErrorIs(t, err, ErrTransicaoNotificacaoInvalida)
+			assert.Equal(t, models.NotificacaoCancelada, atual.Status)
+		}
+		assert.ErrorIs(t, RecusarNotificacaoCorrida(oferta.ID, "1"), ErrNotificacaoJaProcessada)
+	})
+
+	t.Run("Aceite pelo suporte atribui a corrida e registra os cancelamentos do sistema", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		usarDiretorioTemporario(t, corridas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		require.NoError(t, err)
+		ofertas := ofertarParaTodos(t, corrida.ID, "1", "2")
+
+		aceita, err := AlterarStatusNotificacao(ofertas[0].ID, models.NotificacaoAceita, "suporte:ana", "motorista confirmou por telefone")
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacaoAceita, aceita.Status)
+		assert.Equal(t, "suporte:ana", aceita.Historico[0].Por)
+
+		atual, _ := corridas.GetCorridaPorID(corrida.ID)
+		assert.Equal(t, models.MotoristaID("1"), atual.MotoristaID)
+
+		outra, _ := GetNotificacaoCorrida(ofertas[1].ID)
+		assert.Equal(t, models.NotificacaoCancelada, outra.Status)
+		require.Len(t, outra.Historico, 1)
+		assert.Equal(t, models.AutorSistema, outra.Historico[0].Por)
+	})
+
+	t.Run("Recusa do motorista fica registrada com o autor", func(t *testing.T) {
+		usarDiretorioTemporario(t, NewCorridaService(novaElegibilidadeFake()))
+		oferta := ofertarParaTodos(t, 777, "3")[0]
+
+		require.NoError(t, RecusarNotificacaoCorrida(oferta.ID, "3"))
+		recusada, _ := GetNotificacaoCorrida(oferta.ID)
+		require.Len(t, recusada.Historico, 1)
+		assert.Equal(t, "motorista:3", recusada.Historico[0].Por)
+		assert.Equal(t, models.NotificacaoRecusada, recusada.Historico[0].Para)
+	})
+}
+
+func TestListarNotificacoesPaginadas(t *testing.T) {
+	usarDiretorioTemporario(t, nil)
+	ofertarParaTodos(t, 1, "1", "2", "1", "1", "3")
+
+	filtro := FiltroNotificacoes{MotoristaID: "1"}
+	primeira, err := ListarNotificacoes(filtro, "", 2)
+	require.NoError(t, err)
+	require.Len(t, primeira.Notificacoes, 2)
+	assert.NotEmpty(t, primeira.ProximoCursor)
+
+	segunda, err := ListarNotificacoes(filtro, primeira.ProximoCursor, 2)
+	require.NoError(t, err)
+	require.Len(t, segunda.Notificacoes, 1)
+	assert.Equal(t, uint(4), segunda.Notificacoes[0].ID)
+	assert.Empty(t, segunda.ProximoCursor, "última página")
+
+	_, err = ListarNotificacoes(filtro, "nao-e-um-cursor", 2)
+	assert.ErrorIs(t, err, ErrCursorNotificacoesInvalido)
+}
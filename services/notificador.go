@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi-service/models"
+)
+
+// Erros da entrega de mensagens
+var (
+	// ErrSemContato indica que o destinatário não tem o contato exigido pelo canal; o canal é ignorado
+	ErrSemContato = errors.New("destinatário sem contato para o canal")
+	// ErrPreferenciasInvalidas indica preferências com canais desconhecidos ou horários inválidos
+	ErrPreferenciasInvalidas = errors.New("preferências de notificação inválidas")
+	// ErrDestinatarioNaoEncontrado indica preferências de um destinatário sem cadastro
+	ErrDestinatarioNaoEncontrado = errors.New("destinatário não encontrado")
+)
+
+// Notificador entrega avisos aos passageiros e motoristas
+type Notificador interface {
+	Notificar(mensagem models.Mensagem) error
+}
+
+// CanalEntrega é o adaptador de um canal de notificação (caixa de entrada, email, SMS ou push)
+type CanalEntrega interface {
+	Canal() models.CanalNotificacao
+	// Entregar envia a mensagem pelo canal. Retorna ErrSemContato se o destinatário não
+	// tem o contato do canal (ex.: SMS sem telefone).
+	Entregar(mensagem models.Mensagem) error
+}
+
+// ArmazemPreferencias guarda as preferências de um tipo de destinatário fora do roteador
+// (ex.: no perfil do motorista). Destinatários sem cadastro retornam ErrDestinatarioNaoEncontrado.
+type ArmazemPreferencias interface {
+	BuscarPreferencias(id string) (models.PreferenciasNotificacao, error)
+	SalvarPreferencias(id string, preferencias models.PreferenciasNotificacao) error
+}
+
+// RotasPadrao são os canais usados em cada evento quando o destinatário não escolheu outros
+var RotasPadrao = map[models.TipoEventoMensagem][]models.CanalNotificacao{
+	models.EventoMensagemNovaOferta:        {models.CanalPush, models.CanalInApp},
+	models.EventoMensagemCorridaAceita:     {models.CanalPush, models.CanalInApp},
+	models.EventoMensagemMotoristaChegando: {models.CanalPush, models.CanalInApp},
+	models.EventoMensagemCorridaAtrasada:   {models.CanalPush, models.CanalInApp},
+	models.EventoMensagemCorridaCancelada:  {models.CanalPush, models.CanalInApp, models.CanalSMS},
+	models.EventoMensagemCorridaConcluida:  {models.CanalInApp, models.CanalEmail},
+	models.EventoMensagemCadastro:          {models.CanalEmail, models.CanalInApp},
+}
+
+// RoteadorNotificacoes implementa Notificador escolhendo os canais de cada mensagem pelo
+// evento e pelas preferências do destinatário. Eventos sem rota usam a caixa de entrada.
+// Os canais são plugáveis: canais sem adaptador registrado são ignorados.
+type RoteadorNotificacoes struct {
+	canais       map[models.CanalNotificacao]CanalEntrega
+	rotas        map[models.TipoEventoMensagem][]models.CanalNotificacao
+	preferencias map[string]models.PreferenciasNotificacao // chave: Destinatario.Chave(); tipos sem armazém
+	armazens     map[models.TipoDestinatario]ArmazemPreferencias
+	mutex        sync.RWMutex
+	agora        func() time.Time
+}
+
+// NewRoteadorNotificacoes cria o roteador com os adaptadores informados e as rotas padrão
+func NewRoteadorNotificacoes(canais ...CanalEntrega) *RoteadorNotificacoes {
+	roteador := &RoteadorNotificacoes{
+		canais:       make(map[models.CanalNotificacao]CanalEntrega),
+		rotas:        make(map[models.TipoEventoMensagem][]models.CanalNotificacao),
+		preferencias: make(map[string]models.PreferenciasNotificacao),
+		armazens:     make(map[models.TipoDestinatario]ArmazemPreferencias),
+		agora:        time.Now,
+	}
+	for _, canal := range canais {
+		roteador.canais[canal.Canal()] = canal
+	}
+	for evento, rota := range RotasPadrao {
+		roteador.rotas[evento] = rota
+	}
+	return roteador
+}
+
+// RegistrarCanal adiciona ou substitui o adaptador de um canal
+func (r *RoteadorNotificacoes) RegistrarCanal(canal CanalEntrega) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.canais[canal.Canal()] = canal
+}
+
+// DefinirRota define os canais padrão de um evento
+func (r *RoteadorNotificacoes) DefinirRota(evento models.TipoEventoMensagem, canais ...models.CanalNotificacao) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rotas[evento] = canais
+}
+
+// RegistrarArmazemPreferencias passa a guardar as preferências do tipo de destinatário no
+// armazém informado (ex.: motoristas no próprio perfil) em vez de na memória do roteador
+func (r *RoteadorNotificacoes) RegistrarArmazemPreferencias(tipo models.TipoDestinatario, armazem ArmazemPreferencias) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.armazens[tipo] = armazem
+}
+
+// DefinirPreferencias grava as escolhas de canais do destinatário
+func (r *RoteadorNotificacoes) DefinirPreferencias(destinatario models.Destinatario, preferencias models.PreferenciasNotificacao) error {
+	if err := preferencias.Validar(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPreferenciasInvalidas, err)
+	}
+
+	r.mutex.Lock()
+	armazem, existe := r.armazens[destinatario.Tipo]
+	if !existe {
+		r.preferencias[destinatario.Chave()] = preferencias
+	}
+	r.mutex.Unlock()
+
+	if existe {
+		return armazem.SalvarPreferencias(destinatario.ID, preferencias)
+	}
+	return nil
+}
+
+// Preferencias retorna as escolhas de canais do destinatário (vazias se nunca definidas)
+func (r *RoteadorNotificacoes) Preferencias(destinatario models.Destinatario) (models.PreferenciasNotificacao, error) {
+	r.mutex.RLock()
+	armazem, existe := r.armazens[destinatario.Tipo]
+	preferencias := r.preferencias[destinatario.Chave()]
+	r.mutex.RUnlock()
+
+	// O armazém é lido fora do mutex: pode depender de arquivo ou banco
+	if existe {
+		return armazem.BuscarPreferencias(destinatario.ID)
+	}
+	return preferencias, nil
+}
+
+// CanaisPara retorna os canais pelos quais a mensagem será entregue agora
+func (r *RoteadorNotificacoes) CanaisPara(mensagem models.Mensagem) []models.CanalNotificacao {
+	preferencias, _ := r.Preferencias(mensagem.Destinatario)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.canaisPara(mensagem, preferencias, r.agora())
+}
+
+// canaisPara deve ser chamada com o mutex travado
+func (r *RoteadorNotificacoes) canaisPara(mensagem models.Mensagem, preferencias models.PreferenciasNotificacao, agora time.Time) []models.CanalNotificacao {
+	padrao, existe := r.rotas[mensagem.Evento]
+	if !existe {
+		padrao = []models.CanalNotificacao{models.CanalInApp}
+	}
+
+	// Avisos críticos vão pelos canais do evento e pelos escolhidos, ignorando o resto
+	if mensagem.Evento.Critico() {
+		canais := append([]models.CanalNotificacao(nil), padrao...)
+		for _, canal := range preferencias.PorEvento[mensagem.Evento] {
+			if !contemCanal(canais, canal) {
+				canais = append(canais, canal)
+			}
+		}
+		return canais
+	}
+
+	rota, existe := preferencias.PorEvento[mensagem.Evento]
+	if !existe {
+		rota = padrao
+	}
+	silencio := preferencias.Silencio != nil && preferencias.Silencio.Contem(agora)
+
+	var canais []models.CanalNotificacao
+	for _, canal := range rota {
+		if contemCanal(preferencias.Desativados, canal) {
+			continue
+		}
+		// No silêncio a mensagem só fica na caixa de entrada, sem push, SMS ou email
+		if silencio && canal != models.CanalInApp {
+			continue
+		}
+		canais = append(canais, canal)
+	}
+	return canais
+}
+
+func contemCanal(canais []models.CanalNotificacao, procurado models.CanalNotificacao) bool {
+	for _, canal := range canais {
+		if canal == procurado {
+			return true
+		}
+	}
+	return false
+}
+
+// Notificar entrega a mensagem por todos os canais escolhidos. Uma falha em um canal não
+// impede a entrega pelos demais; as falhas são devolvidas juntas.
+func (r *RoteadorNotificacoes) Notificar(mensagem models.Mensagem) error {
+	if mensagem.ID == "" {
+		mensagem.ID = uuid.New().String()
+	}
+	if mensagem.CriadaEm.IsZero() {
+		mensagem.CriadaEm = r.agora()
+	}
+
+	// Sem preferências legíveis (ex.: destinatário fora do cadastro) valem as rotas padrão
+	preferencias, err := r.Preferencias(mensagem.Destinatario)
+	if err != nil {
+		fmt.Printf("Notificações: preferências de %s ignoradas: %v\n", mensagem.Destinatario.Chave(), err)
+	}
+
+	r.mutex.RLock()
+	var adaptadores []CanalEntrega
+	for _, canal := range r.canaisPara(mensagem, preferencias, r.agora()) {
+		if adaptador, existe := r.canais[canal]; existe {
+			adaptadores = append(adaptadores, adaptador)
+		}
+	}
+	r.mutex.RUnlock()
+
+	// A entrega roda fora do mutex: email, SMS e push dependem de serviços externos
+	var falhas []error
+	for _, adaptador := range adaptadores {
+		err := adaptador.Entregar(mensagem)
+		if err != nil && !errors.Is(err, ErrSemContato) {
+			falhas = append(falhas, fmt.Errorf("canal %s: %w", adaptador.Canal(), err))
+		}
+	}
+	return errors.Join(falhas...)
+}
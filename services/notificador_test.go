@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// emailForaDoAr é um servidor de email que recusa todos os envios
+type emailForaDoAr struct{}
+
+func (emailForaDoAr) EnviarMensagem(email, assunto, texto string) error {
+	return errors.New("servidor fora do ar")
+}
+
+// novoNotificadorTeste liga todos os canais ao provedor local
+func novoNotificadorTeste() (*RoteadorNotificacoes, *CaixaEntrada, *ProvedorLocal) {
+	caixa := NewCaixaEntrada()
+	provedor := NewProvedorLocal()
+	roteador := NewRoteadorNotificacoes(caixa, NewCanalEmail(provedor), NewCanalSMS(provedor), NewCanalPush(provedor))
+	return roteador, caixa, provedor
+}
+
+var passageiroTeste = models.Destinatario{
+	Tipo:      models.DestinatarioPassageiro,
+	ID:        "42",
+	Email:     "ana@exemplo.com",
+	Telefone:  "81999990000",
+	TokenPush: "token-ana",
+}
+
+func TestRoteadorNotificacoes(t *testing.T) {
+	t.Run("Canais escolhidos pelo evento", func(t *testing.T) {
+		roteador, caixa, provedor := novoNotificadorTeste()
+
+		require.NoError(t, roteador.Notificar(models.Mensagem{
+			Evento:       models.EventoMensagemCorridaCancelada,
+			Destinatario: passageiroTeste,
+			Titulo:       "Corrida cancelada",
+			Texto:        "Sua corrida foi cancelada",
+		}))
+
+		mensagens := caixa.Mensagens(passageiroTeste)
+		require.Len(t, mensagens, 1)
+		assert.NotEmpty(t, mensagens[0].ID)
+		assert.False(t, mensagens[0].CriadaEm.IsZero())
+
+		var destinos []string
+		for _, envio := range provedor.Envios() {
+			destinos = append(destinos, envio.Para)
+		}
+		assert.ElementsMatch(t, []string{"token-ana", "81999990000"}, destinos)
+	})
+
+	t.Run("Preferências substituem a rota e desativam canais", func(t *testing.T) {
+		roteador, caixa, provedor := novoNotificadorTeste()
+		require.NoError(t, roteador.DefinirPreferencias(passageiroTeste, models.PreferenciasNotificacao{
+			Desativados: []models.CanalNotificacao{models.CanalPush},
+			PorEvento: map[models.TipoEventoMensagem][]models.CanalNotificacao{
+				models.EventoMensagemCorridaAceita: {models.CanalSMS, models.CanalPush},
+			},
+		}))
+
+		mensagem := models.Mensagem{Evento: models.EventoMensagemCorridaAceita, Destinatario: passageiroTeste}
+		assert.Equal(t, []models.CanalNotificacao{models.CanalSMS}, roteador.CanaisPara(mensagem))
+		require.NoError(t, roteador.Notificar(mensagem))
+		assert.Empty(t, caixa.Mensagens(passageiroTeste))
+		require.Len(t, provedor.Envios(), 1)
+
+		err := roteador.DefinirPreferencias(passageiroTeste, models.PreferenciasNotificacao{
+			Desativados: []models.CanalNotificacao{"pombo"},
+		})
+		assert.ErrorIs(t, err, ErrPreferenciasInvalidas)
+	})
+
+	t.Run("Canais sem contato são ignorados e falhas não bloqueiam os demais", func(t *testing.T) {
+		roteador, caixa, provedor := novoNotificadorTeste()
+		roteador.RegistrarCanal(NewCanalEmail(emailForaDoAr{}))
+
+		semContato := models.Destinatario{Tipo: models.DestinatarioMotorista, ID: "7"}
+		err := roteador.Notificar(models.Mensagem{Evento: models.EventoMensagemCorridaConcluida, Destinatario: semContato})
+		assert.NoError(t, err, "email sem endereço não é falha")
+		assert.Len(t, caixa.Mensagens(semContato), 1)
+
+		err = roteador.Notificar(models.Mensagem{Evento: models.EventoMensagemCorridaConcluida, Destinatario: passageiroTeste})
+		assert.ErrorContains(t, err, "canal email")
+		assert.Len(t, caixa.Mensagens(passageiroTeste), 1)
+		assert.Empty(t, provedor.Envios())
+	})
+
+	t.Run("Horário de silêncio guarda só na caixa de entrada, menos avisos críticos", func(t *testing.T) {
+		roteador, caixa, provedor := novoNotificadorTeste()
+		roteador.agora = func() time.Time { return time.Date(2025, 6, 1, 23, 30, 0, 0, time.Local) }
+		require.NoError(t, roteador.DefinirPreferencias(passageiroTeste, models.PreferenciasNotificacao{
+			Desativados: []models.CanalNotificacao{models.CanalSMS},
+			Silencio:    &models.HorarioSilencio{Inicio: "22:00", Fim: "06:00"},
+		}))
+
+		require.NoError(t, roteador.Notificar(models.Mensagem{Evento: models.EventoMensagemCorridaAceita, Destinatario: passageiroTeste}))
+		assert.Len(t, caixa.Mensagens(passageiroTeste), 1)
+		assert.Empty(t, provedor.Envios())
+
+		cancelamento := models.Mensagem{Evento: models.EventoMensagemCorridaCancelada, Destinatario: passageiroTeste}
+		assert.Equal(t, []models.CanalNotificacao{models.CanalPush, models.CanalInApp, models.CanalSMS}, roteador.CanaisPara(cancelamento))
+
+		roteador.agora = func() time.Time { return time.Date(2025, 6, 1, 6, 0, 0, 0, time.Local) }
+		assert.Equal(t, []models.CanalNotificacao{models.CanalPush, models.CanalInApp}, roteador.CanaisPara(models.Mensagem{
+			Evento: models.EventoMensagemCorridaAceita, Destinatario: passageiroTeste,
+		}), "fora do silêncio")
+
+		err := roteador.DefinirPreferencias(passageiroTeste, models.PreferenciasNotificacao{
+			Silencio: &models.HorarioSilencio{Inicio: "25:00", Fim: "06:00"},
+		})
+		assert.ErrorIs(t, err, ErrPreferenciasInvalidas)
+	})
+
+	t.Run("Preferências do motorista ficam no perfil", func(t *testing.T) {
+		original, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(t.TempDir()))
+		t.Cleanup(func() { _ = os.Chdir(original) })
+
+		repo := repositories.NewJSONMotoristaRepository()
+		require.NoError(t, repo.Criar(&models.Motorista{ID: "7", Nome: "João"}))
+		roteador, _, _ := novoNotificadorTeste()
+		roteador.RegistrarArmazemPreferencias(models.DestinatarioMotorista, NewPreferenciasNoPerfil(repo))
+
+		motorista := models.Destinatario{Tipo: models.DestinatarioMotorista, ID: "7"}
+		preferencias := models.PreferenciasNotificacao{MaxOfertasPorMinuto: 2}
+		require.NoError(t, roteador.DefinirPreferencias(motorista, preferencias))
+
+		perfil, err := repo.BuscarPorID("7")
+		require.NoError(t, err)
+		require.NotNil(t, perfil.PreferenciasNotificacao)
+		assert.Equal(t, 2, perfil.PreferenciasNotificacao.MaxOfertasPorMinuto)
+
+		lidas, err := roteador.Preferencias(motorista)
+		require.NoError(t, err)
+		assert.Equal(t, preferencias, lidas)
+
+		_, err = roteador.Preferencias(models.Destinatario{Tipo: models.DestinatarioMotorista, ID: "99"})
+		assert.ErrorIs(t, err, ErrDestinatarioNaoEncontrado)
+	})
+}
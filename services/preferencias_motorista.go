@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// tentativasPreferenciasPerfil limita as regravações quando o perfil muda durante a gravação
+const tentativasPreferenciasPerfil = 3
+
+// PreferenciasNoPerfil implementa ArmazemPreferencias guardando as preferências de notificação
+// no perfil do motorista, onde o despacho também as lê (silêncio e limite de ofertas)
+type PreferenciasNoPerfil struct {
+	repo repositories.MotoristaRepository
+}
+
+// NewPreferenciasNoPerfil cria o armazém sobre o cadastro de motoristas
+func NewPreferenciasNoPerfil(repo repositories.MotoristaRepository) *PreferenciasNoPerfil {
+	return &PreferenciasNoPerfil{repo: repo}
+}
+
+// BuscarPreferencias implementa ArmazemPreferencias
+func (p *PreferenciasNoPerfil) BuscarPreferencias(id string) (models.PreferenciasNotificacao, error) {
+	motorista, err := p.repo.BuscarPorID(models.MotoristaID(id))
+	if err != nil {
+		return models.PreferenciasNotificacao{}, fmt.Errorf("motorista %s: %w", id, ErrDestinatarioNaoEncontrado)
+	}
+	if motorista.PreferenciasNotificacao == nil {
+		return models.PreferenciasNotificacao{}, nil
+	}
+	return *motorista.PreferenciasNotificacao, nil
+}
+
+// SalvarPreferencias implementa ArmazemPreferencias. O perfil é relido e regravado se outra
+// alteração passar na frente (controle otimista do repositório).
+func (p *PreferenciasNoPerfil) SalvarPreferencias(id string, preferencias models.PreferenciasNotificacao) error {
+	for tentativa := 1; ; tentativa++ {
+		motorista, err := p.repo.BuscarPorID(models.MotoristaID(id))
+		if err != nil {
+			return fmt.Errorf("motorista %s: %w", id, ErrDestinatarioNaoEncontrado)
+		}
+
+		motorista.PreferenciasNotificacao = &preferencias
+		err = p.repo.Atualizar(motorista)
+		if !errors.Is(err, repositories.ErrVersaoConflito) || tentativa == tentativasPreferenciasPerfil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package services
+
+import "time"
+
+// relogioTeste é um relógio parado que os testes avançam à mão. Os serviços com campo
+// agora recebem relogio.Agora no lugar de time.Now.
+type relogioTeste struct {
+	agora time.Time
+}
+
+func novoRelogioTeste(inicio time.Time) *relogioTeste {
+	return &relogioTeste{agora: inicio}
+}
+
+func (r *relogioTeste) Agora() time.Time {
+	return r.agora
+}
+
+func (r *relogioTeste) Avancar(intervalo time.Duration) {
+	r.agora = r.agora.Add(intervalo)
+}
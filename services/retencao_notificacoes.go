@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// IntervaloRetencaoPadrao é a frequência padrão da aplicação da política de retenção
+const IntervaloRetencaoPadrao = 24 * time.Hour
+
+// ModoRetencao define o destino das notificações antigas
+type ModoRetencao string
+
+const (
+	// ModoRetencaoArquivar move as notificações para o arquivo morto antes de removê-las
+	ModoRetencaoArquivar ModoRetencao = "arquivar"
+	// ModoRetencaoExcluir remove as notificações sem guardar cópia
+	ModoRetencaoExcluir ModoRetencao = "excluir"
+)
+
+// ErrRetencaoInvalida indica uma aplicação da política sem prazo ou sem destino para as notificações
+var ErrRetencaoInvalida = errors.New("política de retenção inválida")
+
+// statusRetidos são os status alcançados pela retenção: apenas os finais
+// (ver models.NotificacaoStatus.Final)
+var statusRetidos = []models.NotificacaoStatus{
+	models.NotificacaoAceita,
+	models.NotificacaoRecusada,
+	models.NotificacaoExpirada,
+	models.NotificacaoCancelada,
+}
+
+// ArquivoMortoNotificacoes guarda as notificações retiradas pela retenção (ex.: repositories.ArquivoNotificacoes)
+type ArquivoMortoNotificacoes interface {
+	Anexar(notificacoes []models.NotificacaoCorrida) error
+}
+
+// ConfigRetencaoNotificacoes define por quantos dias as notificações são mantidas, o que
+// acontece com as mais antigas e de quanto em quanto tempo a política é aplicada.
+// Dias zero desativa a aplicação periódica.
+type ConfigRetencaoNotificacoes struct {
+	Dias      int
+	Modo      ModoRetencao
+	Intervalo time.Duration
+}
+
+// ConfigRetencaoNotificacoesFromEnv lê NOTIFICATION_RETENTION_DAYS, NOTIFICATION_RETENTION_MODE
+// (arquivar ou excluir) e NOTIFICATION_RETENTION_INTERVAL (formato Go: 24h)
+func ConfigRetencaoNotificacoesFromEnv() ConfigRetencaoNotificacoes {
+	config := ConfigRetencaoNotificacoes{Modo: ModoRetencaoArquivar, Intervalo: IntervaloRetencaoPadrao}
+	if dias, err := strconv.Atoi(os.Getenv("NOTIFICATION_RETENTION_DAYS")); err == nil && dias > 0 {
+		config.Dias = dias
+	}
+	if modo := ModoRetencao(os.Getenv("NOTIFICATION_RETENTION_MODE")); modo == ModoRetencaoExcluir {
+		config.Modo = modo
+	}
+	if intervalo, err := time.ParseDuration(os.Getenv("NOTIFICATION_RETENTION_INTERVAL")); err == nil && intervalo > 0 {
+		config.Intervalo = intervalo
+	}
+	return config
+}
+
+// RelatorioRetencao descreve as notificações alcançadas por uma aplicação da política. Na
+// simulação nada é alterado: o relatório mostra o que seria arquivado ou excluído.
+type RelatorioRetencao struct {
+	Simulacao      bool                             `json:"simulacao"`
+	Modo           ModoRetencao                     `json:"modo"`
+	Dias           int                              `json:"dias"`
+	CriadasAntesDe time.Time                        `json:"criadas_antes_de"`
+	Total          int                              `json:"total"`
+	PorStatus      map[models.NotificacaoStatus]int `json:"por_status"`
+	IDs            []uint                           `json:"ids"`
+}
+
+// RetencaoNotificacoes arquiva ou exclui as notificações mais antigas que o prazo de retenção.
+// Só notificações em status final são alcançadas: ofertas pendentes ficam até expirar.
+type RetencaoNotificacoes struct {
+	repo    repositories.NotificacaoRepository
+	arquivo ArquivoMortoNotificacoes
+	config  ConfigRetencaoNotificacoes
+	agora   func() time.Time
+	mutex   sync.Mutex // uma aplicação por vez
+}
+
+// NewRetencaoNotificacoes cria a política e, se houver prazo configurado, inicia a aplicação periódica
+func NewRetencaoNotificacoes(repo repositories.NotificacaoRepository, arquivo ArquivoMortoNotificacoes, config ConfigRetencaoNotificacoes) *RetencaoNotificacoes {
+	if config.Modo == "" {
+		config.Modo = ModoRetencaoArquivar
+	}
+	if config.Intervalo <= 0 {
+		config.Intervalo = IntervaloRetencaoPadrao
+	}
+	retencao := &RetencaoNotificacoes{
+		repo:    repo,
+		arquivo: arquivo,
+		config:  config,
+		agora:   time.Now,
+	}
+	if config.Dias > 0 {
+		go retencao.MonitorarRetencao()
+	}
+	return retencao
+}
+
+// NewRetencaoNotificacoesFromEnv cria a política com a configuração das variáveis de ambiente
+func NewRetencaoNotificacoesFromEnv(repo repositories.NotificacaoRepository, arquivo ArquivoMortoNotificacoes) *RetencaoNotificacoes {
+	return NewRetencaoNotificacoes(repo, arquivo, ConfigRetencaoNotificacoesFromEnv())
+}
+
+// Config retorna a configuração em uso
+func (r *RetencaoNotificacoes) Config() ConfigRetencaoNotificacoes {
+	return r.config
+}
+
+// Aplicar arquiva ou exclui, conforme o modo configurado, as notificações finalizadas criadas
+// há mais de dias dias. Dias zero usa o prazo configurado. Com simular, só gera o relatório.
+func (r *RetencaoNotificacoes) Aplicar(dias int, simular bool) (RelatorioRetencao, error) {
+	if dias == 0 {
+		dias = r.config.Dias
+	}
+	if dias <= 0 {
+		return RelatorioRetencao{}, fmt.Errorf("%w: informe por quantos dias as notificações são mantidas", ErrRetencaoInvalida)
+	}
+	if r.config.Modo == ModoRetencaoArquivar && r.arquivo == nil {
+		return RelatorioRetencao{}, fmt.Errorf("%w: modo arquivar sem arquivo morto", ErrRetencaoInvalida)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	relatorio := RelatorioRetencao{
+		Simulacao:      simular,
+		Modo:           r.config.Modo,
+		Dias:           dias,
+		CriadasAntesDe: r.agora().AddDate(0, 0, -dias),
+		PorStatus:      make(map[models.NotificacaoStatus]int),
+		IDs:            []uint{},
+	}
+	antigas, err := r.repo.Listar(repositories.FiltroNotificacoes{
+		Status:    statusRetidos,
+		CriadaAte: relatorio.CriadasAntesDe,
+	})
+	if err != nil {
+		return RelatorioRetencao{}, err
+	}
+	for _, notificacao := range antigas {
+		relatorio.PorStatus[notificacao.Status]++
+		relatorio.IDs = append(relatorio.IDs, notificacao.ID)
+	}
+	relatorio.Total = len(antigas)
+	if simular || len(antigas) == 0 {
+		return relatorio, nil
+	}
+
+	// O arquivo morto é gravado antes da remoção: uma falha na remoção só faz a próxima
+	// aplicação arquivar de novo as mesmas notificações
+	if r.config.Modo == ModoRetencaoArquivar {
+		if err := r.arquivo.Anexar(antigas); err != nil {
+			return RelatorioRetencao{}, fmt.Errorf("erro ao arquivar notificações: %w", err)
+		}
+	}
+	if _, err := r.repo.DeletarLote(relatorio.IDs); err != nil {
+		return RelatorioRetencao{}, err
+	}
+	return relatorio, nil
+}
+
+// MonitorarRetencao é um processo em background que aplica a política no intervalo configurado
+func (r *RetencaoNotificacoes) MonitorarRetencao() {
+	ticker := time.NewTicker(r.config.Intervalo)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		relatorio, err := r.Aplicar(0, false)
+		if err != nil {
+			fmt.Printf("Retenção de notificações: %v\n", err)
+			continue
+		}
+		if relatorio.Total > 0 {
+			fmt.Printf("Retenção de notificações: %d notificações anteriores a %s (%s).\n",
+				relatorio.Total, relatorio.CriadasAntesDe.Format(time.RFC3339), relatorio.Modo)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+func TestRetencaoNotificacoes(t *testing.T) {
+	agora := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	// novaRetencao cria ofertas com 60, 40 e 10 dias, além de uma pendente antiga
+	novaRetencao := func(t *testing.T, modo ModoRetencao) (*RetencaoNotificacoes, *repositories.MemoriaNotificacaoRepository, *repositories.ArquivoNotificacoes) {
+		repo := repositories.NewMemoriaNotificacaoRepository(nil)
+		for _, oferta := range []struct {
+			dias   int
+			status models.NotificacaoStatus
+		}{
+			{60, models.NotificacaoAceita},
+			{40, models.NotificacaoExpirada},
+			{10, models.NotificacaoRecusada},
+			{90, models.NotificacaoPendente},
+		} {
+			require.NoError(t, repo.Criar(&models.NotificacaoCorrida{
+				MotoristaID: "1",
+				Status:      oferta.status,
+				CreatedAt:   agora.AddDate(0, 0, -oferta.dias),
+			}))
+		}
+		arquivo := repositories.NewArquivoNotificacoes(filepath.Join(t.TempDir(), "arquivadas.json"))
+		retencao := NewRetencaoNotificacoes(repo, arquivo, ConfigRetencaoNotificacoes{Modo: modo})
+		retencao.agora = func() time.Time { return agora }
+		return retencao, repo, arquivo
+	}
+
+	t.Run("Simulação relata sem alterar nada", func(t *testing.T) {
+		retencao, repo, arquivo := novaRetencao(t, ModoRetencaoArquivar)
+
+		relatorio, err := retencao.Aplicar(30, true)
+		require.NoError(t, err)
+		assert.True(t, relatorio.Simulacao)
+		assert.Equal(t, 2, relatorio.Total)
+		assert.Equal(t, []uint{1, 2}, relatorio.IDs, "a pendente antiga não é alcançada")
+		assert.Equal(t, map[models.NotificacaoStatus]int{models.NotificacaoAceita: 1, models.NotificacaoExpirada: 1}, relatorio.PorStatus)
+
+		todas, _ := repo.ListarTodas()
+		assert.Len(t, todas, 4)
+		arquivadas, _ := arquivo.Carregar()
+		assert.Empty(t, arquivadas)
+	})
+
+	t.Run("Arquivar move as antigas para o arquivo morto", func(t *testing.T) {
+		retencao, repo, arquivo := novaRetencao(t, ModoRetencaoArquivar)
+
+		relatorio, err := retencao.Aplicar(30, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, relatorio.Total)
+
+		restantes, _ := repo.ListarTodas()
+		require.Len(t, restantes, 2)
+		assert.Equal(t, uint(3), restantes[0].ID)
+		arquivadas, _ := arquivo.Carregar()
+		assert.Len(t, arquivadas, 2)
+
+		// Nada mais a fazer na segunda aplicação
+		relatorio, err = retencao.Aplicar(30, false)
+		require.NoError(t, err)
+		assert.Zero(t, relatorio.Total)
+	})
+
+	t.Run("Excluir não guarda cópia", func(t *testing.T) {
+		retencao, repo, arquivo := novaRetencao(t, ModoRetencaoExcluir)
+
+		_, err := retencao.Aplicar(5, false)
+		require.NoError(t, err)
+		restantes, _ := repo.ListarTodas()
+		require.Len(t, restantes, 1)
+		assert.Equal(t, models.NotificacaoPendente, restantes[0].Status)
+		arquivadas, _ := arquivo.Carregar()
+		assert.Empty(t, arquivadas)
+	})
+
+	t.Run("Sem prazo a aplicação é recusada", func(t *testing.T) {
+		retencao, _, _ := novaRetencao(t, ModoRetencaoArquivar)
+		_, err := retencao.Aplicar(0, true)
+		assert.ErrorIs(t, err, ErrRetencaoInvalida)
+	})
+}
@@ -0,0 +1,57 @@
+package services
+
+import "taxi-service/models"
+
+// Tarifa é a bandeirada e o preço por km de uma categoria de veículo
+type Tarifa struct {
+	Base  float64 `json:"base"`
+	PorKm float64 `json:"porKm"`
+}
+
+// TarifasPorCategoria define o preço de cada categoria de veículo
+var TarifasPorCategoria = map[models.CategoriaVeiculo]Tarifa{
+	models.CategoriaComum:     {Base: 5.00, PorKm: 2.50},
+	models.CategoriaExecutivo: {Base: 8.00, PorKm: 3.80},
+	models.CategoriaVan:       {Base: 10.00, PorKm: 4.20},
+	models.CategoriaMoto:      {Base: 3.00, PorKm: 1.60},
+}
+
+// TarifaDa retorna a tarifa da categoria; categorias vazias usam a comum
+func TarifaDa(categoria models.CategoriaVeiculo) Tarifa {
+	if tarifa, existe := TarifasPorCategoria[categoria.OuPadrao()]; existe {
+		return tarifa
+	}
+	return TarifasPorCategoria[models.CategoriaComum]
+}
+
+// Calcular retorna o valor de um trajeto com a distância informada
+func (t Tarifa) Calcular(distanciaKm float64) float64 {
+	return arredondar(t.Base+t.PorKm*distanciaKm, 2)
+}
+
+// CalcularDinamica retorna o valor do trajeto com o multiplicador da tarifa dinâmica
+func (t Tarifa) CalcularDinamica(distanciaKm, multiplicador float64) float64 {
+	return aplicarMultiplicador(t.Base+t.PorKm*distanciaKm, multiplicador)
+}
+
+// PrecoCorrida retorna o valor cobrado pela corrida com o multiplicador da tarifa dinâmica:
+// sobre o preço informado ou, sem ele, sobre a tarifa da categoria pela distância do embarque
+// ao destino (só a bandeirada, se não há destino)
+func PrecoCorrida(corrida models.Corrida) float64 {
+	if corrida.Preco > 0 {
+		return aplicarMultiplicador(corrida.Preco, corrida.MultiplicadorDinamico)
+	}
+	distanciaKm := 0.0
+	if corrida.DestinoLat != 0 || corrida.DestinoLng != 0 {
+		distanciaKm = DistanciaKm(corrida.OrigemLat, corrida.OrigemLng, corrida.DestinoLat, corrida.DestinoLng)
+	}
+	return TarifaDa(corrida.Categoria).CalcularDinamica(distanciaKm, corrida.MultiplicadorDinamico)
+}
+
+// aplicarMultiplicador multiplica o valor; multiplicadores até 1 (ou não definidos) não o alteram
+func aplicarMultiplicador(valor, multiplicador float64) float64 {
+	if multiplicador > 1 {
+		valor *= multiplicador
+	}
+	return arredondar(valor, 2)
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Parâmetros padrão da tarifa dinâmica
+const (
+	TamanhoZonaPadraoKm              = 2.0
+	JanelaDemandaPadrao              = 10 * time.Minute
+	IntervaloAtualizacaoOfertaPadrao = 15 * time.Second
+	MultiplicadorMaximoPadrao        = 3.0
+)
+
+// CurvaDinamicaPadrao relaciona a razão demanda/oferta da zona ao multiplicador da tarifa
+var CurvaDinamicaPadrao = []PontoCurvaDinamica{
+	{Razao: 1.0, Multiplicador: 1.0},
+	{Razao: 1.5, Multiplicador: 1.2},
+	{Razao: 2.0, Multiplicador: 1.5},
+	{Razao: 3.0, Multiplicador: 2.0},
+	{Razao: 5.0, Multiplicador: 3.0},
+}
+
+// ErrCurvaDinamicaInvalida indica uma curva sem pontos ou com multiplicadores menores que 1
+var ErrCurvaDinamicaInvalida = errors.New("curva de tarifa dinâmica inválida")
+
+// PontoCurvaDinamica é um ponto da curva; entre pontos o multiplicador é interpolado
+type PontoCurvaDinamica struct {
+	Razao         float64 `json:"razao"`
+	Multiplicador float64 `json:"multiplicador"`
+}
+
+// IdentificadorZona converte uma posição no identificador da zona de preço
+type IdentificadorZona interface {
+	ZonaDe(lat, lng float64) string
+}
+
+// GradeZonas divide o mapa em zonas quadradas de tamanho fixo
+type GradeZonas struct {
+	tamanhoGraus float64
+}
+
+// NewGradeZonas cria uma grade com zonas do tamanho informado (em km de latitude)
+func NewGradeZonas(tamanhoKm float64) GradeZonas {
+	if tamanhoKm <= 0 {
+		tamanhoKm = TamanhoZonaPadraoKm
+	}
+	return GradeZonas{tamanhoGraus: tamanhoKm / kmPorGrauLatitude}
+}
+
+// ZonaDe retorna o identificador da célula da grade que contém a posição
+func (g GradeZonas) ZonaDe(lat, lng float64) string {
+	return fmt.Sprintf("grade:%d:%d", int(math.Floor(lat/g.tamanhoGraus)), int(math.Floor(lng/g.tamanhoGraus)))
+}
+
+// ContadorOferta informa quantos motoristas disponíveis há em cada zona
+type ContadorOferta interface {
+	ContarDisponiveisPorZona(zonaDe func(lat, lng float64) string) map[string]int
+}
+
+// LimiteZona é a intervenção do administrador na tarifa dinâmica de uma zona
+type LimiteZona struct {
+	MultiplicadorMaximo float64 `json:"multiplicadorMaximo"` // 1 desativa a tarifa dinâmica na zona
+}
+
+// SituacaoZona resume demanda, oferta e multiplicador atual de uma zona
+type SituacaoZona struct {
+	Zona          string      `json:"zona"`
+	Demanda       int         `json:"demanda"`
+	Oferta        int         `json:"oferta"`
+	Multiplicador float64     `json:"multiplicador"`
+	Limite        *LimiteZona `json:"limite,omitempty"`
+}
+
+// ConfigTarifaDinamica reúne a curva e os limites da tarifa dinâmica
+type ConfigTarifaDinamica struct {
+	Ativa                bool
+	Curva                []PontoCurvaDinamica
+	JanelaDemanda        time.Duration // pedidos mais antigos que a janela não contam como demanda
+	MultiplicadorMaximo  float64       // teto global, abaixo dos limites por zona
+	IntervaloAtualizacao time.Duration // período de recontagem dos motoristas por zona
+	TamanhoZonaKm        float64
+}
+
+// ConfigTarifaDinamicaFromEnv lê SURGE_ENABLED, SURGE_CURVE ("razao:multiplicador,..."),
+// SURGE_WINDOW, SURGE_MAX_MULTIPLIER, SURGE_REFRESH_INTERVAL e SURGE_ZONE_SIZE_KM;
+// valores ausentes ou inválidos usam o padrão
+func ConfigTarifaDinamicaFromEnv() ConfigTarifaDinamica {
+	config := ConfigTarifaDinamica{Ativa: true}
+
+	if ativa, err := strconv.ParseBool(os.Getenv("SURGE_ENABLED")); err == nil {
+		config.Ativa = ativa
+	}
+	if texto := os.Getenv("SURGE_CURVE"); texto != "" {
+		curva, err := ParseCurvaDinamica(texto)
+		if err != nil {
+			fmt.Printf("SURGE_CURVE ignorada: %v\n", err)
+		}
+		config.Curva = curva
+	}
+	if janela, err := time.ParseDuration(os.Getenv("SURGE_WINDOW")); err == nil {
+		config.JanelaDemanda = janela
+	}
+	if maximo, err := strconv.ParseFloat(os.Getenv("SURGE_MAX_MULTIPLIER"), 64); err == nil {
+		config.MultiplicadorMaximo = maximo
+	}
+	if intervalo, err := time.ParseDuration(os.Getenv("SURGE_REFRESH_INTERVAL")); err == nil {
+		config.IntervaloAtualizacao = intervalo
+	}
+	if tamanho, err := strconv.ParseFloat(os.Getenv("SURGE_ZONE_SIZE_KM"), 64); err == nil {
+		config.TamanhoZonaKm = tamanho
+	}
+	return config
+}
+
+// ParseCurvaDinamica lê uma curva no formato "razao:multiplicador,..."
+func ParseCurvaDinamica(texto string) ([]PontoCurvaDinamica, error) {
+	var curva []PontoCurvaDinamica
+	for _, item := range strings.Split(texto, ",") {
+		razaoTexto, multiplicadorTexto, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: ponto %q", ErrCurvaDinamicaInvalida, item)
+		}
+		razao, errRazao := strconv.ParseFloat(razaoTexto, 64)
+		multiplicador, errMultiplicador := strconv.ParseFloat(multiplicadorTexto, 64)
+		if errRazao != nil || errMultiplicador != nil {
+			return nil, fmt.Errorf("%w: ponto %q", ErrCurvaDinamicaInvalida, item)
+		}
+		curva = append(curva, PontoCurvaDinamica{Razao: razao, Multiplicador: multiplicador})
+	}
+	return curva, validarCurva(curva)
+}
+
+func validarCurva(curva []PontoCurvaDinamica) error {
+	if len(curva) == 0 {
+		return ErrCurvaDinamicaInvalida
+	}
+	for _, ponto := range curva {
+		if ponto.Multiplicador < 1 || ponto.Razao < 0 {
+			return fmt.Errorf("%w: multiplicador %.2f na razão %.2f", ErrCurvaDinamicaInvalida, ponto.Multiplicador, ponto.Razao)
+		}
+	}
+	return nil
+}
+
+func (c ConfigTarifaDinamica) comPadroes() ConfigTarifaDinamica {
+	if validarCurva(c.Curva) != nil {
+		c.Curva = CurvaDinamicaPadrao
+	}
+	c.Curva = append([]PontoCurvaDinamica(nil), c.Curva...)
+	sort.Slice(c.Curva, func(a, b int) bool { return c.Curva[a].Razao < c.Curva[b].Razao })
+	if c.JanelaDemanda <= 0 {
+		c.JanelaDemanda = JanelaDemandaPadrao
+	}
+	if c.MultiplicadorMaximo < 1 {
+		c.MultiplicadorMaximo = MultiplicadorMaximoPadrao
+	}
+	if c.IntervaloAtualizacao <= 0 {
+		c.IntervaloAtualizacao = IntervaloAtualizacaoOfertaPadrao
+	}
+	return c
+}
+
+// TarifaDinamicaService mantém os contadores de demanda (pedidos de corrida) e de oferta
+// (motoristas disponíveis) por zona e calcula o multiplicador aplicado à tarifa.
+// Implementa o PrecificadorCorrida usado pelo CorridaService.
+type TarifaDinamicaService struct {
+	zonas    IdentificadorZona
+	contador ContadorOferta
+	config   ConfigTarifaDinamica
+	pedidos  map[string][]time.Time // horários dos pedidos recentes por zona
+	oferta   map[string]int
+	limites  map[string]LimiteZona
+	mutex    sync.Mutex
+	parar    chan struct{}
+	pararUma sync.Once
+	agora    func() time.Time
+}
+
+// NewTarifaDinamicaService cria o serviço e inicia a recontagem periódica da oferta
+func NewTarifaDinamicaService(zonas IdentificadorZona, contador ContadorOferta, config ConfigTarifaDinamica) *TarifaDinamicaService {
+	config = config.comPadroes()
+	if zonas == nil {
+		zonas = NewGradeZonas(config.TamanhoZonaKm)
+	}
+	service := &TarifaDinamicaService{
+		zonas:    zonas,
+		contador: contador,
+		config:   config,
+		pedidos:  make(map[string][]time.Time),
+		oferta:   make(map[string]int),
+		limites:  make(map[string]LimiteZona),
+		parar:    make(chan struct{}),
+		agora:    time.Now,
+	}
+	go service.MonitorarOferta()
+	return service
+}
+
+// NewTarifaDinamicaServiceFromEnv cria o serviço com a configuração das variáveis de ambiente
+func NewTarifaDinamicaServiceFromEnv(zonas IdentificadorZona, contador ContadorOferta) *TarifaDinamicaService {
+	return NewTarifaDinamicaService(zonas, contador, ConfigTarifaDinamicaFromEnv())
+}
+
+// Precificar registra o pedido como demanda da zona do embarque e grava na corrida a zona,
+// o multiplicador vigente e o preço com o acréscimo (PrecoCorrida)
+func (t *TarifaDinamicaService) Precificar(corrida *models.Corrida) {
+	corrida.MultiplicadorDinamico = 1
+	if !CoordenadasValidas(corrida.OrigemLat, corrida.OrigemLng) {
+		return
+	}
+	zona := t.zonas.ZonaDe(corrida.OrigemLat, corrida.OrigemLng)
+	corrida.ZonaTarifa = zona
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.pedidos[zona] = append(t.recentes(zona), t.agora())
+	corrida.MultiplicadorDinamico = t.multiplicador(zona)
+	corrida.Preco = PrecoCorrida(*corrida)
+}
+
+// Consultar retorna a situação da zona que contém a posição
+func (t *TarifaDinamicaService) Consultar(lat, lng float64) SituacaoZona {
+	return t.ConsultarZona(t.zonas.ZonaDe(lat, lng))
+}
+
+// ConsultarZona retorna demanda, oferta, multiplicador e limite atuais da zona
+func (t *TarifaDinamicaService) ConsultarZona(zona string) SituacaoZona {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.pedidos[zona] = t.recentes(zona)
+	situacao := SituacaoZona{
+		Zona:          zona,
+		Demanda:       len(t.pedidos[zona]),
+		Oferta:        t.oferta[zona],
+		Multiplicador: t.multiplicador(zona),
+	}
+	if len(t.pedidos[zona]) == 0 {
+		delete(t.pedidos, zona)
+	}
+	if limite, existe := t.limites[zona]; existe {
+		situacao.Limite = &limite
+	}
+	return situacao
+}
+
+// DefinirLimite limita o multiplicador da zona; um máximo de 1 desativa a tarifa dinâmica nela
+func (t *TarifaDinamicaService) DefinirLimite(zona string, limite LimiteZona) error {
+	if limite.MultiplicadorMaximo < 1 {
+		return fmt.Errorf("multiplicador máximo deve ser maior ou igual a 1")
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.limites[zona] = limite
+	return nil
+}
+
+// RemoverLimite volta a zona à curva e ao teto global
+func (t *TarifaDinamicaService) RemoverLimite(zona string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.limites, zona)
+}
+
+// AtualizarOferta recontabiliza os motoristas disponíveis por zona
+func (t *TarifaDinamicaService) AtualizarOferta() {
+	if t.contador == nil {
+		return
+	}
+	oferta := t.contador.ContarDisponiveisPorZona(t.zonas.ZonaDe)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.oferta = oferta
+}
+
+// MonitorarOferta é um processo em background que mantém a contagem de oferta atualizada
+// até Parar ser chamado
+func (t *TarifaDinamicaService) MonitorarOferta() {
+	ticker := time.NewTicker(t.config.IntervaloAtualizacao)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.parar:
+			return
+		case <-ticker.C:
+			t.AtualizarOferta()
+		}
+	}
+}
+
+// Parar encerra a recontagem periódica da oferta; a última contagem continua valendo
+func (t *TarifaDinamicaService) Parar() {
+	t.pararUma.Do(func() { close(t.parar) })
+}
+
+// recentes descarta os pedidos fora da janela de demanda. Deve ser chamada com o mutex travado.
+func (t *TarifaDinamicaService) recentes(zona string) []time.Time {
+	limite := t.agora().Add(-t.config.JanelaDemanda)
+	pedidos := t.pedidos[zona]
+	inicio := sort.Search(len(pedidos), func(i int) bool { return pedidos[i].After(limite) })
+	return pedidos[inicio:]
+}
+
+// multiplicador aplica a curva à razão demanda/oferta e os tetos. Deve ser chamada com o mutex travado.
+func (t *TarifaDinamicaService) multiplicador(zona string) float64 {
+	if !t.config.Ativa {
+		return 1
+	}
+
+	// Zona sem motoristas conta como um, para que poucos pedidos não disparem o teto
+	razao := float64(len(t.pedidos[zona])) / math.Max(float64(t.oferta[zona]), 1)
+	multiplicador := aplicarCurva(t.config.Curva, razao)
+
+	maximo := t.config.MultiplicadorMaximo
+	if limite, existe := t.limites[zona]; existe && limite.MultiplicadorMaximo < maximo {
+		maximo = limite.MultiplicadorMaximo
+	}
+	return arredondar(math.Max(1, math.Min(multiplicador, maximo)), 2)
+}
+
+// aplicarCurva interpola linearmente a curva ordenada por razão; fora dela vale o ponto da ponta
+func aplicarCurva(curva []PontoCurvaDinamica, razao float64) float64 {
+	if razao <= curva[0].Razao {
+		return curva[0].Multiplicador
+	}
+	for i := 1; i < len(curva); i++ {
+		if razao <= curva[i].Razao {
+			anterior, proximo := curva[i-1], curva[i]
+			proporcao := (razao - anterior.Razao) / (proximo.Razao - anterior.Razao)
+			return anterior.Multiplicador + proporcao*(proximo.Multiplicador-anterior.Multiplicador)
+		}
+	}
+	return curva[len(curva)-1].Multiplicador
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+// contadorFake devolve uma contagem fixa de motoristas por zona
+type contadorFake struct {
+	posicoes [][2]float64
+}
+
+func (c *contadorFake) ContarDisponiveisPorZona(zonaDe func(lat, lng float64) string) map[string]int {
+	contagem := make(map[string]int)
+	for _, posicao := range c.posicoes {
+		contagem[zonaDe(posicao[0], posicao[1])]++
+	}
+	return contagem
+}
+
+// pedirCorridas registra n pedidos no ponto de embarque e retorna a última corrida precificada
+func pedirCorridas(tarifa *TarifaDinamicaService, n int) models.Corrida {
+	var corrida models.Corrida
+	for i := 0; i < n; i++ {
+		corrida = models.Corrida{OrigemLat: embarqueLat, OrigemLng: embarqueLng}
+		tarifa.Precificar(&corrida)
+	}
+	return corrida
+}
+
+func TestAplicarCurva(t *testing.T) {
+	curva := ConfigTarifaDinamica{}.comPadroes().Curva
+
+	assert.Equal(t, 1.0, aplicarCurva(curva, 0.5))
+	assert.Equal(t, 1.0, aplicarCurva(curva, 1))
+	assert.InDelta(t, 1.1, aplicarCurva(curva, 1.25), 0.001)
+	assert.InDelta(t, 2.5, aplicarCurva(curva, 4), 0.001)
+	assert.Equal(t, 3.0, aplicarCurva(curva, 20))
+
+	_, err := ParseCurvaDinamica("1:1,2:0.8")
+	assert.ErrorIs(t, err, ErrCurvaDinamicaInvalida)
+	curva, err = ParseCurvaDinamica("2:1.5, 1:1")
+	require.NoError(t, err)
+	assert.Len(t, curva, 2)
+}
+
+func TestTarifaDinamica(t *testing.T) {
+	// Dois motoristas na zona do embarque e um em outra zona
+	contador := &contadorFake{posicoes: [][2]float64{
+		{embarqueLat, embarqueLng},
+		{embarqueLat + 0.001, embarqueLng},
+		{-8.2000, -34.9500},
+	}}
+
+	t.Run("Multiplicador sobe com a razão entre pedidos e motoristas da zona", func(t *testing.T) {
+		tarifa := NewTarifaDinamicaService(nil, contador, ConfigTarifaDinamica{Ativa: true})
+		t.Cleanup(tarifa.Parar)
+		tarifa.AtualizarOferta()
+
+		corrida := pedirCorridas(tarifa, 2)
+		assert.Equal(t, 1.0, corrida.MultiplicadorDinamico)
+		assert.NotEmpty(t, corrida.ZonaTarifa)
+
+		corrida = pedirCorridas(tarifa, 4) // 6 pedidos para 2 motoristas: razão 3
+		assert.Equal(t, 2.0, corrida.MultiplicadorDinamico)
+
+		situacao := tarifa.Consultar(embarqueLat, embarqueLng)
+		assert.Equal(t, 6, situacao.Demanda)
+		assert.Equal(t, 2, situacao.Oferta)
+		assert.Equal(t, corrida.ZonaTarifa, situacao.Zona)
+	})
+
+	t.Run("Pedidos fora da janela deixam de contar", func(t *testing.T) {
+		relogio := novoRelogioTeste(time.Now())
+		tarifa := NewTarifaDinamicaService(nil, contador, ConfigTarifaDinamica{Ativa: true, JanelaDemanda: 5 * time.Minute})
+		t.Cleanup(tarifa.Parar)
+		tarifa.agora = relogio.Agora
+		tarifa.AtualizarOferta()
+
+		pedirCorridas(tarifa, 6)
+		relogio.Avancar(6 * time.Minute)
+
+		corrida := pedirCorridas(tarifa, 1)
+		assert.Equal(t, 1.0, corrida.MultiplicadorDinamico)
+		assert.Equal(t, 1, tarifa.Consultar(embarqueLat, embarqueLng).Demanda)
+	})
+
+	t.Run("Administrador limita ou desativa a zona", func(t *testing.T) {
+		tarifa := NewTarifaDinamicaService(nil, contador, ConfigTarifaDinamica{Ativa: true})
+		t.Cleanup(tarifa.Parar)
+		tarifa.AtualizarOferta()
+		zona := pedirCorridas(tarifa, 10).ZonaTarifa // razão 5: teto da curva
+
+		require.NoError(t, tarifa.DefinirLimite(zona, LimiteZona{MultiplicadorMaximo: 1.8}))
+		assert.Equal(t, 1.8, pedirCorridas(tarifa, 1).MultiplicadorDinamico)
+
+		require.NoError(t, tarifa.DefinirLimite(zona, LimiteZona{MultiplicadorMaximo: 1}))
+		assert.Equal(t, 1.0, pedirCorridas(tarifa, 1).MultiplicadorDinamico)
+
+		tarifa.RemoverLimite(zona)
+		assert.Equal(t, 3.0, pedirCorridas(tarifa, 1).MultiplicadorDinamico)
+
+		assert.Error(t, tarifa.DefinirLimite(zona, LimiteZona{MultiplicadorMaximo: 0.5}))
+	})
+
+	t.Run("Teto global e tarifa dinâmica desligada", func(t *testing.T) {
+		tarifa := NewTarifaDinamicaService(nil, contador, ConfigTarifaDinamica{Ativa: true, MultiplicadorMaximo: 1.5})
+		t.Cleanup(tarifa.Parar)
+		assert.Equal(t, 1.5, pedirCorridas(tarifa, 10).MultiplicadorDinamico)
+
+		tarifa.config.Ativa = false
+		assert.Equal(t, 1.0, pedirCorridas(tarifa, 1).MultiplicadorDinamico)
+	})
+
+	t.Run("Corrida criada é cobrada com o acréscimo e a oferta mostra o mesmo valor", func(t *testing.T) {
+		tarifa := NewTarifaDinamicaService(nil, contador, ConfigTarifaDinamica{Ativa: true})
+		t.Cleanup(tarifa.Parar)
+		tarifa.AtualizarOferta()
+		pedirCorridas(tarifa, 5)
+
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.DefinirPrecificador(tarifa)
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, OrigemLat: embarqueLat, OrigemLng: embarqueLng, Preco: 20})
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, corrida.MultiplicadorDinamico) // 6 pedidos, 2 motoristas
+		assert.Equal(t, 40.0, corrida.Preco)
+		assert.Equal(t, 40.0, estimarValor(*corrida))
+
+		// Sem preço informado, a tarifa da categoria pelo trajeto recebe o acréscimo
+		corrida, err = corridas.CriarNovaCorrida(models.Corrida{
+			PassageiroID: 1,
+			OrigemLat:    embarqueLat,
+			OrigemLng:    embarqueLng,
+			DestinoLat:   embarqueLat - 0.009,
+			DestinoLng:   embarqueLng,
+		})
+		require.NoError(t, err)
+		comum := TarifaDa(models.CategoriaComum)
+		distanciaKm := DistanciaKm(embarqueLat, embarqueLng, embarqueLat-0.009, embarqueLng)
+		assert.InDelta(t, comum.Calcular(distanciaKm)*corrida.MultiplicadorDinamico, corrida.Preco, 0.01)
+		assert.Greater(t, corrida.MultiplicadorDinamico, 1.0)
+		assert.Equal(t, corrida.Preco, estimarValor(*corrida))
+	})
+}
+
+func TestContarDisponiveisPorZona(t *testing.T) {
+	registro := NewRegistroDisponibilidade(novaElegibilidadeFake(), time.Minute)
+	_, err := registro.FicarOnline("1", embarqueLat, embarqueLng)
+	require.NoError(t, err)
+	_, err = registro.FicarOnline("2", embarqueLat, embarqueLng)
+	require.NoError(t, err)
+	_, err = registro.FicarOffline("2")
+	require.NoError(t, err)
+
+	grade := NewGradeZonas(TamanhoZonaPadraoKm)
+	contagem := registro.ContarDisponiveisPorZona(grade.ZonaDe)
+	assert.Equal(t, map[string]int{grade.ZonaDe(embarqueLat, embarqueLng): 1}, contagem)
+}
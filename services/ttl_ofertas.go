@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"taxi-service/models"
+)
+
+// Limites do tempo de resposta das ofertas
+const (
+	TTLOfertaPadrao = 20 * time.Second
+	TTLOfertaMinimo = 5 * time.Second
+	TTLOfertaMaximo = 5 * time.Minute
+)
+
+// Erros da configuração do tempo de resposta das ofertas
+var (
+	ErrTTLOfertaInvalido      = fmt.Errorf("tempo de resposta da oferta deve estar entre %v e %v", TTLOfertaMinimo, TTLOfertaMaximo)
+	ErrRegraTTLOfertaInvalida = errors.New("regra de tempo de resposta da oferta inválida")
+)
+
+// FaixaHorario é o intervalo de horas [Inicio, Fim) do dia, no horário local. Faixas em
+// que Inicio > Fim atravessam a meia-noite (ex.: 22-6).
+type FaixaHorario struct {
+	Inicio int
+	Fim    int
+}
+
+// Contem indica se a hora do instante está dentro da faixa
+func (f FaixaHorario) Contem(em time.Time) bool {
+	hora := em.Hour()
+	if f.Inicio <= f.Fim {
+		return hora >= f.Inicio && hora < f.Fim
+	}
+	return hora >= f.Inicio || hora < f.Fim
+}
+
+// RegraTTLOferta define o tempo de resposta das ofertas de uma cidade, categoria e faixa de
+// horário. Campos vazios (ou Faixa nil) valem para qualquer valor.
+type RegraTTLOferta struct {
+	Cidade    string
+	Categoria models.CategoriaVeiculo
+	Faixa     *FaixaHorario
+	TTL       time.Duration
+}
+
+// especificidade conta os critérios preenchidos da regra
+func (r RegraTTLOferta) especificidade() int {
+	total := 0
+	if r.Cidade != "" {
+		total++
+	}
+	if r.Categoria != "" {
+		total++
+	}
+	if r.Faixa != nil {
+		total++
+	}
+	return total
+}
+
+// aplica indica se a regra vale para a oferta no instante informado
+func (r RegraTTLOferta) aplica(cidade string, categoria models.CategoriaVeiculo, em time.Time) bool {
+	return (r.Cidade == "" || normalizarCidade(r.Cidade) == cidade) &&
+		(r.Categoria == "" || r.Categoria == categoria) &&
+		(r.Faixa == nil || r.Faixa.Contem(em))
+}
+
+// ConfigTTLOfertas é a configuração do tempo que o motorista tem para responder uma oferta
+type ConfigTTLOfertas struct {
+	Padrao time.Duration // usado quando nenhuma regra se aplica
+	Regras []RegraTTLOferta
+}
+
+// ConfigTTLOfertasFromEnv lê OFFER_TTL e OFFER_TTL_RULES ("cidade:categoria:faixa=ttl,...");
+// valores ausentes ou inválidos usam o padrão
+func ConfigTTLOfertasFromEnv() ConfigTTLOfertas {
+	config := ConfigTTLOfertas{Padrao: TTLOfertaPadrao}
+	if ttl, err := time.ParseDuration(os.Getenv("OFFER_TTL")); err == nil {
+		if ttl >= TTLOfertaMinimo && ttl <= TTLOfertaMaximo {
+			config.Padrao = ttl
+		} else {
+			fmt.Printf("OFFER_TTL ignorado: %v\n", ErrTTLOfertaInvalido)
+		}
+	}
+	if texto := os.Getenv("OFFER_TTL_RULES"); texto != "" {
+		regras, err := ParseRegrasTTLOferta(texto)
+		if err != nil {
+			fmt.Printf("OFFER_TTL_RULES ignorada: %v\n", err)
+		}
+		config.Regras = regras
+	}
+	return config
+}
+
+// ParseRegrasTTLOferta lê regras no formato "cidade:categoria:faixa=ttl,...", em que "*"
+// vale para qualquer valor e a faixa é "inicio-fim" em horas (ex.: "recife:*:22-6=30s")
+func ParseRegrasTTLOferta(texto string) ([]RegraTTLOferta, error) {
+	var regras []RegraTTLOferta
+	for _, item := range strings.Split(texto, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		criterios, ttlTexto, ok := strings.Cut(item, "=")
+		partes := strings.Split(criterios, ":")
+		if !ok || len(partes) != 3 {
+			return nil, fmt.Errorf("%w: %q", ErrRegraTTLOfertaInvalida, item)
+		}
+
+		ttl, err := time.ParseDuration(strings.TrimSpace(ttlTexto))
+		if err != nil || ttl < TTLOfertaMinimo || ttl > TTLOfertaMaximo {
+			return nil, fmt.Errorf("%w: %q: %v", ErrRegraTTLOfertaInvalida, item, ErrTTLOfertaInvalido)
+		}
+		regra := RegraTTLOferta{TTL: ttl}
+
+		if cidade := strings.TrimSpace(partes[0]); cidade != "*" {
+			regra.Cidade = cidade
+		}
+		if categoria := models.CategoriaVeiculo(strings.TrimSpace(partes[1])); categoria != "*" {
+			if !categoria.Valida() {
+				return nil, fmt.Errorf("%w: categoria %q", ErrRegraTTLOfertaInvalida, categoria)
+			}
+			regra.Categoria = categoria
+		}
+		if faixa := strings.TrimSpace(partes[2]); faixa != "*" {
+			inicioTexto, fimTexto, _ := strings.Cut(faixa, "-")
+			inicio, errInicio := strconv.Atoi(inicioTexto)
+			fim, errFim := strconv.Atoi(fimTexto)
+			if errInicio != nil || errFim != nil || inicio < 0 || inicio > 23 || fim < 0 || fim > 24 || inicio == fim {
+				return nil, fmt.Errorf("%w: faixa %q", ErrRegraTTLOfertaInvalida, faixa)
+			}
+			regra.Faixa = &FaixaHorario{Inicio: inicio, Fim: fim}
+		}
+		regras = append(regras, regra)
+	}
+	return regras, nil
+}
+
+// TTLPara resolve o tempo de resposta de uma oferta da cidade e categoria criada no instante
+// informado. Vale a regra que se aplica com mais critérios preenchidos; no empate, a primeira.
+func (c ConfigTTLOfertas) TTLPara(cidade string, categoria models.CategoriaVeiculo, em time.Time) time.Duration {
+	cidade = normalizarCidade(cidade)
+	categoria = categoria.OuPadrao()
+
+	ttl := c.Padrao
+	if ttl <= 0 {
+		ttl = TTLOfertaPadrao
+	}
+	melhor := -1
+	for _, regra := range c.Regras {
+		if regra.aplica(cidade, categoria, em) && regra.especificidade() > melhor {
+			ttl = regra.TTL
+			melhor = regra.especificidade()
+		}
+	}
+	return ttl
+}
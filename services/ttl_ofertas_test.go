@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+)
+
+func TestConfigTTLOfertas(t *testing.T) {
+	regras, err := ParseRegrasTTLOferta("recife:*:*=30s, recife:executivo:*=45s, *:*:22-6=40s, recife:executivo:22-6=60s")
+	require.NoError(t, err)
+	config := ConfigTTLOfertas{Padrao: 20 * time.Second, Regras: regras}
+
+	dia := time.Date(2025, 6, 1, 14, 0, 0, 0, time.Local)
+	madrugada := time.Date(2025, 6, 1, 3, 0, 0, 0, time.Local)
+
+	testes := []struct {
+		nome      string
+		cidade    string
+		categoria models.CategoriaVeiculo
+		em        time.Time
+		esperado  time.Duration
+	}{
+		{"Sem regra aplicável vale o padrão", "Olinda", models.CategoriaComum, dia, 20 * time.Second},
+		{"Regra da cidade, sem diferenciar maiúsculas", " RECIFE ", "", dia, 30 * time.Second},
+		{"Cidade e categoria vencem só a cidade", "Recife", models.CategoriaExecutivo, dia, 45 * time.Second},
+		{"Faixa que atravessa a meia-noite", "Olinda", models.CategoriaMoto, madrugada, 40 * time.Second},
+		{"Regra mais específica vence", "Recife", models.CategoriaExecutivo, madrugada, 60 * time.Second},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			assert.Equal(t, tt.esperado, config.TTLPara(tt.cidade, tt.categoria, tt.em))
+		})
+	}
+
+	t.Run("Regras inválidas são rejeitadas", func(t *testing.T) {
+		for _, texto := range []string{"recife:*=30s", "recife:*:*=1s", "recife:limusine:*=30s", "*:*:25-3=30s", "*:*:*"} {
+			_, err := ParseRegrasTTLOferta(texto)
+			assert.ErrorIs(t, err, ErrRegraTTLOfertaInvalida, texto)
+		}
+	})
+}
+
+func TestTTLDasNovasOfertas(t *testing.T) {
+	usarDiretorioTemporario(t, nil)
+	DefinirConfigTTLOfertas(ConfigTTLOfertas{Padrao: 15 * time.Second, Regras: []RegraTTLOferta{
+		{Categoria: models.CategoriaVan, TTL: 25 * time.Second},
+	}})
+	t.Cleanup(func() { DefinirConfigTTLOfertas(ConfigTTLOfertas{Padrao: TTLOfertaPadrao}) })
+
+	t.Run("Oferta traz o TTL efetivo e o prazo correspondente", func(t *testing.T) {
+		oferta := models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 1, Categoria: models.CategoriaVan}
+		require.NoError(t, CreateNotificacaoCorrida(&oferta))
+		assert.Equal(t, 25, oferta.TTLSegundos)
+		assert.Equal(t, 25*time.Second, oferta.ExpiraEm.Sub(oferta.CreatedAt))
+	})
+
+	t.Run("TTL informado na criação substitui a configuração", func(t *testing.T) {
+		oferta := models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 2, TTLSegundos: 90}
+		require.NoError(t, CreateNotificacaoCorrida(&oferta))
+		assert.Equal(t, 90, oferta.TTLSegundos)
+		assert.Equal(t, 90*time.Second, oferta.ExpiraEm.Sub(oferta.CreatedAt))
+
+		fora := models.NotificacaoCorrida{MotoristaID: "1", CorridaID: 3, TTLSegundos: 1}
+		assert.ErrorIs(t, CreateNotificacaoCorrida(&fora), ErrTTLOfertaInvalido)
+	})
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// Parâmetros padrão das entregas de webhook
+const (
+	TentativasWebhookPadrao       = 5
+	IntervaloWebhookInicialPadrao = time.Second // dobra a cada tentativa
+	IntervaloWebhookMaximoPadrao  = 5 * time.Minute
+	TimeoutWebhookPadrao          = 10 * time.Second
+)
+
+// Cabeçalhos enviados em cada entrega. A assinatura é o HMAC-SHA256, em hexadecimal, de
+// "<timestamp>.<corpo>" com o segredo da assinatura (ver AssinarWebhook).
+const (
+	CabecalhoWebhookEvento     = "X-Webhook-Evento"
+	CabecalhoWebhookEntrega    = "X-Webhook-Entrega"
+	CabecalhoWebhookTimestamp  = "X-Webhook-Timestamp"
+	CabecalhoWebhookAssinatura = "X-Webhook-Assinatura"
+)
+
+// Erros dos webhooks
+var (
+	ErrAssinaturaWebhookNaoEncontrada = repositories.ErrAssinaturaWebhookNaoEncontrada
+	ErrFalhaWebhookNaoEncontrada      = repositories.ErrFalhaWebhookNaoEncontrada
+	ErrAssinaturaWebhookInvalida      = errors.New("assinatura de webhook inválida")
+	ErrEntregaWebhook                 = errors.New("entrega do webhook falhou")
+)
+
+// eventosWebhookCorrida traduz os eventos das corridas para os eventos de webhook
+var eventosWebhookCorrida = map[TipoEventoCorrida]models.TipoEventoWebhook{
+	EventoCorridaCriada:     models.EventoWebhookCorridaCriada,
+	EventoCorridaAceita:     models.EventoWebhookCorridaAceita,
+	EventoMotoristaChegando: models.EventoWebhookMotoristaChegando,
+	EventoCorridaAtrasada:   models.EventoWebhookCorridaAtrasada,
+	EventoCorridaEncerrada:  models.EventoWebhookCorridaEncerrada,
+}
+
+// ConfigWebhooks define as tentativas e o intervalo exponencial entre elas
+type ConfigWebhooks struct {
+	Tentativas       int           // tentativas antes de a entrega ir para as falhas
+	IntervaloInicial time.Duration // espera após a primeira falha; dobra a cada tentativa
+	IntervaloMaximo  time.Duration
+	Timeout          time.Duration // tempo máximo de cada requisição
+}
+
+// ConfigWebhooksFromEnv lê WEBHOOK_MAX_ATTEMPTS, WEBHOOK_BACKOFF_INITIAL, WEBHOOK_BACKOFF_MAX
+// e WEBHOOK_TIMEOUT; valores ausentes ou inválidos usam o padrão
+func ConfigWebhooksFromEnv() ConfigWebhooks {
+	var config ConfigWebhooks
+	if tentativas, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil {
+		config.Tentativas = tentativas
+	}
+	if intervalo, err := time.ParseDuration(os.Getenv("WEBHOOK_BACKOFF_INITIAL")); err == nil {
+		config.IntervaloInicial = intervalo
+	}
+	if intervalo, err := time.ParseDuration(os.Getenv("WEBHOOK_BACKOFF_MAX")); err == nil {
+		config.IntervaloMaximo = intervalo
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT")); err == nil {
+		config.Timeout = timeout
+	}
+	return config
+}
+
+// comPadroes preenche os campos não configurados
+func (c ConfigWebhooks) comPadroes() ConfigWebhooks {
+	if c.Tentativas <= 0 {
+		c.Tentativas = TentativasWebhookPadrao
+	}
+	if c.IntervaloInicial <= 0 {
+		c.IntervaloInicial = IntervaloWebhookInicialPadrao
+	}
+	if c.IntervaloMaximo < c.IntervaloInicial {
+		c.IntervaloMaximo = IntervaloWebhookMaximoPadrao
+		if c.IntervaloMaximo < c.IntervaloInicial {
+			c.IntervaloMaximo = c.IntervaloInicial
+		}
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = TimeoutWebhookPadrao
+	}
+	return c
+}
+
+// intervalo é a espera após a tentativa informada (1, 2, ...): inicial × 2^(tentativa-1)
+func (c ConfigWebhooks) intervalo(tentativa int) time.Duration {
+	intervalo := c.IntervaloInicial
+	for i := 1; i < tentativa && intervalo < c.IntervaloMaximo; i++ {
+		intervalo *= 2
+	}
+	if intervalo > c.IntervaloMaximo {
+		intervalo = c.IntervaloMaximo
+	}
+	return intervalo
+}
+
+// corpoWebhook é o JSON enviado a cada entrega
+type corpoWebhook struct {
+	ID       string                   `json:"id"`
+	Evento   models.TipoEventoWebhook `json:"evento"`
+	CriadoEm time.Time                `json:"criado_em"`
+	Dados    interface{}              `json:"dados"`
+}
+
+// WebhookService envia os eventos das corridas e dos motoristas aos parceiros inscritos.
+// Cada entrega é feita em segundo plano, com novas tentativas em intervalos exponenciais;
+// as que esgotam as tentativas vão para a lista de falhas e podem ser reenviadas à mão.
+type WebhookService struct {
+	repo     repositories.WebhookRepository
+	config   ConfigWebhooks
+	cliente  *http.Client
+	agora    func() time.Time
+	esperar  func(time.Duration)
+	entregas sync.WaitGroup
+
+	statusMotoristas map[models.MotoristaID]models.StatusMotorista // último status publicado
+	mutex            sync.Mutex
+}
+
+// NewWebhookService cria o serviço com o repositório e a configuração informados
+func NewWebhookService(repo repositories.WebhookRepository, config ConfigWebhooks) *WebhookService {
+	config = config.comPadroes()
+	return &WebhookService{
+		repo:             repo,
+		config:           config,
+		cliente:          &http.Client{Timeout: config.Timeout},
+		agora:            time.Now,
+		esperar:          time.Sleep,
+		statusMotoristas: make(map[models.MotoristaID]models.StatusMotorista),
+	}
+}
+
+// NewWebhookServiceFromEnv cria o serviço lendo a configuração das variáveis de ambiente
+func NewWebhookServiceFromEnv(repo repositories.WebhookRepository) *WebhookService {
+	return NewWebhookService(repo, ConfigWebhooksFromEnv())
+}
+
+// AssinarWebhook calcula a assinatura enviada no cabeçalho X-Webhook-Assinatura. Os parceiros
+// repetem o cálculo com o seu segredo para conferir a origem e a integridade da entrega.
+func AssinarWebhook(segredo string, timestamp int64, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CriarAssinatura valida e grava uma nova assinatura. Sem segredo informado, um é gerado;
+// a assinatura retornada é a única resposta que inclui o segredo.
+func (s *WebhookService) CriarAssinatura(assinatura models.AssinaturaWebhook) (models.AssinaturaWebhook, error) {
+	if err := assinatura.Validar(); err != nil {
+		return models.AssinaturaWebhook{}, fmt.Errorf("%w: %v", ErrAssinaturaWebhookInvalida, err)
+	}
+	if assinatura.Segredo == "" {
+		segredo, err := gerarSegredoWebhook()
+		if err != nil {
+			return models.AssinaturaWebhook{}, err
+		}
+		assinatura.Segredo = segredo
+	}
+	assinatura.ID = uuid.New().String()
+	assinatura.CriadaEm = s.agora()
+
+	if err := s.repo.CriarAssinatura(assinatura); err != nil {
+		return models.AssinaturaWebhook{}, err
+	}
+	return assinatura, nil
+}
+
+// AtualizarAssinatura troca a URL, os eventos e a situação da assinatura; o segredo só
+// muda se um novo for informado
+func (s *WebhookService) AtualizarAssinatura(id string, dados models.AssinaturaWebhook) (models.AssinaturaWebhook, error) {
+	assinatura, err := s.repo.BuscarAssinatura(id)
+	if err != nil {
+		return models.AssinaturaWebhook{}, err
+	}
+	if err := dados.Validar(); err != nil {
+		return models.AssinaturaWebhook{}, fmt.Errorf("%w: %v", ErrAssinaturaWebhookInvalida, err)
+	}
+
+	assinatura.URL = dados.URL
+	assinatura.Eventos = dados.Eventos
+	assinatura.Ativa = dados.Ativa
+	if dados.Segredo != "" {
+		assinatura.Segredo = dados.Segredo
+	}
+	if err := s.repo.AtualizarAssinatura(assinatura); err != nil {
+		return models.AssinaturaWebhook{}, err
+	}
+	return assinatura, nil
+}
+
+// BuscarAssinatura busca uma assinatura por ID
+func (s *WebhookService) BuscarAssinatura(id string) (models.AssinaturaWebhook, error) {
+	return s.repo.BuscarAssinatura(id)
+}
+
+// ListarAssinaturas retorna todas as assinaturas
+func (s *WebhookService) ListarAssinaturas() ([]models.AssinaturaWebhook, error) {
+	return s.repo.ListarAssinaturas()
+}
+
+// DeletarAssinatura remove uma assinatura; entregas em andamento para ela são descartadas
+func (s *WebhookService) DeletarAssinatura(id string) error {
+	return s.repo.DeletarAssinatura(id)
+}
+
+// OuvirCorridas é o OuvinteCorrida que publica os eventos das corridas
+func (s *WebhookService) OuvirCorridas(evento EventoCorrida) {
+	tipo, existe := eventosWebhookCorrida[evento.Tipo]
+	if !existe {
+		return
+	}
+	s.Publicar(tipo, evento.Corrida)
+}
+
+// OuvirDisponibilidade é o OuvinteDisponibilidade que publica as mudanças de status dos
+// motoristas. Heartbeats que só atualizam a posição não geram entregas.
+func (s *WebhookService) OuvirDisponibilidade(disponibilidade models.DisponibilidadeMotorista) {
+	s.mutex.Lock()
+	anterior, conhecido := s.statusMotoristas[disponibilidade.MotoristaID]
+	s.statusMotoristas[disponibilidade.MotoristaID] = disponibilidade.Status
+	s.mutex.Unlock()
+
+	if conhecido && anterior == disponibilidade.Status {
+		return
+	}
+	s.Publicar(models.EventoWebhookMotoristaStatus, disponibilidade)
+}
+
+// Publicar agenda a entrega do evento a todas as assinaturas ativas inscritas nele
+func (s *WebhookService) Publicar(evento models.TipoEventoWebhook, dados interface{}) {
+	assinaturas, err := s.repo.ListarAssinaturas()
+	if err != nil {
+		fmt.Printf("Webhooks: erro ao listar assinaturas para %s: %v\n", evento, err)
+		return
+	}
+
+	for _, assinatura := range assinaturas {
+		if !assinatura.Recebe(evento) {
+			continue
+		}
+		entrega := models.EntregaWebhook{
+			ID:           uuid.New().String(),
+			AssinaturaID: assinatura.ID,
+			URL:          assinatura.URL,
+			Evento:       evento,
+			CriadaEm:     s.agora(),
+		}
+		corpo, err := json.Marshal(corpoWebhook{ID: entrega.ID, Evento: evento, CriadoEm: entrega.CriadaEm, Dados: dados})
+		if err != nil {
+			fmt.Printf("Webhooks: erro ao serializar %s: %v\n", evento, err)
+			return
+		}
+		entrega.Corpo = corpo
+
+		s.entregas.Add(1)
+		go func() {
+			defer s.entregas.Done()
+			s.entregarComTentativas(entrega)
+		}()
+	}
+}
+
+// Aguardar espera as entregas em segundo plano terminarem (usado no encerramento e nos testes)
+func (s *WebhookService) Aguardar() {
+	s.entregas.Wait()
+}
+
+// entregarComTentativas tenta a entrega até a configuração permitir e, se todas falharem,
+// grava a entrega na lista de falhas
+func (s *WebhookService) entregarComTentativas(entrega models.EntregaWebhook) {
+	for {
+		err := s.tentar(&entrega)
+		if err == nil || errors.Is(err, ErrAssinaturaWebhookNaoEncontrada) {
+			return
+		}
+		if entrega.Tentativas >= s.config.Tentativas {
+			break
+		}
+		s.esperar(s.config.intervalo(entrega.Tentativas))
+	}
+
+	fmt.Printf("Webhooks: entrega %s (%s) para %s falhou %d vezes: %s\n",
+		entrega.ID, entrega.Evento, entrega.URL, entrega.Tentativas, entrega.UltimoErro)
+	if err := s.repo.SalvarFalha(entrega); err != nil {
+		fmt.Printf("Webhooks: erro ao gravar a falha da entrega %s: %v\n", entrega.ID, err)
+	}
+}
+
+// tentar faz uma tentativa de entrega, registrando-a na entrega. Usa a URL e o segredo
+// atuais da assinatura; assinaturas removidas retornam ErrAssinaturaWebhookNaoEncontrada.
+func (s *WebhookService) tentar(entrega *models.EntregaWebhook) error {
+	assinatura, err := s.repo.BuscarAssinatura(entrega.AssinaturaID)
+	if err != nil {
+		return err
+	}
+
+	agora := s.agora()
+	entrega.Tentativas++
+	entrega.UltimaTentativaEm = &agora
+	entrega.URL = assinatura.URL
+
+	err = s.enviar(assinatura, *entrega, agora)
+	if err != nil {
+		entrega.UltimoErro = err.Error()
+		return err
+	}
+	entrega.UltimoErro = ""
+	return nil
+}
+
+// enviar faz o POST assinado da entrega; respostas fora da faixa 2xx são falhas
+func (s *WebhookService) enviar(assinatura models.AssinaturaWebhook, entrega models.EntregaWebhook, agora time.Time) error {
+	req, err := http.NewRequest(http.MethodPost, assinatura.URL, bytes.NewReader(entrega.Corpo))
+	if err != nil {
+		return err
+	}
+	timestamp := agora.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CabecalhoWebhookEvento, string(entrega.Evento))
+	req.Header.Set(CabecalhoWebhookEntrega, entrega.ID)
+	req.Header.Set(CabecalhoWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(CabecalhoWebhookAssinatura, AssinarWebhook(assinatura.Segredo, timestamp, entrega.Corpo))
+
+	resp, err := s.cliente.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("resposta HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListarFalhas retorna as entregas que esgotaram as tentativas
+func (s *WebhookService) ListarFalhas() ([]models.EntregaWebhook, error) {
+	return s.repo.ListarFalhas()
+}
+
+// ReenviarFalha faz uma nova tentativa imediata de uma entrega com falha. Se der certo, a
+// entrega sai da lista; se não, continua nela com a tentativa registrada e o erro é
+// retornado junto de ErrEntregaWebhook.
+func (s *WebhookService) ReenviarFalha(id string) (models.EntregaWebhook, error) {
+	entrega, err := s.repo.BuscarFalha(id)
+	if err != nil {
+		return models.EntregaWebhook{}, err
+	}
+
+	if err := s.tentar(&entrega); err != nil {
+		if errors.Is(err, ErrAssinaturaWebhookNaoEncontrada) {
+			return entrega, err
+		}
+		if errSalvar := s.repo.SalvarFalha(entrega); errSalvar != nil {
+			return entrega, errSalvar
+		}
+		return entrega, fmt.Errorf("%w: %v", ErrEntregaWebhook, err)
+	}
+	if err := s.repo.RemoverFalha(entrega.ID); err != nil {
+		return entrega, err
+	}
+	return entrega, nil
+}
+
+// gerarSegredoWebhook cria um segredo aleatório de 32 bytes em hexadecimal
+func gerarSegredoWebhook() (string, error) {
+	segredo := make([]byte, 32)
+	if _, err := rand.Read(segredo); err != nil {
+		return "", fmt.Errorf("erro ao gerar o segredo do webhook: %w", err)
+	}
+	return hex.EncodeToString(segredo), nil
+}
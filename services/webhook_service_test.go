@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// receptorWebhook é um parceiro local que confere as assinaturas e responde com o status definido
+type receptorWebhook struct {
+	servidor *httptest.Server
+	segredo  string
+	status   int
+	entregas []map[string]interface{}
+	invalida int // entregas com assinatura que não confere
+	mutex    sync.Mutex
+}
+
+func novoReceptorWebhook(t *testing.T, segredo string) *receptorWebhook {
+	receptor := &receptorWebhook{segredo: segredo, status: http.StatusOK}
+	receptor.servidor = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		corpo, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(CabecalhoWebhookTimestamp), 10, 64)
+
+		receptor.mutex.Lock()
+		defer receptor.mutex.Unlock()
+		if r.Header.Get(CabecalhoWebhookAssinatura) != AssinarWebhook(receptor.segredo, timestamp, corpo) {
+			receptor.invalida++
+		}
+		var entrega map[string]interface{}
+		json.Unmarshal(corpo, &entrega)
+		receptor.entregas = append(receptor.entregas, entrega)
+		w.WriteHeader(receptor.status)
+	}))
+	t.Cleanup(receptor.servidor.Close)
+	return receptor
+}
+
+func (r *receptorWebhook) responder(status int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status = status
+}
+
+func (r *receptorWebhook) recebidas() ([]map[string]interface{}, int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]map[string]interface{}(nil), r.entregas...), r.invalida
+}
+
+// novoWebhookServiceTeste grava as assinaturas em um diretório temporário e registra as esperas
+// entre as tentativas em vez de dormir
+func novoWebhookServiceTeste(t *testing.T) (*WebhookService, *[]time.Duration) {
+	t.Helper()
+	original, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(original) })
+
+	service := NewWebhookService(repositories.NewJSONWebhookRepository(), ConfigWebhooks{
+		Tentativas:       3,
+		IntervaloInicial: 10 * time.Millisecond,
+		IntervaloMaximo:  time.Second,
+	})
+	var esperas []time.Duration
+	service.esperar = func(intervalo time.Duration) { esperas = append(esperas, intervalo) }
+	return service, &esperas
+}
+
+func TestWebhookService(t *testing.T) {
+	t.Run("Entrega assinada apenas aos inscritos no evento", func(t *testing.T) {
+		service, _ := novoWebhookServiceTeste(t)
+		hotel := novoReceptorWebhook(t, "segredo-hotel")
+		bi := novoReceptorWebhook(t, "")
+
+		_, err := service.CriarAssinatura(models.AssinaturaWebhook{
+			URL:     hotel.servidor.URL,
+			Eventos: []models.TipoEventoWebhook{models.EventoWebhookCorridaAceita},
+			Segredo: "segredo-hotel",
+			Ativa:   true,
+		})
+		require.NoError(t, err)
+		assinaturaBI, err := service.CriarAssinatura(models.AssinaturaWebhook{
+			URL:     bi.servidor.URL,
+			Eventos: []models.TipoEventoWebhook{models.EventoWebhookTodos},
+			Ativa:   true,
+		})
+		require.NoError(t, err)
+		require.Len(t, assinaturaBI.Segredo, 64, "segredo gerado quando não informado")
+		bi.segredo = assinaturaBI.Segredo
+
+		service.OuvirCorridas(EventoCorrida{Tipo: EventoCorridaCriada, Corrida: models.Corrida{ID: 1}})
+		service.OuvirCorridas(EventoCorrida{Tipo: EventoCorridaAceita, Corrida: models.Corrida{ID: 1, MotoristaID: "7"}})
+		service.Aguardar()
+
+		entregasHotel, invalidas := hotel.recebidas()
+		require.Len(t, entregasHotel, 1)
+		assert.Zero(t, invalidas)
+		assert.Equal(t, "corrida.aceita", entregasHotel[0]["evento"])
+		assert.Equal(t, "7", entregasHotel[0]["dados"].(map[string]interface{})["motoristaID"])
+
+		entregasBI, invalidas := bi.recebidas()
+		assert.Len(t, entregasBI, 2)
+		assert.Zero(t, invalidas)
+	})
+
+	t.Run("Heartbeats sem mudança de status não são entregues", func(t *testing.T) {
+		service, _ := novoWebhookServiceTeste(t)
+		bi := novoReceptorWebhook(t, "s")
+		_, err := service.CriarAssinatura(models.AssinaturaWebhook{
+			URL:     bi.servidor.URL,
+			Eventos: []models.TipoEventoWebhook{models.EventoWebhookMotoristaStatus},
+			Segredo: "s",
+			Ativa:   true,
+		})
+		require.NoError(t, err)
+
+		for _, status := range []models.StatusMotorista{models.StatusDisponivel, models.StatusDisponivel, models.StatusOcupado} {
+			service.OuvirDisponibilidade(models.DisponibilidadeMotorista{MotoristaID: "7", Status: status})
+		}
+		service.Aguardar()
+
+		entregas, _ := bi.recebidas()
+		assert.Len(t, entregas, 2)
+	})
+
+	t.Run("Falhas repetidas usam intervalo exponencial e vão para a lista de falhas", func(t *testing.T) {
+		service, esperas := novoWebhookServiceTeste(t)
+		parceiro := novoReceptorWebhook(t, "s")
+		parceiro.responder(http.StatusInternalServerError)
+		_, err := service.CriarAssinatura(models.AssinaturaWebhook{
+			URL:     parceiro.servidor.URL,
+			Eventos: []models.TipoEventoWebhook{models.EventoWebhookCorridaEncerrada},
+			Segredo: "s",
+			Ativa:   true,
+		})
+		require.NoError(t, err)
+
+		service.OuvirCorridas(EventoCorrida{Tipo: EventoCorridaEncerrada, Corrida: models.Corrida{ID: 3}})
+		service.Aguardar()
+
+		entregas, _ := parceiro.recebidas()
+		assert.Len(t, entregas, 3)
+		assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *esperas)
+
+		falhas, err := service.ListarFalhas()
+		require.NoError(t, err)
+		require.Len(t, falhas, 1)
+		assert.Equal(t, 3, falhas[0].Tentativas)
+		assert.Equal(t, "resposta HTTP 500", falhas[0].UltimoErro)
+
+		// Reenvio manual enquanto o parceiro continua fora do ar: a entrega continua na lista
+		_, err = service.ReenviarFalha(falhas[0].ID)
+		assert.ErrorIs(t, err, ErrEntregaWebhook)
+		falhas, _ = service.ListarFalhas()
+		require.Len(t, falhas, 1)
+		assert.Equal(t, 4, falhas[0].Tentativas)
+
+		parceiro.responder(http.StatusNoContent)
+		entrega, err := service.ReenviarFalha(falhas[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, entrega.Tentativas)
+		falhas, _ = service.ListarFalhas()
+		assert.Empty(t, falhas)
+
+		entregas, invalidas := parceiro.recebidas()
+		assert.Len(t, entregas, 5)
+		assert.Zero(t, invalidas)
+		assert.Equal(t, entregas[0]["id"], entregas[4]["id"], "o reenvio repete a mesma entrega")
+	})
+
+	t.Run("Assinatura inválida é recusada", func(t *testing.T) {
+		service, _ := novoWebhookServiceTeste(t)
+
+		_, err := service.CriarAssinatura(models.AssinaturaWebhook{URL: "ftp://parceiro", Eventos: []models.TipoEventoWebhook{models.EventoWebhookTodos}})
+		assert.ErrorIs(t, err, ErrAssinaturaWebhookInvalida)
+		_, err = service.CriarAssinatura(models.AssinaturaWebhook{URL: "https://parceiro.com", Eventos: []models.TipoEventoWebhook{"corrida.voou"}})
+		assert.ErrorIs(t, err, ErrAssinaturaWebhookInvalida)
+	})
+}
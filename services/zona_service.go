@@ -0,0 +1,361 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// Erros das zonas e da validação do ponto de embarque
+var (
+	ErrZonaNaoEncontrada      = repositories.ErrZonaNaoEncontrada
+	ErrZonaInvalida           = errors.New("zona inválida")
+	ErrGeoJSONInvalido        = errors.New("GeoJSON inválido")
+	ErrEmbarqueForaDaArea     = errors.New("o ponto de embarque está fora da área de atendimento")
+	ErrEmbarqueAreaRestrita   = errors.New("o ponto de embarque está em uma área restrita")
+	ErrEmbarqueSemCoordenadas = errors.New("o ponto de embarque deve ter latitude e longitude válidas")
+)
+
+// ZonaService mantém as zonas geográficas e um índice em memória das zonas ativas,
+// reconstruído a cada alteração, para responder consultas de posição sem ler o arquivo.
+// Implementa ValidadorEmbarque para o CorridaService.
+type ZonaService struct {
+	repo   repositories.ZonaRepository
+	indice *indiceZonas
+	mutex  sync.RWMutex
+	agora  func() time.Time
+}
+
+// NewZonaService cria o serviço e indexa as zonas já cadastradas
+func NewZonaService(repo repositories.ZonaRepository) *ZonaService {
+	service := &ZonaService{
+		repo:   repo,
+		indice: novoIndiceZonas(nil),
+		agora:  time.Now,
+	}
+	if err := service.reindexar(); err != nil {
+		log.Printf("Erro ao carregar as zonas: %v", err)
+	}
+	return service
+}
+
+// NewZonaServiceFromEnv cria o serviço e, se ainda não houver zonas cadastradas, importa
+// o arquivo GeoJSON indicado em SERVICE_AREAS_GEOJSON
+func NewZonaServiceFromEnv(repo repositories.ZonaRepository) *ZonaService {
+	service := NewZonaService(repo)
+
+	caminho := os.Getenv("SERVICE_AREAS_GEOJSON")
+	if caminho == "" {
+		return service
+	}
+	if zonas, err := repo.ListarTodas(); err != nil || len(zonas) > 0 {
+		return service
+	}
+	data, err := os.ReadFile(caminho)
+	if err != nil {
+		log.Printf("Erro ao ler %s: %v", caminho, err)
+		return service
+	}
+	importadas, err := service.ImportarGeoJSON(data)
+	if err != nil {
+		log.Printf("Erro ao importar %s: %v", caminho, err)
+		return service
+	}
+	log.Printf("%d zonas importadas de %s", len(importadas), caminho)
+	return service
+}
+
+// reindexar relê as zonas do repositório e troca o índice pelas zonas ativas
+// (com o mutex travado, para que alterações simultâneas não instalem um índice antigo)
+func (s *ZonaService) reindexar() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	zonas, err := s.repo.ListarTodas()
+	if err != nil {
+		return err
+	}
+	ativas := make([]models.Zona, 0, len(zonas))
+	for _, zona := range zonas {
+		if zona.Ativa {
+			ativas = append(ativas, *zona)
+		}
+	}
+	s.indice = novoIndiceZonas(ativas)
+	return nil
+}
+
+// ListarZonas retorna todas as zonas cadastradas, ativas ou não
+func (s *ZonaService) ListarZonas() ([]*models.Zona, error) {
+	return s.repo.ListarTodas()
+}
+
+// BuscarZona busca uma zona pelo ID
+func (s *ZonaService) BuscarZona(id string) (*models.Zona, error) {
+	return s.repo.BuscarPorID(id)
+}
+
+// CriarZona valida e cadastra uma nova zona
+func (s *ZonaService) CriarZona(zona models.Zona) (*models.Zona, error) {
+	if err := zona.Validar(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrZonaInvalida, err)
+	}
+
+	agora := s.agora()
+	zona.ID = uuid.New().String()
+	zona.CriadoEm = agora
+	zona.AtualizadoEm = agora
+	zona.Versao = 1
+
+	if err := s.repo.Criar(&zona); err != nil {
+		return nil, err
+	}
+	if err := s.reindexar(); err != nil {
+		return nil, err
+	}
+	return &zona, nil
+}
+
+// AtualizarZona substitui nome, tipo, geometria e situação de uma zona.
+// versaoEsperada igual a 0 dispensa a verificação de versão.
+func (s *ZonaService) AtualizarZona(id string, alteracao models.Zona, versaoEsperada int) (*models.Zona, error) {
+	if err := alteracao.Validar(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrZonaInvalida, err)
+	}
+
+	atual, err := s.repo.BuscarPorID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarVersao(atual.Versao, versaoEsperada); err != nil {
+		return nil, err
+	}
+
+	zona := *atual
+	zona.Nome = alteracao.Nome
+	zona.Tipo = alteracao.Tipo
+	zona.Geometria = alteracao.Geometria
+	zona.Ativa = alteracao.Ativa
+	zona.AtualizadoEm = s.agora()
+
+	if err := s.repo.Atualizar(&zona); err != nil {
+		return nil, err
+	}
+	if err := s.reindexar(); err != nil {
+		return nil, err
+	}
+	return &zona, nil
+}
+
+// DeletarZona remove uma zona
+func (s *ZonaService) DeletarZona(id string) error {
+	if err := s.repo.Deletar(id); err != nil {
+		return err
+	}
+	return s.reindexar()
+}
+
+// ImportarGeoJSON cadastra como novas zonas as features de um FeatureCollection (ou de uma
+// Feature isolada). Cada feature deve ter geometria Polygon ou MultiPolygon e as propriedades
+// "nome" (ou "name") e "tipo"; "ativa" é opcional e vale true quando ausente.
+// Nenhuma zona é gravada se alguma feature for inválida.
+func (s *ZonaService) ImportarGeoJSON(data []byte) ([]models.Zona, error) {
+	zonas, err := ZonasDeGeoJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	agora := s.agora()
+	for i := range zonas {
+		zonas[i].ID = uuid.New().String()
+		zonas[i].CriadoEm = agora
+		zonas[i].AtualizadoEm = agora
+		zonas[i].Versao = 1
+		if err := s.repo.Criar(&zonas[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.reindexar(); err != nil {
+		return nil, err
+	}
+	return zonas, nil
+}
+
+// ExportarGeoJSON retorna todas as zonas como um FeatureCollection
+func (s *ZonaService) ExportarGeoJSON() ([]byte, error) {
+	zonas, err := s.repo.ListarTodas()
+	if err != nil {
+		return nil, err
+	}
+
+	colecao := colecaoGeoJSON{Type: "FeatureCollection", Features: make([]featureGeoJSON, 0, len(zonas))}
+	for _, zona := range zonas {
+		ativa := zona.Ativa
+		colecao.Features = append(colecao.Features, featureGeoJSON{
+			Type:     "Feature",
+			ID:       zona.ID,
+			Geometry: &zona.Geometria,
+			Properties: propriedadesZona{
+				Nome:  zona.Nome,
+				Tipo:  zona.Tipo,
+				Ativa: &ativa,
+			},
+		})
+	}
+	return json.Marshal(colecao)
+}
+
+// Localizar retorna as zonas ativas que contêm a posição
+func (s *ZonaService) Localizar(lat, lng float64) []models.Zona {
+	s.mutex.RLock()
+	indice := s.indice
+	s.mutex.RUnlock()
+
+	posicoes := indice.localizar(lat, lng)
+	zonas := make([]models.Zona, 0, len(posicoes))
+	for _, posicao := range posicoes {
+		zonas = append(zonas, indice.zonas[posicao])
+	}
+	return zonas
+}
+
+// ValidarEmbarque recusa embarques em áreas restritas e, quando há áreas de atendimento
+// ativas, embarques fora de todas elas. Sem áreas cadastradas, embarques sem coordenadas
+// continuam aceitos; com áreas, não há como conferi-los.
+func (s *ZonaService) ValidarEmbarque(lat, lng float64) error {
+	s.mutex.RLock()
+	indice := s.indice
+	s.mutex.RUnlock()
+
+	if !CoordenadasValidas(lat, lng) {
+		if indice.possuiTipo(models.ZonaAreaAtendimento) || indice.possuiTipo(models.ZonaAreaRestrita) {
+			return ErrEmbarqueSemCoordenadas
+		}
+		return nil
+	}
+
+	dentroDeArea := false
+	for _, posicao := range indice.localizar(lat, lng) {
+		switch indice.zonas[posicao].Tipo {
+		case models.ZonaAreaRestrita:
+			return fmt.Errorf("%w (%s)", ErrEmbarqueAreaRestrita, indice.zonas[posicao].Nome)
+		case models.ZonaAreaAtendimento:
+			dentroDeArea = true
+		}
+	}
+	if !dentroDeArea && indice.possuiTipo(models.ZonaAreaAtendimento) {
+		return ErrEmbarqueForaDaArea
+	}
+	return nil
+}
+
+// RegiaoPrecoDe retorna o identificador ("zona:<id>") da região de preço que contém a posição.
+// Se houver regiões sobrepostas, vale a primeira cadastrada.
+func (s *ZonaService) RegiaoPrecoDe(lat, lng float64) (string, bool) {
+	s.mutex.RLock()
+	indice := s.indice
+	s.mutex.RUnlock()
+
+	for _, posicao := range indice.localizar(lat, lng) {
+		if indice.zonas[posicao].Tipo == models.ZonaRegiaoPreco {
+			return "zona:" + indice.zonas[posicao].ID, true
+		}
+	}
+	return "", false
+}
+
+// RegioesPreco adapta o serviço para a tarifa dinâmica: posições dentro de uma região de preço
+// usam a região e as demais caem na zona do identificador reserva (normalmente a grade)
+func (s *ZonaService) RegioesPreco(reserva IdentificadorZona) IdentificadorZona {
+	return regioesPreco{zonas: s, reserva: reserva}
+}
+
+type regioesPreco struct {
+	zonas   *ZonaService
+	reserva IdentificadorZona
+}
+
+func (r regioesPreco) ZonaDe(lat, lng float64) string {
+	if regiao, ok := r.zonas.RegiaoPrecoDe(lat, lng); ok {
+		return regiao
+	}
+	return r.reserva.ZonaDe(lat, lng)
+}
+
+// Estruturas do GeoJSON (RFC 7946) usadas na importação e exportação
+type colecaoGeoJSON struct {
+	Type     string           `json:"type"`
+	Features []featureGeoJSON `json:"features"`
+}
+
+type featureGeoJSON struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id,omitempty"`
+	Geometry   *models.Geometria `json:"geometry"`
+	Properties propriedadesZona  `json:"properties"`
+}
+
+type propriedadesZona struct {
+	Nome  string          `json:"nome,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Tipo  models.TipoZona `json:"tipo"`
+	Ativa *bool           `json:"ativa,omitempty"`
+}
+
+// ZonasDeGeoJSON converte um FeatureCollection ou uma Feature em zonas validadas (sem ID)
+func ZonasDeGeoJSON(data []byte) ([]models.Zona, error) {
+	var cabecalho struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &cabecalho); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGeoJSONInvalido, err)
+	}
+
+	var features []featureGeoJSON
+	switch cabecalho.Type {
+	case "FeatureCollection":
+		var colecao colecaoGeoJSON
+		if err := json.Unmarshal(data, &colecao); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGeoJSONInvalido, err)
+		}
+		features = colecao.Features
+	case "Feature":
+		var feature featureGeoJSON
+		if err := json.Unmarshal(data, &feature); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGeoJSONInvalido, err)
+		}
+		features = []featureGeoJSON{feature}
+	default:
+		return nil, fmt.Errorf("%w: tipo %q não suportado; use FeatureCollection ou Feature", ErrGeoJSONInvalido, cabecalho.Type)
+	}
+
+	zonas := make([]models.Zona, 0, len(features))
+	for i, feature := range features {
+		if feature.Geometry == nil {
+			return nil, fmt.Errorf("%w: feature %d sem geometria", ErrGeoJSONInvalido, i)
+		}
+		zona := models.Zona{
+			Nome:      feature.Properties.Nome,
+			Tipo:      feature.Properties.Tipo,
+			Geometria: *feature.Geometry,
+			Ativa:     feature.Properties.Ativa == nil || *feature.Properties.Ativa,
+		}
+		if zona.Nome == "" {
+			zona.Nome = feature.Properties.Name
+		}
+		if err := zona.Validar(); err != nil {
+			return nil, fmt.Errorf("%w: feature %d: %v", ErrGeoJSONInvalido, i, err)
+		}
+		zonas = append(zonas, zona)
+	}
+	return zonas, nil
+}
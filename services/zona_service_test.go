@@ -0,0 +1,198 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi-service/models"
+	"taxi-service/repositories"
+)
+
+// retangulo cria um anel fechado com os cantos informados (lat/lng)
+func retangulo(latMin, lngMin, latMax, lngMax float64) []models.Coordenada {
+	return []models.Coordenada{
+		{lngMin, latMin}, {lngMax, latMin}, {lngMax, latMax}, {lngMin, latMax}, {lngMin, latMin},
+	}
+}
+
+func zonaRetangular(nome string, tipo models.TipoZona, latMin, lngMin, latMax, lngMax float64) models.Zona {
+	return models.Zona{
+		Nome:      nome,
+		Tipo:      tipo,
+		Ativa:     true,
+		Geometria: models.Geometria{Poligonos: []models.Poligono{{retangulo(latMin, lngMin, latMax, lngMax)}}},
+	}
+}
+
+// novoZonaServiceTeste grava as zonas em um diretório descartável
+func novoZonaServiceTeste(t *testing.T) *ZonaService {
+	t.Helper()
+	usarDiretorioTemporario(t, nil)
+	return NewZonaService(repositories.NewJSONZonaRepository())
+}
+
+func TestAnelContem(t *testing.T) {
+	// Polígono côncavo em forma de "L"
+	anel := []models.Coordenada{{0, 0}, {4, 0}, {4, 1}, {1, 1}, {1, 4}, {0, 4}, {0, 0}}
+
+	assert.True(t, anelContem(anel, 0.5, 3))
+	assert.True(t, anelContem(anel, 0.5, 0.5))
+	assert.False(t, anelContem(anel, 3, 3), "ponto no vão do L")
+	assert.False(t, anelContem(anel, 5, 5))
+}
+
+func TestZonaServiceLocalizar(t *testing.T) {
+	zonas := novoZonaServiceTeste(t)
+
+	comBuraco := zonaRetangular("Centro", models.ZonaAreaAtendimento, -8.10, -34.95, -8.00, -34.85)
+	comBuraco.Geometria.Poligonos[0] = append(comBuraco.Geometria.Poligonos[0], retangulo(-8.06, -34.91, -8.04, -34.89))
+	centro, err := zonas.CriarZona(comBuraco)
+	require.NoError(t, err)
+
+	assert.Len(t, zonas.Localizar(-8.02, -34.87), 1)
+	assert.Equal(t, centro.ID, zonas.Localizar(-8.02, -34.87)[0].ID)
+	assert.Empty(t, zonas.Localizar(-8.05, -34.90), "ponto dentro do buraco")
+	assert.Empty(t, zonas.Localizar(-8.20, -34.87))
+
+	t.Run("Zonas inativas não são consideradas", func(t *testing.T) {
+		inativa := *centro
+		inativa.Ativa = false
+		_, err := zonas.AtualizarZona(centro.ID, inativa, centro.Versao)
+		require.NoError(t, err)
+		assert.Empty(t, zonas.Localizar(-8.02, -34.87))
+	})
+
+	t.Run("Atualização com versão antiga é recusada", func(t *testing.T) {
+		_, err := zonas.AtualizarZona(centro.ID, *centro, centro.Versao)
+		assert.ErrorIs(t, err, ErrVersaoConflito)
+	})
+
+	t.Run("Geometria inválida é recusada", func(t *testing.T) {
+		aberta := zonaRetangular("Aberta", models.ZonaAreaAtendimento, 0, 0, 1, 1)
+		aberta.Geometria.Poligonos[0][0] = aberta.Geometria.Poligonos[0][0][:4]
+		_, err := zonas.CriarZona(aberta)
+		assert.ErrorIs(t, err, ErrZonaInvalida)
+	})
+}
+
+func TestZonaServiceValidarEmbarque(t *testing.T) {
+	zonas := novoZonaServiceTeste(t)
+
+	assert.NoError(t, zonas.ValidarEmbarque(-8.05, -34.90), "sem áreas cadastradas, qualquer ponto é atendido")
+
+	t.Run("Sem áreas cadastradas, corridas sem coordenadas são aceitas", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.DefinirValidadorEmbarque(novoZonaServiceTeste(t))
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, Origem: "Rua A, 123"})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusProcurandoMotorista, corrida.Status)
+	})
+
+	_, err := zonas.CriarZona(zonaRetangular("Recife", models.ZonaAreaAtendimento, -8.20, -35.00, -7.90, -34.80))
+	require.NoError(t, err)
+	_, err = zonas.CriarZona(zonaRetangular("Pista do aeroporto", models.ZonaAreaRestrita, -8.14, -34.93, -8.12, -34.91))
+	require.NoError(t, err)
+
+	assert.NoError(t, zonas.ValidarEmbarque(-8.05, -34.90))
+	assert.ErrorIs(t, zonas.ValidarEmbarque(-8.13, -34.92), ErrEmbarqueAreaRestrita)
+	assert.ErrorIs(t, zonas.ValidarEmbarque(-23.55, -46.63), ErrEmbarqueForaDaArea)
+
+	t.Run("CorridaService recusa embarque fora da área", func(t *testing.T) {
+		corridas := NewCorridaService(novaElegibilidadeFake())
+		corridas.DefinirValidadorEmbarque(zonas)
+
+		_, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, OrigemLat: -23.55, OrigemLng: -46.63})
+		assert.ErrorIs(t, err, ErrEmbarqueForaDaArea)
+
+		// Sem coordenadas ou com coordenadas impossíveis a área não pode ser conferida
+		_, err = corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1})
+		assert.ErrorIs(t, err, ErrEmbarqueSemCoordenadas)
+		_, err = corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, OrigemLat: 999, OrigemLng: -34.90})
+		assert.ErrorIs(t, err, ErrEmbarqueSemCoordenadas)
+
+		corrida, err := corridas.CriarNovaCorrida(models.Corrida{PassageiroID: 1, OrigemLat: -8.05, OrigemLng: -34.90})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusProcurandoMotorista, corrida.Status)
+	})
+}
+
+func TestZonaServiceRegioesPreco(t *testing.T) {
+	zonas := novoZonaServiceTeste(t)
+	boaViagem, err := zonas.CriarZona(zonaRetangular("Boa Viagem", models.ZonaRegiaoPreco, -8.15, -34.92, -8.08, -34.88))
+	require.NoError(t, err)
+
+	regioes := zonas.RegioesPreco(NewGradeZonas(2))
+	assert.Equal(t, "zona:"+boaViagem.ID, regioes.ZonaDe(-8.12, -34.90))
+	assert.Equal(t, NewGradeZonas(2).ZonaDe(-8.05, -34.87), regioes.ZonaDe(-8.05, -34.87))
+}
+
+func TestZonasDeGeoJSON(t *testing.T) {
+	geojson := []byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {"name": "Olinda", "tipo": "area_atendimento"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[-34.87,-8.03],[-34.83,-8.03],[-34.83,-7.98],[-34.87,-7.98],[-34.87,-8.03]]]}},
+			{"type": "Feature", "properties": {"nome": "Ilhas", "tipo": "regiao_preco", "ativa": false},
+			 "geometry": {"type": "MultiPolygon", "coordinates": [
+				[[[0,0],[1,0],[1,1],[0,0]]],
+				[[[2,2],[3,2],[3,3],[2,2]]]
+			 ]}}
+		]
+	}`)
+
+	zonas, err := ZonasDeGeoJSON(geojson)
+	require.NoError(t, err)
+	require.Len(t, zonas, 2)
+	assert.Equal(t, "Olinda", zonas[0].Nome)
+	assert.True(t, zonas[0].Ativa)
+	assert.False(t, zonas[1].Ativa)
+	assert.Len(t, zonas[1].Geometria.Poligonos, 2)
+
+	_, err = ZonasDeGeoJSON([]byte(`{"type": "Feature", "properties": {"nome": "X", "tipo": "outro"}, "geometry": {"type": "Point", "coordinates": [0, 0]}}`))
+	assert.ErrorIs(t, err, ErrGeoJSONInvalido)
+
+	t.Run("Importação e exportação preservam as zonas", func(t *testing.T) {
+		service := novoZonaServiceTeste(t)
+		importadas, err := service.ImportarGeoJSON(geojson)
+		require.NoError(t, err)
+		require.Len(t, importadas, 2)
+		assert.Len(t, service.Localizar(-8.00, -34.85), 1)
+
+		exportado, err := service.ExportarGeoJSON()
+		require.NoError(t, err)
+		reimportadas, err := ZonasDeGeoJSON(exportado)
+		require.NoError(t, err)
+		assert.Equal(t, importadas[1].Geometria, reimportadas[1].Geometria)
+		assert.Equal(t, importadas[1].Ativa, reimportadas[1].Ativa)
+	})
+}
+
+// BenchmarkIndiceZonasLocalizar mede a consulta feita a cada atualização de posição
+// com 500 zonas de ~40 vértices espalhadas por uma região metropolitana
+func BenchmarkIndiceZonasLocalizar(b *testing.B) {
+	var zonas []models.Zona
+	for i := 0; i < 500; i++ {
+		lat := -8.30 + float64(i%25)*0.02
+		lng := -35.10 + float64(i/25)*0.02
+		var anel []models.Coordenada
+		for v := 0; v < 40; v++ {
+			anel = append(anel, models.Coordenada{lng + 0.015*float64(v%2), lat + 0.015*float64(v)/40})
+		}
+		anel = append(anel, anel[0])
+		zonas = append(zonas, models.Zona{
+			Nome:      fmt.Sprintf("zona %d", i),
+			Tipo:      models.ZonaRegiaoPreco,
+			Geometria: models.Geometria{Poligonos: []models.Poligono{{anel}}},
+		})
+	}
+	indice := novoIndiceZonas(zonas)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		indice.localizar(-8.30+float64(i%500)*0.001, -35.10+float64(i%400)*0.001)
+	}
+}
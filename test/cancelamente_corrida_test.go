@@ -9,7 +9,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"taxi-service/middlewares"
 	"taxi-service/models"
+	"taxi-service/repositories"
 	"taxi-service/routes"
 	"taxi-service/services"
 
@@ -58,10 +60,10 @@ func InitializeScenarioCancelamentoDeCorrida(ctx *godog.ScenarioContext) {
 		tc.lastBody = make(map[string]interface{})
 
 		// Criamos instâncias novas para cada cenário para garantir o isolamento.
-		tc.service = services.NewCorridaService()
+		tc.service = services.NewCorridaService(services.NewElegibilidadeService(repositories.NewJSONMotoristaRepository()))
 		app := fiber.New()
 		// Injeta o serviço real no controller através da configuração de rotas
-		routes.SetupCorridaRoutes(app.Group("/api"), tc.service)
+		routes.SetupCorridaRoutes(app.Group("/api"), tc.service, middlewares.NewIdempotenciaStoreFromEnv())
 		tc.app = app
 
 		return ctx, nil
@@ -96,7 +98,7 @@ func (tc *TestContext) queOSistemaPossuiOsSeguintesMotoristas(table *godog.Table
 		statusStr := strings.Trim(row.Cells[3].Value, `"`)
 
 		motorista := &models.Motorista{
-			ID:     strconv.Itoa(i),
+			ID:     models.MotoristaID(strconv.Itoa(i)),
 			Nome:   nomeCompleto,
 			CPF:    cpf,
 			Status: models.StatusMotorista(statusStr),
@@ -111,7 +113,6 @@ func (tc *TestContext) queAsSeguintesCorridasExistemNoSistema(nomeReferencia str
 	if !ok {
 		return fmt.Errorf("motorista de referência '%s' não encontrado", nomeReferencia)
 	}
-	motoristaID, _ := strconv.Atoi(motorista.ID)
 
 	for i := 1; i < len(table.Rows); i++ {
 		row := table.Rows[i]
@@ -134,7 +135,7 @@ func (tc *TestContext) queAsSeguintesCorridasExistemNoSistema(nomeReferencia str
 
 		// Força o estado da corrida para corresponder exatamente ao cenário Gherkin
 		corrida.ID = id
-		corrida.MotoristaID = motoristaID
+		corrida.MotoristaID = motorista.ID
 		corrida.Status = statusInicial
 	}
 	return nil
@@ -152,7 +153,7 @@ func (tc *TestContext) aCorridaDeIdTemOStatus(idStr, statusEsperado string) erro
 }
 
 func (tc *TestContext) oMotoristaTentaCancelarACorrida(cpf, idCorridaStr string) error {
-	var motoristaID string
+	var motoristaID models.MotoristaID
 	for _, m := range tc.motoristas {
 		if m.CPF == cpf {
 			motoristaID = m.ID
@@ -74,7 +74,7 @@ func TestAvaliarCorridaHandler(t *testing.T) {
 	// (poderia ser via POST /corridas, mas aqui é direto)
 	model := models.Corrida{
 		ID:          99,
-		MotoristaID: 1,
+		MotoristaID: "1",
 		Preco:       50.0,
 		Status:      "em_andamento",
 	}
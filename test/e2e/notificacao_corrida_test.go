@@ -1,6 +1,7 @@
 package e2e
 
 import (
+    "sync"
     "testing"
     "time"
 	"fmt"
@@ -33,7 +34,7 @@ func TestListNotificacoesCorrida(t *testing.T) {
             assert.NotEmpty(t, notif.PassageiroNome, "Notificacao should have PassageiroNome")
             assert.Greater(t, notif.Valor, 0.0, "Valor should be greater than 0")
             
-            t.Logf("Notificacao[%d]: ID=%d, Motorista=%d, Status='%s', Valor=%.2f", 
+            t.Logf("Notificacao[%d]: ID=%d, Motorista=%s, Status='%s', Valor=%.2f", 
                 i, notif.ID, notif.MotoristaID, notif.Status, notif.Valor)
         }
     }
@@ -54,7 +55,7 @@ func TestCreateNotificacaoCorrida(t *testing.T) {
 
     // Create a new notificacao
     newNotificacao := models.NotificacaoCorrida{
-        MotoristaID:     999,
+        MotoristaID:     "999",
         CorridaID:       888,
         PassageiroNome:  "Test Passenger",
         Valor:           45.50,
@@ -72,7 +73,7 @@ func TestCreateNotificacaoCorrida(t *testing.T) {
     
     // Verify created notificacao
     assert.NotZero(t, createdNotificacao.ID, "Created notificacao should have an ID")
-    assert.Equal(t, uint(999), createdNotificacao.MotoristaID)
+    assert.Equal(t, models.MotoristaID("999"), createdNotificacao.MotoristaID)
     assert.Equal(t, uint(888), createdNotificacao.CorridaID)
     assert.Equal(t, "Test Passenger", createdNotificacao.PassageiroNome)
     assert.Equal(t, 45.50, createdNotificacao.Valor)
@@ -105,7 +106,7 @@ func TestGetNotificacoesPendentesParaMotorista(t *testing.T) {
     motoristaID := pendingResp["motorista_id"]
     pendingCount := pendingResp["pending_count"]
     
-    assert.Equal(t, float64(102), motoristaID)
+    assert.Equal(t, "102", motoristaID)
     assert.NotNil(t, pendingCount)
     
     t.Logf("Motorista 102 has %v pending notificacoes", pendingCount)
@@ -137,7 +138,7 @@ func TestGetHistoricoNotificacoesMotorista(t *testing.T) {
     expiradasCount := historicoResp["expiradas_count"]
     pendentesCount := historicoResp["pendentes_count"]
 
-    assert.Equal(t, float64(101), motoristaID)
+    assert.Equal(t, "101", motoristaID)
     assert.NotNil(t, totalCount)
     assert.NotNil(t, aceitasCount)
     assert.NotNil(t, recusadasCount)
@@ -154,7 +155,7 @@ func TestAceitarNotificacaoCorrida(t *testing.T) {
 
     // First create a notificacao to accept
     newNotificacao := models.NotificacaoCorrida{
-        MotoristaID:     555,
+        MotoristaID:     "555",
         CorridaID:       777,
         PassageiroNome:  "Accept Test Passenger",
         Valor:           30.00,
@@ -184,7 +185,7 @@ func TestAceitarNotificacaoCorrida(t *testing.T) {
         
         assert.Equal(t, "Notificacao accepted successfully", acceptResult["message"])
         assert.Equal(t, float64(createdNotificacao.ID), acceptResult["notificacao_id"])
-        assert.Equal(t, float64(555), acceptResult["motorista_id"])
+        assert.Equal(t, "555", acceptResult["motorista_id"])
         
         t.Logf("Successfully accepted notificacao ID: %d", createdNotificacao.ID)
     case 410:
@@ -194,13 +195,58 @@ func TestAceitarNotificacaoCorrida(t *testing.T) {
     }
 }
 
+func TestAceiteSimultaneoMesmaCorrida(t *testing.T) {
+    app := test.SetupTestApp(t)
+    defer test.CleanupTestApp(t)
+
+    // Broadcast: the same ride offered to several drivers at once
+    motoristas := []string{"601", "602", "603", "604"}
+    ids := make(map[string]uint)
+    for _, motorista := range motoristas {
+        oferta := models.NotificacaoCorrida{
+            MotoristaID:    models.MotoristaID(motorista),
+            CorridaID:      8801,
+            PassageiroNome: "Race Test Passenger",
+            Valor:          18.00,
+            Origem:         "Race Test Origin",
+            Destino:        "Race Test Destination",
+        }
+        createResp := test.MakeRequest(t, app, "POST", "/notificacoes", oferta)
+        assert.Equal(t, 201, createResp.StatusCode)
+
+        var criada models.NotificacaoCorrida
+        test.ParseResponseBody(t, createResp, &criada)
+        ids[motorista] = criada.ID
+    }
+
+    status := make(chan int, len(motoristas))
+    var wg sync.WaitGroup
+    for _, motorista := range motoristas {
+        wg.Add(1)
+        go func(motorista string) {
+            defer wg.Done()
+            acceptPath := fmt.Sprintf("/notificacoes/%d/motorista/%s/accept", ids[motorista], motorista)
+            status <- test.MakeRequest(t, app, "POST", acceptPath, nil).StatusCode
+        }(motorista)
+    }
+    wg.Wait()
+    close(status)
+
+    contagem := make(map[int]int)
+    for codigo := range status {
+        contagem[codigo]++
+    }
+    assert.Equal(t, 1, contagem[200], "only one driver may win the ride")
+    assert.Equal(t, len(motoristas)-1, contagem[409])
+}
+
 func TestRecusarNotificacaoCorrida(t *testing.T) {
     app := test.SetupTestApp(t)
     defer test.CleanupTestApp(t)
 
     // First create a notificacao to refuse
     newNotificacao := models.NotificacaoCorrida{
-        MotoristaID:     666,
+        MotoristaID:     "666",
         CorridaID:       888,
         PassageiroNome:  "Refuse Test Passenger",
         Valor:           25.00,
@@ -233,7 +279,7 @@ func TestRecusarNotificacaoCorrida(t *testing.T) {
             
             assert.Equal(t, "Notificacao refused successfully", refuseResult["message"])
             assert.Equal(t, float64(createdNotificacao.ID), refuseResult["notificacao_id"])
-            assert.Equal(t, float64(666), refuseResult["motorista_id"])
+            assert.Equal(t, "666", refuseResult["motorista_id"])
             
             t.Logf("Successfully refused notificacao ID: %d", createdNotificacao.ID)
         case 410:
@@ -250,7 +296,7 @@ func TestExpirarNotificacoesVencidas(t *testing.T) {
 
     // 1. Criar uma notificação que irá expirar
     newNotificacao := models.NotificacaoCorrida{
-        MotoristaID:     777,
+        MotoristaID:     "777",
         CorridaID:       888,
         PassageiroNome:  "Expire Test Passenger",
         Valor:           25.00,
@@ -290,7 +336,7 @@ func TestExpirarNotificacoesVencidas(t *testing.T) {
     t.Logf("Notificacao ID %d expired as expected (status: %s)", after.ID, after.Status)
 
     // 5. Verificar que ela não aparece mais na lista de notificações pendentes do motorista
-    pendingResp := test.MakeRequest(t, app, "GET", fmt.Sprintf("/notificacoes/motorista/%d/pending", newNotificacao.MotoristaID), nil)
+    pendingResp := test.MakeRequest(t, app, "GET", fmt.Sprintf("/notificacoes/motorista/%s/pending", newNotificacao.MotoristaID), nil)
     assert.Equal(t, 200, pendingResp.StatusCode)
 
     var pendingResult map[string]interface{}
@@ -318,7 +364,7 @@ func TestDeleteNotificacaoCorrida(t *testing.T) {
 
     // First create a notificacao to delete
     newNotificacao := models.NotificacaoCorrida{
-        MotoristaID:     777,
+        MotoristaID:     "777",
         CorridaID:       999,
         PassageiroNome:  "Delete Test Passenger",
         Valor:           20.00,
@@ -363,7 +409,7 @@ func TestNotificacaoCorridaWorkflow(t *testing.T) {
     t.Log("=== TESTING COMPLETE NOTIFICACAO WORKFLOW ===")
 
     // 1. Create a notificacao
-    motoristaID := uint(123)
+    motoristaID := models.MotoristaID("123")
     newNotificacao := models.NotificacaoCorrida{
         MotoristaID:     motoristaID,
         CorridaID:       456,
@@ -422,6 +468,11 @@ func TestInvalidRequests(t *testing.T) {
         {"Refuse invalid motorista ID", "POST", "/notificacoes/1/motorista/xyz/refuse", 400},
         {"Pending invalid motorista ID", "GET", "/notificacoes/motorista/abc/pending", 400},
         {"Historico invalid motorista ID", "GET", "/notificacoes/motorista/xyz/historico", 400},
+        {"List invalid status filter", "GET", "/notificacoes?status=voando", 400},
+        {"List invalid cursor", "GET", "/notificacoes?cursor=xyz", 400},
+        {"List invalid date", "GET", "/notificacoes?de=ontem", 400},
+        {"Estatisticas invalid janela", "GET", "/notificacoes/motorista/101/estatisticas?janela=90d", 400},
+        {"Estatisticas invalid motorista ID", "GET", "/notificacoes/motorista/xyz/estatisticas", 400},
     }
 
     for _, tc := range testCases {
@@ -431,4 +482,77 @@ func TestInvalidRequests(t *testing.T) {
             t.Logf("Invalid request test '%s' passed with status %d", tc.name, resp.StatusCode)
         })
     }
-}
\ No newline at end of file
+}
+
+func TestListNotificacoesFiltradasPaginadas(t *testing.T) {
+    app := test.SetupTestApp(t)
+    defer test.CleanupTestApp(t)
+
+    resp := test.MakeRequest(t, app, "GET", "/notificacoes?status=aceita,recusada&limite=2", nil)
+    assert.Equal(t, 200, resp.StatusCode)
+    cursor := resp.Header.Get("X-Next-Cursor")
+
+    var pagina []models.NotificacaoCorrida
+    test.ParseResponseBody(t, resp, &pagina)
+    assert.Len(t, pagina, 2)
+    assert.NotEmpty(t, cursor, "há mais notificações aceitas ou recusadas")
+
+    resp = test.MakeRequest(t, app, "GET", "/notificacoes?status=aceita,recusada&limite=2&cursor="+cursor, nil)
+    assert.Equal(t, 200, resp.StatusCode)
+    var proxima []models.NotificacaoCorrida
+    test.ParseResponseBody(t, resp, &proxima)
+    for _, notif := range proxima {
+        assert.Greater(t, notif.ID, pagina[len(pagina)-1].ID)
+        assert.Contains(t, []models.NotificacaoStatus{models.NotificacaoAceita, models.NotificacaoRecusada}, notif.Status)
+    }
+
+    resp = test.MakeRequest(t, app, "GET", "/notificacoes?motorista_id=101&status=aceita&ate=2099-12-31", nil)
+    assert.Equal(t, 200, resp.StatusCode)
+    var doMotorista []models.NotificacaoCorrida
+    test.ParseResponseBody(t, resp, &doMotorista)
+    for _, notif := range doMotorista {
+        assert.Equal(t, models.MotoristaID("101"), notif.MotoristaID)
+        assert.Equal(t, models.NotificacaoAceita, notif.Status)
+    }
+}
+
+func TestRetencaoNotificacoesSimulada(t *testing.T) {
+    app := test.SetupTestApp(t)
+    defer test.CleanupTestApp(t)
+
+    antes := test.MakeRequest(t, app, "GET", "/notificacoes?limite=500", nil)
+    var todas []models.NotificacaoCorrida
+    test.ParseResponseBody(t, antes, &todas)
+
+    // Sem simular=false nada é removido
+    resp := test.MakeRequest(t, app, "POST", "/notificacoes/retencao", map[string]interface{}{"dias": 1})
+    assert.Equal(t, 200, resp.StatusCode)
+    var relatorio map[string]interface{}
+    test.ParseResponseBody(t, resp, &relatorio)
+    assert.Equal(t, true, relatorio["simulacao"])
+    assert.NotZero(t, relatorio["total"])
+
+    depois := test.MakeRequest(t, app, "GET", "/notificacoes?limite=500", nil)
+    var restantes []models.NotificacaoCorrida
+    test.ParseResponseBody(t, depois, &restantes)
+    assert.Len(t, restantes, len(todas))
+}
+
+func TestEstatisticasMotorista(t *testing.T) {
+    app := test.SetupTestApp(t)
+    defer test.CleanupTestApp(t)
+
+    // Período personalizado cobrindo os dados de exemplo
+    resp := test.MakeRequest(t, app, "GET", "/notificacoes/motorista/101/estatisticas?de=2020-01-01", nil)
+    assert.Equal(t, 200, resp.StatusCode)
+
+    var estatisticas map[string]interface{}
+    test.ParseResponseBody(t, resp, &estatisticas)
+    assert.Equal(t, "101", estatisticas["motorista_id"])
+    assert.NotZero(t, estatisticas["ofertas"])
+    taxas := estatisticas["taxa_aceite"].(float64) + estatisticas["taxa_recusa"].(float64) + estatisticas["taxa_expiracao"].(float64)
+    assert.InDelta(t, 1.0, taxas, 0.001)
+
+    resp = test.MakeRequest(t, app, "GET", "/notificacoes/motorista/101/estatisticas?janela=30d", nil)
+    assert.Equal(t, 200, resp.StatusCode)
+}